@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"github.com/imposter-project/imposter-cli/internal/engine"
+	"github.com/imposter-project/imposter-cli/internal/proxy"
 	"github.com/spf13/cobra"
 )
 
@@ -53,3 +54,36 @@ func registerEngineTypeCompletions(cmd *cobra.Command, additionalTypes ...engine
 		return types, cobra.ShellCompDirectiveNoFileComp
 	})
 }
+
+// redactionFlags holds the flags controlling redaction of secrets
+// from recorded exchanges, shared by the commands that record them.
+type redactionFlags struct {
+	headers     []string
+	jsonPaths   []string
+	patterns    []string
+	queryParams []string
+	noDefaults  bool
+}
+
+func (f *redactionFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&f.headers, "redact-header", nil, "Redact the value of this request or response header (repeatable)")
+	cmd.Flags().StringArrayVar(&f.jsonPaths, "redact-json-path", nil, "Redact the value at this JSON path in JSON bodies, e.g. $.user.password or $..token (repeatable)")
+	cmd.Flags().StringArrayVar(&f.patterns, "redact-pattern", nil, "Redact matches of this regular expression, or its first group, in text bodies and query parameters (repeatable)")
+	cmd.Flags().StringArrayVar(&f.queryParams, "redact-query-param", nil, "Redact the value of this query parameter (repeatable)")
+	cmd.Flags().BoolVar(&f.noDefaults, "no-default-redaction", false, "Do not redact the Authorization, Cookie and Set-Cookie headers by default")
+}
+
+// rules returns the redaction rules for the flags, including
+// the default rules unless they have been disabled.
+func (f *redactionFlags) rules() *proxy.RedactionRules {
+	var headers []string
+	if !f.noDefaults {
+		headers = append(headers, proxy.DefaultRedactedHeaders...)
+	}
+	headers = append(headers, f.headers...)
+	rules, err := proxy.NewRedactionRules(headers, f.jsonPaths, f.patterns, f.queryParams)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	return rules
+}
@@ -0,0 +1,105 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/imposter-project/imposter-cli/internal/har"
+	"github.com/imposter-project/imposter-cli/internal/proxy"
+	"github.com/spf13/cobra"
+)
+
+var importHarFlags = struct {
+	outputDir                 string
+	captureRequestBody        bool
+	captureRequestHeaders     bool
+	ignoreDuplicateRequests   bool
+	recordOnlyResponseHeaders []string
+	flatResponseFileStructure bool
+	templatePaths             bool
+	recordSequences           bool
+	redaction                 redactionFlags
+}{}
+
+// importHarCmd represents the import har command
+var importHarCmd = &cobra.Command{
+	Use:   "har FILE",
+	Short: "Import HTTP exchanges from a HAR file",
+	Long: `Imports the HTTP exchanges in a HAR (HTTP Archive) file, such as one
+exported from browser developer tools, in Imposter format.
+
+Each exchange is recorded as though it had been proxied with 'imposter proxy',
+producing one configuration file per upstream host, along with response files.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var outputDir string
+		if importHarFlags.outputDir != "" {
+			outputDir = importHarFlags.outputDir
+		} else {
+			workingDir, err := os.Getwd()
+			if err != nil {
+				panic(err)
+			}
+			outputDir = workingDir
+		}
+		options := proxy.RecorderOptions{
+			CaptureRequestBody:        importHarFlags.captureRequestBody,
+			CaptureRequestHeaders:     importHarFlags.captureRequestHeaders,
+			IgnoreDuplicateRequests:   importHarFlags.ignoreDuplicateRequests,
+			RecordOnlyResponseHeaders: importHarFlags.recordOnlyResponseHeaders,
+			FlatResponseFileStructure: importHarFlags.flatResponseFileStructure,
+			TemplatePaths:             importHarFlags.templatePaths,
+			RecordSequences:           importHarFlags.recordSequences,
+			Redaction:                 importHarFlags.redaction.rules(),
+		}
+		importHar(args[0], outputDir, options)
+	},
+}
+
+func init() {
+	importHarCmd.Flags().StringVarP(&importHarFlags.outputDir, "output-dir", "o", "", "Directory in which HTTP exchanges are recorded (default: current working directory)")
+	importHarCmd.Flags().BoolVar(&importHarFlags.captureRequestBody, "capture-request-body", false, "Capture the request body")
+	importHarCmd.Flags().BoolVar(&importHarFlags.captureRequestHeaders, "capture-request-headers", false, "Capture the request headers")
+	importHarCmd.Flags().BoolVarP(&importHarFlags.ignoreDuplicateRequests, "ignore-duplicate-requests", "i", true, "Ignore duplicate requests with same method and URI")
+	importHarCmd.Flags().StringSliceVarP(&importHarFlags.recordOnlyResponseHeaders, "response-headers", "H", nil, "Record only these response headers")
+	importHarCmd.Flags().BoolVar(&importHarFlags.flatResponseFileStructure, "flat", false, "Flatten the response file structure")
+	importHarCmd.Flags().BoolVar(&importHarFlags.templatePaths, "template-paths", false, "Collapse identifier-like path segments (numbers, UUIDs, hashes) into path parameters")
+	importHarCmd.Flags().BoolVar(&importHarFlags.recordSequences, "record-sequences", false, "Record different responses to repeated requests as a sequence, replayed in order")
+	importHarFlags.redaction.register(importHarCmd)
+	importCmd.AddCommand(importHarCmd)
+}
+
+func importHar(harFile string, dir string, options proxy.RecorderOptions) {
+	archive, err := har.Parse(harFile)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	groups, err := proxy.ExchangesFromHar(archive)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if len(groups) == 0 {
+		logger.Fatalf("no HTTP exchanges found in HAR file: %s", harFile)
+	}
+	for _, group := range groups {
+		if err := proxy.RecordExchanges(group.Upstream, dir, options, group.Exchanges); err != nil {
+			logger.Fatal(err)
+		}
+		logger.Infof("imported %d exchange(s) for upstream %s", len(group.Exchanges), group.Upstream)
+	}
+}
@@ -0,0 +1,56 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/imposter-project/imposter-cli/internal/impostermodel"
+	"github.com/imposter-project/imposter-cli/internal/proxy"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func Test_importHar(t *testing.T) {
+	workingDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputDir := t.TempDir()
+
+	importHar(filepath.Join(workingDir, "testdata_har", "example.har"), outputDir, proxy.RecorderOptions{
+		IgnoreDuplicateRequests: true,
+	})
+
+	apiConfig, err := os.ReadFile(filepath.Join(outputDir, "api.example.com-config.yaml"))
+	require.NoError(t, err, "config file should exist for api upstream")
+	var parsed impostermodel.PluginConfig
+	require.NoError(t, yaml.Unmarshal(apiConfig, &parsed))
+	require.Equal(t, "rest", parsed.Plugin)
+	require.Len(t, parsed.Resources, 1, "entry without a response should be skipped")
+	require.Equal(t, "/users", parsed.Resources[0].Path)
+	require.Equal(t, "GET-users.json", parsed.Resources[0].Response.File)
+
+	body, err := os.ReadFile(filepath.Join(outputDir, "GET-users.json"))
+	require.NoError(t, err)
+	require.Equal(t, `[{"id":1,"name":"Alice"}]`, string(body))
+
+	require.FileExists(t, filepath.Join(outputDir, "cdn.example.com-config.yaml"))
+	require.FileExists(t, filepath.Join(outputDir, "GET-logo.png"))
+}
@@ -0,0 +1,100 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/imposter-project/imposter-cli/internal/fileutil"
+	"github.com/imposter-project/imposter-cli/internal/impostermodel"
+	"github.com/imposter-project/imposter-cli/internal/pact"
+	"github.com/spf13/cobra"
+)
+
+var importPactFlags = struct {
+	outputDir      string
+	forceOverwrite bool
+}{}
+
+// importPactCmd represents the import pact command
+var importPactCmd = &cobra.Command{
+	Use:   "pact FILE...",
+	Short: "Import interactions from Pact files",
+	Long: `Imports the interactions in one or more Pact v2 or v3 contract files
+in Imposter format.
+
+Each interaction is translated to a resource matching the method, path,
+query parameters, headers and text body of its request, and returning its
+response. JSON request bodies are not matched, as clients rarely format
+them exactly as in the pact, and a warning is reported. A REST plugin configuration file is written for each provider,
+named after the provider, combining the interactions from all files.
+
+Interactions that require a provider state are selected by the
+'X-Provider-State' request header, holding the name of the state, so a
+test can opt into a state. Matching rules, such as type or regular
+expression matchers, are not translated - the parts of the request they
+apply to are not matched, and a warning is reported.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var outputDir string
+		if importPactFlags.outputDir != "" {
+			outputDir = importPactFlags.outputDir
+		} else {
+			workingDir, err := os.Getwd()
+			if err != nil {
+				panic(err)
+			}
+			outputDir = workingDir
+		}
+		importPact(args, outputDir, importPactFlags.forceOverwrite)
+	},
+}
+
+func init() {
+	importPactCmd.Flags().StringVarP(&importPactFlags.outputDir, "output-dir", "o", "", "Directory in which the configuration is written (default: current working directory)")
+	importPactCmd.Flags().BoolVarP(&importPactFlags.forceOverwrite, "force-overwrite", "f", false, "Force overwrite of destination file(s) if already exist")
+	importCmd.AddCommand(importPactCmd)
+}
+
+func importPact(pactFiles []string, outputDir string, forceOverwrite bool) {
+	var pacts []*pact.Pact
+	for _, pactFile := range pactFiles {
+		parsed, err := pact.Parse(pactFile)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		pacts = append(pacts, parsed)
+	}
+	configs, issues, err := pact.Convert(pacts, outputDir, forceOverwrite)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	for _, issue := range issues {
+		logger.Warn(issue)
+	}
+
+	for _, providerConfig := range configs {
+		configFile := filepath.Join(outputDir, providerConfig.BaseName+"-config.yaml")
+		fileutil.MustNotExist(configFile, forceOverwrite)
+		config := impostermodel.GenerateConfig(impostermodel.ConfigGenerationOptions{PluginName: "rest"}, providerConfig.Resources)
+		if err := os.WriteFile(configFile, config, 0644); err != nil {
+			logger.Fatalf("failed to write config file: %s: %v", configFile, err)
+		}
+		logger.Infof("imported %d interaction(s) for provider %s to %s", len(providerConfig.Resources), providerConfig.Provider, configFile)
+	}
+}
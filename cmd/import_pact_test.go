@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/imposter-project/imposter-cli/internal/impostermodel"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func Test_importPact(t *testing.T) {
+	workingDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputDir := t.TempDir()
+
+	importPact([]string{
+		filepath.Join(workingDir, "testdata_pact", "web-pet_service.json"),
+		filepath.Join(workingDir, "testdata_pact", "mobile-pet_service.json"),
+		filepath.Join(workingDir, "testdata_pact", "web-order_service.json"),
+	}, outputDir, false)
+
+	config, err := os.ReadFile(filepath.Join(outputDir, "pet_service-config.yaml"))
+	require.NoError(t, err, "config file should be written for each provider")
+	var parsed impostermodel.PluginConfig
+	require.NoError(t, yaml.Unmarshal(config, &parsed))
+	require.Equal(t, "rest", parsed.Plugin)
+	require.Len(t, parsed.Resources, 2, "interactions with the same provider should be combined")
+
+	getPet := parsed.Resources[0]
+	require.Equal(t, "GET", getPet.Method)
+	require.Equal(t, "/pets/1", getPet.Path)
+	require.Equal(t, map[string]string{"fields": "name"}, *getPet.QueryParams)
+	require.Equal(t, map[string]string{"Accept": "application/json", "X-Provider-State": "pet 1 exists"}, *getPet.RequestHeaders)
+	require.Nil(t, getPet.RequestBody)
+	require.Equal(t, 200, getPet.Response.StatusCode)
+	require.Equal(t, "pet_service-responses/a-request-for-a-pet.json", getPet.Response.File)
+	body, err := os.ReadFile(filepath.Join(outputDir, getPet.Response.File))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"id":1,"name":"Fluffy"}`, string(body))
+
+	createPet := parsed.Resources[1]
+	require.Equal(t, "POST", createPet.Method)
+	require.Equal(t, map[string]string{"dryRun": "true"}, *createPet.QueryParams)
+	require.Equal(t, map[string]string{
+		"Content-Type":     "application/json",
+		"X-Provider-State": "the store is open, user is admin",
+	}, *createPet.RequestHeaders, "headers with matching rules should not be matched")
+	require.Nil(t, createPet.RequestBody, "JSON request bodies should not be matched")
+	require.Equal(t, 201, createPet.Response.StatusCode)
+	body, err = os.ReadFile(filepath.Join(outputDir, createPet.Response.File))
+	require.NoError(t, err)
+	require.Equal(t, "created", string(body))
+
+	config, err = os.ReadFile(filepath.Join(outputDir, "order_service-config.yaml"))
+	require.NoError(t, err)
+	var orderConfig impostermodel.PluginConfig
+	require.NoError(t, yaml.Unmarshal(config, &orderConfig))
+	require.Len(t, orderConfig.Resources, 1)
+	require.Equal(t, "GET", orderConfig.Resources[0].Method)
+	require.Nil(t, orderConfig.Resources[0].RequestHeaders, "interactions without a provider state should not require the header")
+}
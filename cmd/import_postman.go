@@ -0,0 +1,96 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/imposter-project/imposter-cli/internal/fileutil"
+	"github.com/imposter-project/imposter-cli/internal/impostermodel"
+	"github.com/imposter-project/imposter-cli/internal/postman"
+	"github.com/spf13/cobra"
+)
+
+var importPostmanFlags = struct {
+	outputDir      string
+	forceOverwrite bool
+}{}
+
+// importPostmanCmd represents the import postman command
+var importPostmanCmd = &cobra.Command{
+	Use:   "postman FILE",
+	Short: "Import saved examples from a Postman collection",
+	Long: `Imports the examples saved for the requests in a Postman collection,
+exported in v2.1 format, in Imposter format.
+
+Each example is translated to a resource matching its method, path and
+query parameters, returning its status, headers and body, in a REST plugin
+configuration file named after the collection. Folders are walked in order,
+and collection and folder variables, such as {{baseUrl}}, are resolved.
+
+If several examples are saved for a request, such as for a success and an
+error, the first is returned by default, and the others are selected by
+the 'X-Mock-Status' request header, such as 'X-Mock-Status: 404'.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var outputDir string
+		if importPostmanFlags.outputDir != "" {
+			outputDir = importPostmanFlags.outputDir
+		} else {
+			workingDir, err := os.Getwd()
+			if err != nil {
+				panic(err)
+			}
+			outputDir = workingDir
+		}
+		importPostman(args[0], outputDir, importPostmanFlags.forceOverwrite)
+	},
+}
+
+func init() {
+	importPostmanCmd.Flags().StringVarP(&importPostmanFlags.outputDir, "output-dir", "o", "", "Directory in which the configuration is written (default: current working directory)")
+	importPostmanCmd.Flags().BoolVarP(&importPostmanFlags.forceOverwrite, "force-overwrite", "f", false, "Force overwrite of destination file(s) if already exist")
+	importCmd.AddCommand(importPostmanCmd)
+}
+
+func importPostman(collectionFile string, outputDir string, forceOverwrite bool) {
+	collection, err := postman.Parse(collectionFile)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	baseName := fileutil.SanitiseFileName(collection.Info.Name, "postman")
+
+	resources, issues, err := postman.Convert(collection, outputDir, baseName+"-responses", forceOverwrite)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	for _, issue := range issues {
+		logger.Warn(issue)
+	}
+	if len(resources) == 0 {
+		logger.Fatalf("no saved examples found in Postman collection: %s", collectionFile)
+	}
+
+	configFile := filepath.Join(outputDir, baseName+"-config.yaml")
+	fileutil.MustNotExist(configFile, forceOverwrite)
+	config := impostermodel.GenerateConfig(impostermodel.ConfigGenerationOptions{PluginName: "rest"}, resources)
+	if err := os.WriteFile(configFile, config, 0644); err != nil {
+		logger.Fatalf("failed to write config file: %s: %v", configFile, err)
+	}
+	logger.Infof("imported %d example(s) from Postman collection to %s, with %d warning(s)", len(resources), configFile, len(issues))
+}
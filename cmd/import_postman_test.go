@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/imposter-project/imposter-cli/internal/impostermodel"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func Test_importPostman(t *testing.T) {
+	workingDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputDir := t.TempDir()
+
+	importPostman(filepath.Join(workingDir, "testdata_postman", "pet_store.postman_collection.json"), outputDir, false)
+
+	config, err := os.ReadFile(filepath.Join(outputDir, "Pet-Store-config.yaml"))
+	require.NoError(t, err, "config file should be named after the collection")
+	var parsed impostermodel.PluginConfig
+	require.NoError(t, yaml.Unmarshal(config, &parsed))
+	require.Equal(t, "rest", parsed.Plugin)
+	require.Len(t, parsed.Resources, 3, "requests without examples should be skipped")
+
+	found := parsed.Resources[0]
+	require.Equal(t, "GET", found.Method)
+	require.Equal(t, "/v1/pets/{petId}", found.Path)
+	require.Equal(t, map[string]string{"petId": "42"}, *found.PathParams, "folder variables should be resolved")
+	require.Equal(t, map[string]string{"version": "2"}, *found.QueryParams, "collection variables should be resolved")
+	require.Nil(t, found.RequestHeaders)
+	require.Equal(t, 200, found.Response.StatusCode)
+	require.Equal(t, map[string]string{"Content-Type": "application/json"}, *found.Response.Headers)
+	require.Equal(t, "Pet-Store-responses/Get-pet-Found.json", found.Response.File)
+	body, err := os.ReadFile(filepath.Join(outputDir, found.Response.File))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"id":42,"name":"Fluffy"}`, string(body))
+
+	notFound := parsed.Resources[1]
+	require.Equal(t, found.Path, notFound.Path)
+	require.Equal(t, map[string]string{"X-Mock-Status": "404"}, *notFound.RequestHeaders, "other examples should be selected by status")
+	require.Equal(t, "Pet-Store-responses/Get-pet-Not-found.txt", notFound.Response.File)
+
+	created := parsed.Resources[2]
+	require.Equal(t, "POST", created.Method)
+	require.Equal(t, "/v1/pets", created.Path)
+	require.Equal(t, 201, created.Response.StatusCode)
+	require.Equal(t, map[string]string{"Location": "/pets/43"}, *created.Response.Headers)
+	require.Empty(t, created.Response.File)
+}
@@ -0,0 +1,95 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/imposter-project/imposter-cli/internal/fileutil"
+	"github.com/imposter-project/imposter-cli/internal/impostermodel"
+	"github.com/imposter-project/imposter-cli/internal/wiremock"
+	"github.com/spf13/cobra"
+)
+
+var importWiremockFlags = struct {
+	outputDir      string
+	forceOverwrite bool
+}{}
+
+// importWiremockCmd represents the import wiremock command
+var importWiremockCmd = &cobra.Command{
+	Use:   "wiremock DIR",
+	Short: "Import stub mappings from a WireMock root directory",
+	Long: `Imports the stub mappings in a WireMock root directory, which contains
+'mappings' and '__files' directories, in Imposter format.
+
+Request matchers on the URL, method, headers, query and path parameters
+and body, and responses with a status, headers and a body or body file, are
+translated to resources in a REST plugin configuration file named
+'wiremock-config.yaml'. Body files are copied to the '__files' directory
+of the output directory.
+
+Constructs that cannot be translated exactly, such as regular expression
+matchers, scenarios, delays and response templates, are reported as
+warnings. Mappings that proxy requests or return faults are skipped.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var outputDir string
+		if importWiremockFlags.outputDir != "" {
+			outputDir = importWiremockFlags.outputDir
+		} else {
+			workingDir, err := os.Getwd()
+			if err != nil {
+				panic(err)
+			}
+			outputDir = workingDir
+		}
+		importWiremock(args[0], outputDir, importWiremockFlags.forceOverwrite)
+	},
+}
+
+func init() {
+	importWiremockCmd.Flags().StringVarP(&importWiremockFlags.outputDir, "output-dir", "o", "", "Directory in which the configuration is written (default: current working directory)")
+	importWiremockCmd.Flags().BoolVarP(&importWiremockFlags.forceOverwrite, "force-overwrite", "f", false, "Force overwrite of destination file(s) if already exist")
+	importCmd.AddCommand(importWiremockCmd)
+}
+
+func importWiremock(rootDir string, outputDir string, forceOverwrite bool) {
+	mappings, err := wiremock.Load(rootDir)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if len(mappings) == 0 {
+		logger.Fatalf("no WireMock mappings found in: %s", filepath.Join(rootDir, "mappings"))
+	}
+	resources, issues, err := wiremock.Convert(mappings, rootDir, outputDir, forceOverwrite)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	for _, issue := range issues {
+		logger.Warn(issue)
+	}
+
+	configFile := filepath.Join(outputDir, "wiremock-config.yaml")
+	fileutil.MustNotExist(configFile, forceOverwrite)
+	config := impostermodel.GenerateConfig(impostermodel.ConfigGenerationOptions{PluginName: "rest"}, resources)
+	if err := os.WriteFile(configFile, config, 0644); err != nil {
+		logger.Fatalf("failed to write config file: %s: %v", configFile, err)
+	}
+	logger.Infof("imported %d of %d WireMock mapping(s) to %s, with %d warning(s)", len(resources), len(mappings), configFile, len(issues))
+}
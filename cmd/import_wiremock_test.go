@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/imposter-project/imposter-cli/internal/impostermodel"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func Test_importWiremock(t *testing.T) {
+	workingDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputDir := t.TempDir()
+
+	importWiremock(filepath.Join(workingDir, "testdata_wiremock"), outputDir, false)
+
+	config, err := os.ReadFile(filepath.Join(outputDir, "wiremock-config.yaml"))
+	require.NoError(t, err, "config file should exist")
+	var parsed impostermodel.PluginConfig
+	require.NoError(t, yaml.Unmarshal(config, &parsed))
+	require.Equal(t, "rest", parsed.Plugin)
+	require.Len(t, parsed.Resources, 3, "proxy mapping should be skipped")
+
+	createOrder := parsed.Resources[0]
+	require.Equal(t, "POST", createOrder.Method, "mappings should be ordered by priority")
+	require.Equal(t, "/orders", createOrder.Path)
+	require.Equal(t, map[string]string{"dryRun": "true"}, *createOrder.QueryParams)
+	require.Equal(t, &impostermodel.RequestBody{Operator: "EqualTo", Value: `{"item":"book"}`}, createOrder.RequestBody)
+	require.Equal(t, 201, createOrder.Response.StatusCode)
+	require.Equal(t, `{"id":"abc"}`, createOrder.Response.Content)
+
+	getOrder := parsed.Resources[1]
+	require.Equal(t, "/orders/{param1}", getOrder.Path)
+	require.Nil(t, getOrder.QueryParams, "regex matchers should not be translated")
+	require.Equal(t, "order", getOrder.Response.Content)
+
+	getUser := parsed.Resources[2]
+	require.Equal(t, "/users/1", getUser.Path)
+	require.Equal(t, map[string]string{"Accept": "application/json"}, *getUser.RequestHeaders)
+	require.Equal(t, "__files/user.json", getUser.Response.File)
+	require.Equal(t, map[string]string{"Content-Type": "application/json"}, *getUser.Response.Headers)
+	require.FileExists(t, filepath.Join(outputDir, "__files", "user.json"))
+}
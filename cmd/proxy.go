@@ -18,10 +18,13 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/imposter-project/imposter-cli/internal/config"
 	proxy2 "github.com/imposter-project/imposter-cli/internal/proxy"
 	"github.com/spf13/cobra"
 	"net/http"
 	"os"
+	"path/filepath"
+	"sync"
 )
 
 var proxyFlags = struct {
@@ -33,17 +36,130 @@ var proxyFlags = struct {
 	ignoreDuplicateRequests   bool
 	recordOnlyResponseHeaders []string
 	flatResponseFileStructure bool
+	templatePaths             bool
+	recordSequences           bool
 	insecure                  bool
+	harFile                   string
+	tlsPort                   int
+	forwardProxy              bool
+	routes                    []string
+	maxBodySize               int64
+	skipTruncatedBodies       bool
+	protoFiles                []string
+	chaosFile                 string
+	playback                  bool
+	specFile                  string
+	generateSpec              bool
+	redaction                 redactionFlags
 }{}
 
+type proxySettings struct {
+	upstream string
+	port     int
+	dir      string
+	rewrite  bool
+	insecure bool
+	harFile  string
+	options  proxy2.RecorderOptions
+
+	// tlsPort is the port for an additional HTTPS listener, if non-zero.
+	tlsPort int
+
+	// forwardProxy permits clients to use the proxy as an HTTP(S) forward
+	// proxy, with TLS terminated using the proxy CA for CONNECT tunnels.
+	forwardProxy bool
+
+	// routes send matching requests to upstreams other than the default.
+	routes []proxy2.Route
+
+	// maxBodySize is the maximum size of each body retained for recording.
+	maxBodySize int64
+
+	// protoFiles are used to decode gRPC calls, which are only
+	// recorded if at least one is provided.
+	protoFiles []string
+
+	// chaosFile holds rules for injecting faults and latency, if set.
+	chaosFile string
+
+	// playback serves requests matching already recorded resources from
+	// the recording, only forwarding unmatched requests to the upstream.
+	playback bool
+
+	// specFile is an OpenAPI spec to which responses from the upstream
+	// are recorded as examples, if set.
+	specFile string
+
+	// generateSpec infers an OpenAPI spec for each upstream
+	// from the exchanges recorded with it.
+	generateSpec bool
+}
+
 // proxyCmd represents the up command
 var proxyCmd = &cobra.Command{
 	Use:   "proxy [URL]",
 	Short: "Proxy an endpoint and record HTTP exchanges",
-	Long:  `Proxies an endpoint and records HTTP exchanges to file, in Imposter format.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Proxies an endpoint and records HTTP exchanges to file, in Imposter format.
+
+To record several upstreams at once, add a --route for each, in the form
+MATCH=URL, where MATCH is a path prefix, a Host header value, or both. Each
+upstream is recorded to its own configuration file. Requests not matching
+a route are sent to URL, if provided. For example:
+
+	imposter proxy --route /users=https://users.example.com \
+	  --route orders.local=https://orders.example.com
+
+If --forward-proxy is set, clients can instead use the proxy as a forward
+proxy, for example by setting the HTTP_PROXY or HTTPS_PROXY environment
+variables, in which case URL is optional. HTTPS traffic is decrypted using
+a certificate authority generated under the CLI config directory, which
+clients must trust.
+
+WebSocket connections are passed through, and the messages of each are
+recorded to a session file, which is referenced by a resource in the
+generated configuration. Sessions are replayed with --playback.
+
+With --playback, requests matching exchanges already recorded in the output
+directory are served from the recording, and only unmatched requests are
+forwarded to the upstream and appended to the configuration, so recordings
+can be extended across runs. It cannot be combined with --record-sequences.
+
+gRPC calls are proxied over HTTP/2, with or without TLS. To record them,
+pass the service definitions with --proto; each call is recorded as a
+resource in a grpc plugin configuration file, with a JSON response file.
+
+With --spec, exchanges with URL are instead recorded against an OpenAPI 3
+spec. Each response body is added to a copy of the spec as a named example
+of the operation it matches, and an openapi plugin configuration is written
+with a resource selecting each example. Exchanges that do not match an
+operation and documented response status are not recorded.
+
+With --generate-spec, an OpenAPI 3 spec is also inferred for each upstream
+from the exchanges recorded with it, and written alongside the recording.
+Identifier-like path segments, such as numeric IDs or UUIDs, are described
+as path parameters, and query and header parameters are described from the
+values seen. The schema of each request and response body is inferred by
+merging all the bodies recorded for the operation, and the distinct bodies
+are included as examples.`,
+	Args: cobra.RangeArgs(0, 1),
 	Run: func(cmd *cobra.Command, args []string) {
-		upstream := args[0]
+		var upstream string
+		if len(args) > 0 {
+			upstream = args[0]
+		} else if !proxyFlags.forwardProxy && len(proxyFlags.routes) == 0 {
+			logger.Fatal("upstream URL is required unless --route or --forward-proxy is set")
+		}
+		if proxyFlags.specFile != "" && upstream == "" {
+			logger.Fatal("upstream URL is required when --spec is set")
+		}
+		var routes []proxy2.Route
+		for _, spec := range proxyFlags.routes {
+			route, err := proxy2.ParseRoute(spec)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			routes = append(routes, route)
+		}
 		var outputDir string
 		if proxyFlags.outputDir != "" {
 			outputDir = proxyFlags.outputDir
@@ -60,8 +176,31 @@ var proxyCmd = &cobra.Command{
 			IgnoreDuplicateRequests:   proxyFlags.ignoreDuplicateRequests,
 			RecordOnlyResponseHeaders: proxyFlags.recordOnlyResponseHeaders,
 			FlatResponseFileStructure: proxyFlags.flatResponseFileStructure,
+			TemplatePaths:             proxyFlags.templatePaths,
+			RecordSequences:           proxyFlags.recordSequences,
+			SkipTruncatedBodies:       proxyFlags.skipTruncatedBodies,
+			Redaction:                 proxyFlags.redaction.rules(),
+			AppendToExisting:          proxyFlags.playback,
 		}
-		proxyUpstream(upstream, proxyFlags.port, outputDir, proxyFlags.rewrite, proxyFlags.insecure, options)
+		proxyUpstream(proxySettings{
+			upstream: upstream,
+			port:     proxyFlags.port,
+			dir:      outputDir,
+			rewrite:  proxyFlags.rewrite,
+			insecure: proxyFlags.insecure,
+			harFile:  proxyFlags.harFile,
+			options:  options,
+
+			tlsPort:      proxyFlags.tlsPort,
+			forwardProxy: proxyFlags.forwardProxy,
+			routes:       routes,
+			maxBodySize:  proxyFlags.maxBodySize,
+			protoFiles:   proxyFlags.protoFiles,
+			chaosFile:    proxyFlags.chaosFile,
+			playback:     proxyFlags.playback,
+			specFile:     proxyFlags.specFile,
+			generateSpec: proxyFlags.generateSpec,
+		})
 	},
 }
 
@@ -74,39 +213,267 @@ func init() {
 	proxyCmd.Flags().BoolVarP(&proxyFlags.ignoreDuplicateRequests, "ignore-duplicate-requests", "i", true, "Ignore duplicate requests with same method and URI")
 	proxyCmd.Flags().StringSliceVarP(&proxyFlags.recordOnlyResponseHeaders, "response-headers", "H", nil, "Record only these response headers")
 	proxyCmd.Flags().BoolVar(&proxyFlags.flatResponseFileStructure, "flat", false, "Flatten the response file structure")
+	proxyCmd.Flags().BoolVar(&proxyFlags.templatePaths, "template-paths", false, "Collapse identifier-like path segments (numbers, UUIDs, hashes) into path parameters")
+	proxyCmd.Flags().BoolVar(&proxyFlags.recordSequences, "record-sequences", false, "Record different responses to repeated requests as a sequence, replayed in order")
 	proxyCmd.Flags().BoolVar(&proxyFlags.insecure, "insecure", false, "Skip TLS certificate verification when forwarding to the upstream")
+	proxyCmd.Flags().StringVar(&proxyFlags.harFile, "har", "", "Also write HTTP exchanges to this HAR file")
+	proxyCmd.Flags().IntVar(&proxyFlags.tlsPort, "tls-port", 0, "Port on which to listen for HTTPS, using a certificate issued by the proxy CA (default: disabled)")
+	proxyCmd.Flags().BoolVar(&proxyFlags.forwardProxy, "forward-proxy", false, "Accept forward proxy requests, including CONNECT tunnels for HTTPS, and record each upstream host")
+	proxyCmd.Flags().StringArrayVar(&proxyFlags.routes, "route", nil, "Route matching requests to another upstream, as MATCH=URL, where MATCH is a path prefix (/api), host (api.local) or both (api.local/v1)")
+	proxyCmd.Flags().Int64Var(&proxyFlags.maxBodySize, "max-body-size", 0, "Maximum size in bytes of each request or response body to record; bodies are always proxied in full (default: no limit)")
+	proxyCmd.Flags().BoolVar(&proxyFlags.skipTruncatedBodies, "skip-truncated-bodies", false, "Skip recording response bodies larger than --max-body-size, instead of truncating them")
+	proxyCmd.Flags().StringArrayVar(&proxyFlags.protoFiles, "proto", nil, "Proto file used to decode and record gRPC calls (repeatable)")
+	proxyCmd.Flags().BoolVar(&proxyFlags.playback, "playback", false, "Serve requests matching exchanges already recorded in the output directory, forwarding and recording only unmatched requests")
+	proxyCmd.Flags().StringVar(&proxyFlags.specFile, "spec", "", "OpenAPI 3 spec to which responses from URL are recorded as examples, with an openapi plugin configuration")
+	proxyCmd.MarkFlagsMutuallyExclusive("spec", "playback")
+	proxyCmd.MarkFlagsMutuallyExclusive("spec", "template-paths")
+	proxyCmd.MarkFlagsMutuallyExclusive("spec", "record-sequences")
+	proxyCmd.MarkFlagsMutuallyExclusive("playback", "record-sequences")
+	proxyCmd.Flags().BoolVar(&proxyFlags.generateSpec, "generate-spec", false, "Also infer an OpenAPI 3 spec for each upstream from the recorded exchanges")
+	proxyCmd.Flags().StringVar(&proxyFlags.chaosFile, "chaos", "", "YAML file of rules for injecting latency and faults into matching requests; reloaded when changed")
+	proxyFlags.redaction.register(proxyCmd)
 	rootCmd.AddCommand(proxyCmd)
 }
 
-func proxyUpstream(upstream string, port int, dir string, rewrite bool, insecure bool, options proxy2.RecorderOptions) {
-	logger.Infof("starting proxy for upstream %s on port %v", upstream, port)
-	recorderC, err := proxy2.StartRecorder(upstream, dir, options)
-	if err != nil {
-		logger.Fatal(err)
+// proxyServer holds the state shared by the listeners of a running proxy.
+type proxyServer struct {
+	settings  proxySettings
+	ca        *proxy2.CertificateAuthority
+	chaos     *proxy2.Chaos
+	harC      chan proxy2.HttpExchange
+	mutex     sync.Mutex
+	recorders map[string]chan proxy2.HttpExchange
+	playbacks map[string]*proxy2.Playback
+
+	// specGenerators infer a spec for each upstream, if enabled.
+	specGenerators map[string]chan proxy2.HttpExchange
+}
+
+func proxyUpstream(settings proxySettings) {
+	server := &proxyServer{
+		settings:       settings,
+		recorders:      make(map[string]chan proxy2.HttpExchange),
+		playbacks:      make(map[string]*proxy2.Playback),
+		specGenerators: make(map[string]chan proxy2.HttpExchange),
+	}
+	if settings.upstream != "" {
+		logger.Infof("starting proxy for upstream %s on port %v", settings.upstream, settings.port)
+		if _, err := server.getRecorder(settings.upstream, false); err != nil {
+			logger.Fatal(err)
+		}
+		if settings.generateSpec {
+			if _, err := server.getSpecGenerator(settings.upstream); err != nil {
+				logger.Fatal(err)
+			}
+		}
+	}
+	for _, route := range settings.routes {
+		logger.Infof("routing requests matching host=%q path=%q to upstream %s", route.Host, route.PathPrefix, route.Upstream)
+		if _, err := server.getRecorder(route.Upstream, false); err != nil {
+			logger.Fatal(err)
+		}
+		if settings.generateSpec {
+			if _, err := server.getSpecGenerator(route.Upstream); err != nil {
+				logger.Fatal(err)
+			}
+		}
+	}
+	if settings.upstream == "" && len(settings.routes) == 0 {
+		logger.Infof("starting forward proxy on port %v", settings.port)
 	}
 
-	mux := http.NewServeMux()
-	mux.HandleFunc("/system/status", func(writer http.ResponseWriter, request *http.Request) {
-		_, _ = fmt.Fprintf(writer, "ok\n")
-	})
-	mux.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {
-		proxy2.Handle(upstream, writer, request, insecure, func(reqBody *[]byte, statusCode int, respBody *[]byte, respHeaders *http.Header) (*[]byte, *http.Header) {
-			if rewrite {
-				respBody = proxy2.Rewrite(respHeaders, respBody, upstream, port)
+	var err error
+	if settings.harFile != "" {
+		server.harC, err = proxy2.StartHarRecorder(settings.harFile, settings.options.Redaction)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		logger.Infof("writing HAR archive to %s", settings.harFile)
+	}
+	if settings.chaosFile != "" {
+		server.chaos, err = proxy2.LoadChaos(settings.chaosFile)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		server.chaos.WatchForChanges()
+	}
+	if settings.tlsPort > 0 || settings.forwardProxy {
+		server.ca, err = loadProxyCA()
+		if err != nil {
+			logger.Fatal(err)
+		}
+	}
+
+	if settings.tlsPort > 0 {
+		go func() {
+			logger.Infof("listening for HTTPS on port %v", settings.tlsPort)
+			tlsServer := &http.Server{
+				Addr:      fmt.Sprintf(":%d", settings.tlsPort),
+				Handler:   server,
+				TLSConfig: server.ca.TLSConfig("localhost"),
 			}
-			recorderC <- proxy2.HttpExchange{
-				Request:         request,
-				RequestBody:     reqBody,
-				StatusCode:      statusCode,
-				ResponseBody:    respBody,
-				ResponseHeaders: respHeaders,
+			if err := tlsServer.ListenAndServeTLS("", ""); err != nil {
+				logger.Fatal(err)
 			}
-			return respBody, respHeaders
-		})
-	})
+		}()
+	}
 
-	err = http.ListenAndServe(fmt.Sprintf(":%d", port), mux)
+	// accept HTTP/2 without TLS (h2c), as used by plaintext gRPC clients
+	plainServer := &http.Server{
+		Addr:      fmt.Sprintf(":%d", settings.port),
+		Handler:   server,
+		Protocols: new(http.Protocols),
+	}
+	plainServer.Protocols.SetHTTP1(true)
+	plainServer.Protocols.SetUnencryptedHTTP2(true)
+	err = plainServer.ListenAndServe()
 	if err != nil {
 		logger.Fatal(err)
 	}
 }
+
+// loadProxyCA loads the proxy CA from the CLI config directory,
+// generating it on first use.
+func loadProxyCA() (*proxy2.CertificateAuthority, error) {
+	globalConfigDir, err := config.GetGlobalConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	ca, err := proxy2.LoadOrCreateCA(filepath.Join(globalConfigDir, "proxy-ca"))
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("clients must trust the proxy CA certificate: %s", ca.CertFile)
+	return ca, nil
+}
+
+func (s *proxyServer) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if s.settings.forwardProxy && proxy2.IsForwardProxyRequest(request) {
+		if request.Method == http.MethodConnect {
+			proxy2.ServeConnect(writer, request, s.ca, s.handle)
+		} else {
+			s.handle(proxy2.ForwardProxyUpstream(request), writer, request)
+		}
+		return
+	}
+	if request.URL.Path == "/system/status" {
+		_, _ = fmt.Fprintf(writer, "ok\n")
+		return
+	}
+	if upstream, ok := proxy2.MatchRoute(s.settings.routes, request); ok {
+		s.handle(upstream, writer, request)
+		return
+	}
+	if s.settings.upstream == "" {
+		http.Error(writer, "no upstream matches request", http.StatusBadGateway)
+		return
+	}
+	s.handle(s.settings.upstream, writer, request)
+}
+
+// getRecorder returns the recorder for the upstream, starting it
+// on first use, so each upstream is recorded to its own config file.
+// gRPC calls are recorded separately, as they use the grpc plugin, and
+// if a spec is set, exchanges with the main upstream are recorded to it.
+func (s *proxyServer) getRecorder(upstream string, grpc bool) (chan proxy2.HttpExchange, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	key := upstream
+	if grpc {
+		key = "grpc:" + upstream
+	}
+	if recorderC, ok := s.recorders[key]; ok {
+		return recorderC, nil
+	}
+	var recorderC chan proxy2.HttpExchange
+	var err error
+	if grpc {
+		if len(s.settings.protoFiles) == 0 {
+			return nil, fmt.Errorf("no proto files were provided with --proto")
+		}
+		recorderC, err = proxy2.StartGrpcRecorder(upstream, s.settings.dir, s.settings.protoFiles, s.settings.options)
+	} else if s.settings.specFile != "" && upstream == s.settings.upstream {
+		recorderC, err = proxy2.StartOpenApiRecorder(upstream, s.settings.dir, s.settings.specFile, s.settings.options)
+	} else {
+		recorderC, err = proxy2.StartRecorder(upstream, s.settings.dir, s.settings.options)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.recorders[key] = recorderC
+	return recorderC, nil
+}
+
+// getSpecGenerator returns the spec generator for the upstream,
+// starting it on first use.
+func (s *proxyServer) getSpecGenerator(upstream string) (chan proxy2.HttpExchange, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if specC, ok := s.specGenerators[upstream]; ok {
+		return specC, nil
+	}
+	specC, err := proxy2.StartSpecGenerator(upstream, s.settings.dir, s.settings.options.Redaction)
+	if err != nil {
+		return nil, err
+	}
+	s.specGenerators[upstream] = specC
+	return specC, nil
+}
+
+// getPlayback returns the playback for the upstream, creating it on first use.
+func (s *proxyServer) getPlayback(upstream string) (*proxy2.Playback, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if playback, ok := s.playbacks[upstream]; ok {
+		return playback, nil
+	}
+	playback, err := proxy2.NewPlayback(upstream, s.settings.dir)
+	if err != nil {
+		return nil, err
+	}
+	s.playbacks[upstream] = playback
+	return playback, nil
+}
+
+func (s *proxyServer) handle(upstream string, writer http.ResponseWriter, request *http.Request) {
+	grpc := proxy2.IsGrpcRequest(request)
+	if s.settings.playback && !grpc {
+		playback, err := s.getPlayback(upstream)
+		if err != nil {
+			logger.Warnf("cannot play back recordings for upstream %s: %v", upstream, err)
+		} else if playback.Serve(writer, request) {
+			return
+		}
+	}
+	recorderC, err := s.getRecorder(upstream, grpc)
+	if err != nil {
+		logger.Warnf("exchanges with upstream %s will not be recorded: %v", upstream, err)
+	}
+	var specC chan proxy2.HttpExchange
+	if s.settings.generateSpec && !grpc {
+		if specC, err = s.getSpecGenerator(upstream); err != nil {
+			logger.Warnf("spec will not be generated for upstream %s: %v", upstream, err)
+		}
+	}
+	options := proxy2.HandleOptions{
+		Insecure:    s.settings.insecure,
+		MaxBodySize: s.settings.maxBodySize,
+		Chaos:       s.chaos,
+	}
+	if s.settings.rewrite {
+		options.Rewrite = func(respHeaders *http.Header, respBody *[]byte) *[]byte {
+			return proxy2.Rewrite(respHeaders, respBody, upstream, s.settings.port)
+		}
+	}
+	proxy2.Handle(upstream, writer, request, options, func(exchange proxy2.HttpExchange) {
+		if recorderC != nil {
+			recorderC <- exchange
+		}
+		if s.harC != nil {
+			s.harC <- exchange
+		}
+		if specC != nil {
+			specC <- exchange
+		}
+	})
+}
@@ -20,10 +20,13 @@ import (
 	"fmt"
 	"github.com/imposter-project/imposter-cli/internal/engine"
 	"github.com/imposter-project/imposter-cli/internal/engine/enginetests"
+	"github.com/imposter-project/imposter-cli/internal/har"
+	"github.com/imposter-project/imposter-cli/internal/openapi"
 	"github.com/imposter-project/imposter-cli/internal/proxy"
 	"github.com/sirupsen/logrus"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path"
 	"strings"
@@ -39,6 +42,7 @@ func Test_proxyUpstream(t *testing.T) {
 	type args struct {
 		rewrite bool
 		soap    bool
+		har     bool
 		options proxy.RecorderOptions
 	}
 	tests := []struct {
@@ -73,6 +77,16 @@ func Test_proxyUpstream(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "proxy example.com with HAR export",
+			args: args{
+				rewrite: false,
+				har:     true,
+				options: proxy.RecorderOptions{
+					FlatResponseFileStructure: false,
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		server, upstream, upstreamPort, err := startUpstream()
@@ -90,8 +104,20 @@ func Test_proxyUpstream(t *testing.T) {
 				panic(err)
 			}
 
+			var harFile string
+			if tt.args.har {
+				harFile = path.Join(outputDir, "exchanges.har")
+			}
+
 			go func() {
-				proxyUpstream(upstream, port, outputDir, tt.args.rewrite, false, tt.args.options)
+				proxyUpstream(proxySettings{
+					upstream: upstream,
+					port:     port,
+					dir:      outputDir,
+					rewrite:  tt.args.rewrite,
+					harFile:  harFile,
+					options:  tt.args.options,
+				})
 			}()
 			if up, _ := engine.WaitUntilUp(port, nil); !up {
 				t.Fatalf("proxy did not come up on port %d", port)
@@ -139,6 +165,23 @@ func Test_proxyUpstream(t *testing.T) {
 			}); !indexExists {
 				t.Fatalf("index file not found")
 			}
+
+			if tt.args.har {
+				if harWritten, _ := engine.WaitForOp("HAR entry", 10*time.Second, nil, func() bool {
+					archive, err := har.Parse(harFile)
+					return err == nil && len(archive.Log.Entries) == 1
+				}); !harWritten {
+					t.Fatalf("HAR entry not written")
+				}
+				archive, _ := har.Parse(harFile)
+				entry := archive.Log.Entries[0]
+				if entry.Request.Method != "GET" || entry.Response.Status != 200 || entry.Response.Content.Text != "hello world" {
+					t.Fatalf("unexpected HAR entry: %+v", entry)
+				}
+				if entry.Request.URL != upstream+"/" {
+					t.Fatalf("expected HAR request URL %s/, got: %s", upstream, entry.Request.URL)
+				}
+			}
 		})
 	}
 }
@@ -217,3 +260,194 @@ func sendSoapRequestToProxy(port int) error {
 	logger.Tracef("SOAP proxy up at %s", url)
 	return nil
 }
+
+func Test_proxyUpstream_forwardProxy(t *testing.T) {
+	server, upstream, upstreamPort, err := startUpstream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		server.Close()
+	})
+
+	port := enginetests.GetFreePort()
+	outputDir := t.TempDir()
+	go func() {
+		proxyUpstream(proxySettings{
+			port:         port,
+			dir:          outputDir,
+			forwardProxy: true,
+		})
+	}()
+	if up, _ := engine.WaitUntilUp(port, nil); !up {
+		t.Fatalf("proxy did not come up on port %d", port)
+	}
+
+	proxyUrl, _ := url.Parse(fmt.Sprintf("http://localhost:%d", port))
+	client := http.Client{
+		Timeout:   2 * time.Second,
+		Transport: &http.Transport{Proxy: http.ProxyURL(proxyUrl)},
+	}
+	resp, err := client.Get(upstream + "/forwarded")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != "hello world" {
+		t.Fatalf("expected upstream response via forward proxy, got: %s", body)
+	}
+
+	cfgFileName := fmt.Sprintf("localhost-%d-config.yaml", upstreamPort)
+	if cfgExists, _ := engine.WaitForOp(fmt.Sprintf("config file: %s", cfgFileName), 10*time.Second, nil, func() bool {
+		_, err := os.Stat(path.Join(outputDir, cfgFileName))
+		return err == nil
+	}); !cfgExists {
+		t.Fatalf("config file not found for forwarded upstream")
+	}
+}
+
+func Test_proxyUpstream_routes(t *testing.T) {
+	defaultServer, defaultUpstream, defaultPort, err := startUpstream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	routedServer, routedUpstream, routedPort, err := startUpstream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		defaultServer.Close()
+		routedServer.Close()
+	})
+
+	port := enginetests.GetFreePort()
+	outputDir := t.TempDir()
+	go func() {
+		proxyUpstream(proxySettings{
+			upstream: defaultUpstream,
+			port:     port,
+			dir:      outputDir,
+			routes:   []proxy.Route{{PathPrefix: "/routed", Upstream: routedUpstream}},
+		})
+	}()
+	if up, _ := engine.WaitUntilUp(port, nil); !up {
+		t.Fatalf("proxy did not come up on port %d", port)
+	}
+
+	client := http.Client{Timeout: 2 * time.Second}
+	for _, reqPath := range []string{"/", "/routed/resource"} {
+		resp, err := client.Get(fmt.Sprintf("http://localhost:%d%s", port, reqPath))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+	}
+
+	for _, upstreamPort := range []int{defaultPort, routedPort} {
+		cfgFileName := fmt.Sprintf("localhost-%d-config.yaml", upstreamPort)
+		if cfgExists, _ := engine.WaitForOp(fmt.Sprintf("config file: %s", cfgFileName), 10*time.Second, nil, func() bool {
+			_, err := os.Stat(path.Join(outputDir, cfgFileName))
+			return err == nil
+		}); !cfgExists {
+			t.Fatalf("config file %s not found", cfgFileName)
+		}
+	}
+	routedConfig, err := os.ReadFile(path.Join(outputDir, fmt.Sprintf("localhost-%d-config.yaml", routedPort)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(routedConfig), "path: /routed/resource") {
+		t.Fatalf("expected routed request in routed upstream config, got:\n%s", routedConfig)
+	}
+}
+
+func Test_proxyUpstream_routesToSameHost(t *testing.T) {
+	server, upstream, upstreamPort, err := startUpstream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		server.Close()
+	})
+
+	port := enginetests.GetFreePort()
+	outputDir := t.TempDir()
+	go func() {
+		proxyUpstream(proxySettings{
+			port: port,
+			dir:  outputDir,
+			routes: []proxy.Route{
+				{PathPrefix: "/users", Upstream: upstream + "/v1"},
+				{PathPrefix: "/orders", Upstream: upstream + "/v2"},
+			},
+		})
+	}()
+	if up, _ := engine.WaitUntilUp(port, nil); !up {
+		t.Fatalf("proxy did not come up on port %d", port)
+	}
+
+	client := http.Client{Timeout: 2 * time.Second}
+	for _, reqPath := range []string{"/users/1", "/orders/1"} {
+		resp, err := client.Get(fmt.Sprintf("http://localhost:%d%s", port, reqPath))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+	}
+
+	for _, basePath := range []string{"v1", "v2"} {
+		cfgFileName := fmt.Sprintf("localhost-%d-%s-config.yaml", upstreamPort, basePath)
+		if cfgExists, _ := engine.WaitForOp(fmt.Sprintf("config file: %s", cfgFileName), 10*time.Second, nil, func() bool {
+			_, err := os.Stat(path.Join(outputDir, cfgFileName))
+			return err == nil
+		}); !cfgExists {
+			t.Fatalf("config file %s not found", cfgFileName)
+		}
+	}
+}
+
+func Test_proxyUpstream_generateSpec(t *testing.T) {
+	server, upstream, upstreamPort, err := startUpstream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		server.Close()
+	})
+
+	port := enginetests.GetFreePort()
+	outputDir := t.TempDir()
+	go func() {
+		proxyUpstream(proxySettings{
+			upstream:     upstream,
+			port:         port,
+			dir:          outputDir,
+			generateSpec: true,
+		})
+	}()
+	if up, _ := engine.WaitUntilUp(port, nil); !up {
+		t.Fatalf("proxy did not come up on port %d", port)
+	}
+	if err := sendRequestToProxy(port); err != nil {
+		t.Fatal(err)
+	}
+
+	specFile := path.Join(outputDir, fmt.Sprintf("localhost-%d-openapi.yaml", upstreamPort))
+	if specExists, _ := engine.WaitForOp("spec file", 10*time.Second, nil, func() bool {
+		_, err := os.Stat(specFile)
+		return err == nil
+	}); !specExists {
+		t.Fatalf("spec file %s not found", specFile)
+	}
+	spec, err := openapi.Parse(specFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := spec.Paths["/"]["get"]
+	if op == nil || op.Responses["200"] == nil {
+		t.Fatalf("expected GET / operation with 200 response in spec, got: %+v", spec.Paths)
+	}
+}
@@ -18,15 +18,22 @@ package cmd
 
 import (
 	impostermodel2 "github.com/imposter-project/imposter-cli/internal/impostermodel"
+	"github.com/imposter-project/imposter-cli/internal/specfetch"
 	"github.com/spf13/cobra"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 var scaffoldFlags = struct {
 	forceOverwrite    bool
 	generateResources bool
 	scriptEngine      string
+	seed              int64
+	statusTrigger     string
+	from              string
+	headers           []string
 }{}
 
 // scaffoldCmd represents the up command
@@ -39,6 +46,32 @@ specification files, WSDL files or protobuf files are present, they are used
 as the basis for the generated configuration. If no specification files are
 present, a simple REST mock is created.
 
+For OpenAPI/Swagger specifications, a resource is generated for each
+operation, matching required parameters that have examples. Each response
+example is written to a response file in a directory named after the
+specification. If a response has no example, fake data is generated from
+its schema, respecting types, formats, enums and constraints. The same
+data is generated each time, so re-running scaffold does not change the
+response files; pass a different --seed to generate different data.
+
+A resource is also generated for each documented 4xx and 5xx response,
+selected by the 'X-Mock-Status' request header, such as 'X-Mock-Status: 404'.
+Pass '--status-trigger query' to select them with the 'mockStatus' query
+parameter instead, or '--status-trigger none' to skip them.
+
+For WSDL files, a resource is generated for each operation, with a sample
+SOAP response envelope shaped by the XSD types of its output message. If
+the operation declares a fault, a resource returning a sample SOAP fault
+is also generated, selected in the same way as error responses.
+
+For protobuf files, a resource is generated for each method of each service,
+with a JSON response file containing a sample of the output message.
+
+Pass --from with an HTTP(S) or file URL to fetch a specification, and the
+files it references, into DIR first, such as from a registry. Use --header
+to send a header when fetching, such as for authentication. References are
+only followed if they use the same scheme as the URL.
+
 If DIR is not specified, the current working directory is used.`,
 	Args: cobra.RangeArgs(0, 1),
 	Run: func(cmd *cobra.Command, args []string) {
@@ -48,8 +81,16 @@ If DIR is not specified, the current working directory is used.`,
 		} else {
 			configDir, _ = filepath.Abs(args[0])
 		}
+		if scaffoldFlags.from != "" {
+			fetchSpec(configDir, scaffoldFlags.from, scaffoldFlags.headers, scaffoldFlags.forceOverwrite)
+		}
 		scriptEngine := impostermodel2.ParseScriptEngine(scaffoldFlags.scriptEngine)
-		impostermodel2.Create(configDir, scaffoldFlags.generateResources, scaffoldFlags.forceOverwrite, scriptEngine, false)
+		impostermodel2.CreateWithOptions(configDir, scaffoldFlags.generateResources, false, impostermodel2.ResourceGenerationOptions{
+			ScriptEngine:   scriptEngine,
+			ForceOverwrite: scaffoldFlags.forceOverwrite,
+			StatusTrigger:  impostermodel2.ParseStatusTrigger(scaffoldFlags.statusTrigger),
+			Seed:           scaffoldFlags.seed,
+		})
 	},
 }
 
@@ -57,5 +98,26 @@ func init() {
 	scaffoldCmd.Flags().BoolVarP(&scaffoldFlags.forceOverwrite, "force-overwrite", "f", false, "Force overwrite of destination file(s) if already exist")
 	scaffoldCmd.Flags().BoolVar(&scaffoldFlags.generateResources, "generate-resources", true, "Generate Imposter resources from OpenAPI paths or WSDL operations")
 	scaffoldCmd.Flags().StringVarP(&scaffoldFlags.scriptEngine, "script-engine", "s", "none", "Generate placeholder Imposter script (none|groovy|js)")
+	scaffoldCmd.Flags().Int64Var(&scaffoldFlags.seed, "seed", 0, "Seed for data generated from response schemas; the same seed generates the same response files")
+	scaffoldCmd.Flags().StringVar(&scaffoldFlags.statusTrigger, "status-trigger", "header", "How requests select documented error responses (header|query|none)")
+	scaffoldCmd.Flags().StringVar(&scaffoldFlags.from, "from", "", "HTTP(S) or file URL of an OpenAPI, WSDL or proto file to fetch into DIR before scaffolding")
+	scaffoldCmd.Flags().StringArrayVarP(&scaffoldFlags.headers, "header", "H", nil, "Header to send when fetching the --from URL, as 'Name: value' (can be repeated)")
 	rootCmd.AddCommand(scaffoldCmd)
 }
+
+func fetchSpec(configDir string, from string, rawHeaders []string, forceOverwrite bool) {
+	headers := http.Header{}
+	for _, rawHeader := range rawHeaders {
+		name, value, found := strings.Cut(rawHeader, ":")
+		if !found {
+			logger.Fatalf("invalid header: %s - must be in the form 'Name: value'", rawHeader)
+		}
+		headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		logger.Fatalf("failed to create directory: %s: %v", configDir, err)
+	}
+	if _, err := specfetch.Fetch(from, configDir, headers, forceOverwrite); err != nil {
+		logger.Fatalf("failed to fetch specification: %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/imposter-project/imposter-cli/internal/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var validateFlags = struct {
+	recursiveConfigScan bool
+}{}
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate [DIR]",
+	Short: "Check Imposter configuration for errors",
+	Long: `Checks the Imposter configuration files in DIR for errors, without
+starting a mock.
+
+Each configuration file is checked for unknown properties and properties
+of the wrong type, and that the response, script, specification, WSDL and
+proto files it references exist. Specifications, WSDL and proto files are
+also parsed. Problems are reported as FILE:LINE: MESSAGE. The engine
+accepts more than is checked here, so unknown properties and properties
+of the wrong type are reported as warnings.
+
+Exits with a non-zero status if any errors are found, such as for use in CI.
+
+If DIR is not specified, the current working directory is used.`,
+	Args: cobra.RangeArgs(0, 1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var configDir string
+		if len(args) == 0 {
+			configDir, _ = os.Getwd()
+		} else {
+			configDir, _ = filepath.Abs(args[0])
+		}
+		recursive := validateFlags.recursiveConfigScan || viper.GetBool("config.scan.recursive")
+		if !validateConfig(os.Stdout, configDir, recursive) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	validateCmd.Flags().BoolVarP(&validateFlags.recursiveConfigScan, "recursive-config-scan", "r", false, "Scan for config files in subdirectories")
+	rootCmd.AddCommand(validateCmd)
+}
+
+// validateConfig writes the problems found in the configuration
+// files to out, returning false if there are any errors.
+func validateConfig(out io.Writer, configDir string, recursive bool) bool {
+	problems, count, err := config.Validate(configDir, recursive)
+	if err != nil {
+		logger.Error(err)
+		return false
+	}
+	if count == 0 {
+		logger.Errorf("no Imposter configuration files found in: %s", configDir)
+		return false
+	}
+
+	errorCount := 0
+	for _, problem := range problems {
+		if relFile, err := filepath.Rel(configDir, problem.File); err == nil {
+			problem.File = relFile
+		}
+		_, _ = fmt.Fprintln(out, problem)
+		if !problem.Warning {
+			errorCount++
+		}
+	}
+	if errorCount > 0 {
+		logger.Errorf("found %d error(s) in %d configuration file(s)", errorCount, count)
+		return false
+	}
+	logger.Infof("validated %d configuration file(s) with %d warning(s)", count, len(problems))
+	return true
+}
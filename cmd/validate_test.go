@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_validateConfig(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "mock-config.yaml"), []byte(`plugin: rest
+resources:
+  - path: /example
+    method: GET
+    response:
+      file: response.json
+`), 0644))
+
+	var out bytes.Buffer
+	require.False(t, validateConfig(&out, dir, false), "missing files should be an error")
+	require.Equal(t, "mock-config.yaml:6: response file not found: response.json\n", out.String())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "response.json"), []byte(`{}`), 0644))
+	out.Reset()
+	require.True(t, validateConfig(&out, dir, false))
+	require.Empty(t, out.String())
+
+	require.False(t, validateConfig(&out, t.TempDir(), false), "a directory without configuration should be an error")
+}
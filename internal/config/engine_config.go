@@ -69,6 +69,28 @@ func ContainsConfigFile(configDir string, recursive bool) bool {
 	return false
 }
 
+// FindConfigFiles returns the paths of the files within the specified
+// configDir that match the expected naming format, in directory order.
+func FindConfigFiles(configDir string, recursive bool) ([]string, error) {
+	files, err := os.ReadDir(configDir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list directory contents: %v: %v", configDir, err)
+	}
+	var configFiles []string
+	for _, file := range files {
+		if file.IsDir() && recursive {
+			nested, err := FindConfigFiles(filepath.Join(configDir, file.Name()), recursive)
+			if err != nil {
+				return nil, err
+			}
+			configFiles = append(configFiles, nested...)
+		} else if !file.IsDir() && matchesConfigFileFmt(file) {
+			configFiles = append(configFiles, filepath.Join(configDir, file.Name()))
+		}
+	}
+	return configFiles, nil
+}
+
 func matchesConfigFileFmt(file os.DirEntry) bool {
 	for _, configFileSuffix := range getConfigFileSuffixes() {
 		if strings.HasSuffix(file.Name(), configFileSuffix) {
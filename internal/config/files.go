@@ -0,0 +1,51 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/imposter-project/imposter-cli/internal/fileutil"
+	"github.com/imposter-project/imposter-cli/internal/openapi"
+	"github.com/imposter-project/imposter-cli/internal/stringutil"
+)
+
+// ListConfigFiles returns the files in the configuration directory, along
+// with the files in its subdirectories that are referenced by OpenAPI specs,
+// such as the fragments of a multi-file spec.
+func ListConfigFiles(configDir string) ([]string, error) {
+	absDir, err := filepath.Abs(configDir)
+	if err != nil {
+		return nil, err
+	}
+	files, err := fileutil.ListFiles(absDir, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, dependency := range openapi.DiscoverDependencies(absDir) {
+		relPath, err := filepath.Rel(absDir, dependency)
+		if err != nil || strings.HasPrefix(relPath, "..") {
+			logger.Warnf("file referenced by spec is outside the configuration directory and will not be included: %s", dependency)
+			continue
+		}
+		if !stringutil.Contains(files, dependency) {
+			files = append(files, dependency)
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,359 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/imposter-project/imposter-cli/internal/impostermodel"
+	"github.com/imposter-project/imposter-cli/internal/openapi"
+	"github.com/imposter-project/imposter-cli/internal/protobuf"
+	wsdlparser "github.com/outofcoffee/go-wsdl-parser"
+	"gopkg.in/yaml.v3"
+)
+
+var yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// matcherFields are the properties of a resource whose values may be
+// given as a string to match, or as a matcher object.
+var matcherFields = []string{"pathParams", "queryParams", "requestHeaders"}
+
+var matchOperators = []string{"EqualTo", "NotEqualTo", "Contains", "NotContains", "Matches", "NotMatches", "Exists", "NotExists"}
+
+// matcher is the object form of a matcher, such as
+// {value: abc, operator: Contains}.
+type matcher struct {
+	Value    string `json:"value"`
+	Operator string `json:"operator"`
+}
+
+// Problem is an error or warning found in a configuration file.
+type Problem struct {
+	File    string
+	Line    int
+	Message string
+
+	// Warning is true if the problem may not prevent the
+	// configuration from loading, such as an unknown property.
+	Warning bool
+}
+
+func (p Problem) String() string {
+	if p.Warning {
+		return fmt.Sprintf("%s:%d: warning: %s", p.File, p.Line, p.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", p.File, p.Line, p.Message)
+}
+
+// Validate checks each configuration file in the configDir against the
+// structure of impostermodel.PluginConfig, and checks that the files it
+// references exist and, for specifications, can be parsed.
+//
+// Returns the problems found, in file order, and the number of
+// configuration files checked.
+func Validate(configDir string, recursive bool) ([]Problem, int, error) {
+	configFiles, err := FindConfigFiles(configDir, recursive)
+	if err != nil {
+		return nil, 0, err
+	}
+	var problems []Problem
+	for _, configFile := range configFiles {
+		logger.Debugf("validating config file: %s", configFile)
+		problems = append(problems, validateConfigFile(configFile)...)
+	}
+	return problems, len(configFiles), nil
+}
+
+type configValidator struct {
+	file     string
+	problems []Problem
+}
+
+func (v *configValidator) errorf(node *yaml.Node, format string, args ...any) {
+	v.problems = append(v.problems, Problem{File: v.file, Line: node.Line, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *configValidator) warnf(node *yaml.Node, format string, args ...any) {
+	v.problems = append(v.problems, Problem{File: v.file, Line: node.Line, Message: fmt.Sprintf(format, args...), Warning: true})
+}
+
+func validateConfigFile(configFile string) []Problem {
+	v := &configValidator{file: configFile}
+	f, err := os.Open(configFile)
+	if err != nil {
+		return []Problem{{File: configFile, Message: err.Error()}}
+	}
+	defer f.Close()
+
+	// JSON is parsed as YAML, so problems in either are reported by line
+	decoder := yaml.NewDecoder(f)
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			problem := Problem{File: configFile, Message: err.Error()}
+			if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+				problem.Line, _ = strconv.Atoi(match[1])
+				problem.Message = match[2]
+			}
+			return append(v.problems, problem)
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		root := resolveAlias(doc.Content[0])
+		v.checkNode(root, reflect.TypeOf(impostermodel.PluginConfig{}), "")
+		if root.Kind == yaml.MappingNode {
+			v.checkPlugin(root)
+		}
+	}
+	return v.problems
+}
+
+// checkNode checks that the node has the structure of the type,
+// using the JSON names of the fields of structs. The engine accepts
+// more than the model describes, so differences are reported as
+// warnings rather than errors.
+func (v *configValidator) checkNode(node *yaml.Node, t reflect.Type, path string) {
+	node = resolveAlias(node)
+	if node.Tag == "!!null" {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			v.warnf(node, "%s should be an object", describe(path))
+			return
+		}
+		fields := jsonFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, ok := fields[key.Value]
+			if !ok {
+				v.warnf(key, "unknown property '%s' in %s", key.Value, describe(path))
+				continue
+			}
+			if slices.Contains(matcherFields, key.Value) {
+				v.checkMatchers(value, joinPath(path, key.Value))
+			} else {
+				v.checkNode(value, field.Type, joinPath(path, key.Value))
+			}
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			v.warnf(node, "%s should be an object", describe(path))
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			v.checkNode(node.Content[i+1], t.Elem(), joinPath(path, node.Content[i].Value))
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			v.warnf(node, "%s should be a list", describe(path))
+			return
+		}
+		for i, item := range node.Content {
+			v.checkNode(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+	case reflect.Int:
+		if _, err := strconv.Atoi(node.Value); node.Kind != yaml.ScalarNode || err != nil {
+			v.warnf(node, "%s should be an integer", describe(path))
+		}
+	case reflect.Bool:
+		if _, err := strconv.ParseBool(node.Value); node.Kind != yaml.ScalarNode || err != nil {
+			v.warnf(node, "%s should be a boolean", describe(path))
+		}
+	default:
+		if node.Kind != yaml.ScalarNode {
+			v.warnf(node, "%s should be a string", describe(path))
+		}
+	}
+}
+
+// checkMatchers checks that each matcher is either a string, or an
+// object with a value and an operator, such as {operator: Exists}.
+func (v *configValidator) checkMatchers(node *yaml.Node, path string) {
+	node = resolveAlias(node)
+	if node.Tag == "!!null" {
+		return
+	}
+	if node.Kind != yaml.MappingNode {
+		v.warnf(node, "%s should be an object", describe(path))
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		value, matcherPath := resolveAlias(node.Content[i+1]), joinPath(path, node.Content[i].Value)
+		if value.Kind != yaml.MappingNode {
+			v.checkNode(value, reflect.TypeOf(""), matcherPath)
+			continue
+		}
+		v.checkNode(value, reflect.TypeOf(matcher{}), matcherPath)
+		if operator := scalarMember(value, "operator"); operator != nil && !slices.Contains(matchOperators, operator.Value) {
+			v.warnf(operator, "unknown operator '%s' in %s", operator.Value, describe(matcherPath))
+		}
+	}
+}
+
+// checkPlugin checks the plugin is set, and the files referenced
+// by the configuration exist and can be parsed.
+func (v *configValidator) checkPlugin(root *yaml.Node) {
+	if plugin := member(root, "plugin"); plugin == nil || plugin.Value == "" {
+		v.errorf(root, "plugin is required")
+	}
+	if specFile := scalarMember(root, "specFile"); specFile != nil {
+		if path, ok := v.checkFileExists(specFile, "spec file"); ok {
+			if _, err := openapi.Parse(path); err != nil {
+				v.errorf(specFile, "failed to parse spec file %s: %v", specFile.Value, err)
+			}
+		}
+	}
+	if wsdlFile := scalarMember(root, "wsdlFile"); wsdlFile != nil {
+		if path, ok := v.checkFileExists(wsdlFile, "WSDL file"); ok {
+			if _, err := wsdlparser.NewWSDLParser(path); err != nil {
+				v.errorf(wsdlFile, "failed to parse WSDL file %s: %v", wsdlFile.Value, err)
+			}
+		}
+	}
+	if pluginConfig := member(root, "config"); pluginConfig != nil && pluginConfig.Kind == yaml.MappingNode {
+		if protoFiles := member(pluginConfig, "protoFiles"); protoFiles != nil && protoFiles.Kind == yaml.SequenceNode {
+			var paths []string
+			for _, protoFile := range protoFiles.Content {
+				if path, ok := v.checkFileExists(resolveAlias(protoFile), "proto file"); ok {
+					paths = append(paths, path)
+				}
+			}
+			if len(paths) == len(protoFiles.Content) && len(paths) > 0 {
+				if _, err := protobuf.Parse(paths); err != nil {
+					v.errorf(protoFiles, "%v", err)
+				}
+			}
+		}
+	}
+	v.checkResponse(member(root, "response"))
+
+	if resources := member(root, "resources"); resources != nil && resources.Kind == yaml.SequenceNode {
+		for _, resource := range resources.Content {
+			resource = resolveAlias(resource)
+			if resource.Kind != yaml.MappingNode {
+				continue
+			}
+			v.checkResponse(member(resource, "response"))
+			if steps := member(resource, "steps"); steps != nil && steps.Kind == yaml.SequenceNode {
+				for _, step := range steps.Content {
+					if step = resolveAlias(step); step.Kind == yaml.MappingNode {
+						if scriptFile := scalarMember(step, "file"); scriptFile != nil {
+							v.checkFileExists(scriptFile, "script file")
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func (v *configValidator) checkResponse(response *yaml.Node) {
+	if response == nil || response.Kind != yaml.MappingNode {
+		return
+	}
+	if responseFile := scalarMember(response, "file"); responseFile != nil {
+		v.checkFileExists(responseFile, "response file")
+	}
+}
+
+// checkFileExists checks that the file named by the node exists, relative
+// to the configuration file, returning its path. URLs and templated paths
+// are not checked, as they are only resolved by the engine.
+func (v *configValidator) checkFileExists(node *yaml.Node, description string) (string, bool) {
+	if node.Kind != yaml.ScalarNode || node.Value == "" || strings.Contains(node.Value, "://") || strings.Contains(node.Value, "${") {
+		return "", false
+	}
+	path := node.Value
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(v.file), filepath.FromSlash(path))
+	}
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		v.errorf(node, "%s not found: %s", description, node.Value)
+		return "", false
+	}
+	return path, true
+}
+
+// jsonFields returns the fields of the struct, keyed by their JSON names.
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		} else if name == "" {
+			name = field.Name
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+func member(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return resolveAlias(node.Content[i+1])
+		}
+	}
+	return nil
+}
+
+func scalarMember(node *yaml.Node, key string) *yaml.Node {
+	if value := member(node, key); value != nil && value.Kind == yaml.ScalarNode {
+		return value
+	}
+	return nil
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func describe(path string) string {
+	if path == "" {
+		return "configuration"
+	}
+	return "'" + path + "'"
+}
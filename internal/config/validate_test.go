@@ -0,0 +1,126 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestFile(t *testing.T, file string, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+	require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "response.json"), `{}`)
+	writeTestFile(t, filepath.Join(dir, "pets.yaml"), "openapi: \"3.0.1\"\ninfo:\n  title: Pets\n  version: 1.0.0\npaths: {}\n")
+	writeTestFile(t, filepath.Join(dir, "valid-config.yaml"), `plugin: openapi
+specFile: pets.yaml
+resources:
+  - path: /pets
+    method: GET
+    response:
+      statusCode: 200
+      file: response.json
+`)
+	writeTestFile(t, filepath.Join(dir, "invalid-config.yaml"), `plugin: rest
+resources:
+  - path: /pets
+    method: GET
+    response:
+      statusCode: ok
+      file: missing.json
+    steps:
+      - type: script
+        file: missing.js
+  - path: /other
+    requestHeaders: [a, b]
+    responce:
+      statusCode: 200
+`)
+	writeTestFile(t, filepath.Join(dir, "nested", "soap-config.json"), `{
+  "plugin": "soap",
+  "wsdlFile": "missing.wsdl"
+}`)
+
+	problems, count, err := Validate(dir, false)
+	require.NoError(t, err)
+	require.Equal(t, 2, count, "config files in subdirectories should only be found if recursive")
+
+	var messages []string
+	for _, problem := range problems {
+		messages = append(messages, problem.String())
+	}
+	invalidFile := filepath.Join(dir, "invalid-config.yaml")
+	require.Equal(t, []string{
+		invalidFile + ":6: warning: 'resources[0].response.statusCode' should be an integer",
+		invalidFile + ":12: warning: 'resources[1].requestHeaders' should be an object",
+		invalidFile + ":13: warning: unknown property 'responce' in 'resources[1]'",
+		invalidFile + ":7: response file not found: missing.json",
+		invalidFile + ":10: script file not found: missing.js",
+	}, messages)
+
+	problems, count, err = Validate(dir, true)
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+	require.Contains(t, problems, Problem{File: filepath.Join(dir, "nested", "soap-config.json"), Line: 3, Message: "WSDL file not found: missing.wsdl"})
+}
+
+func TestValidate_matchers(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "matchers-config.yaml")
+	writeTestFile(t, configFile, `plugin: rest
+resources:
+  - path: /pets/{petId}
+    method: GET
+    pathParams:
+      petId: "1"
+    queryParams:
+      limit:
+        value: "10"
+        operator: EqualTo
+    requestHeaders:
+      X-Api-Key:
+        operator: Exists
+      X-Trace:
+        operator: Missing
+      X-Tenant: [a, b]
+    response:
+      statusCode: 200
+`)
+
+	problems, _, err := Validate(dir, false)
+	require.NoError(t, err)
+
+	var messages []string
+	for _, problem := range problems {
+		messages = append(messages, problem.String())
+	}
+	require.Equal(t, []string{
+		configFile + ":15: warning: unknown operator 'Missing' in 'resources[0].requestHeaders.X-Trace'",
+		configFile + ":16: warning: 'resources[0].requestHeaders.X-Tenant' should be a string",
+	}, messages)
+}
+
+func TestValidate_unparseable(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "pets.yaml"), "swagger: \"2.0\"\npaths:\n  /pets:\n    get:\n      responses:\n        \"200\":\n          schema:\n            $ref: \"#/definitions/Missing\"\n")
+	writeTestFile(t, filepath.Join(dir, "spec-config.yaml"), "plugin: openapi\nspecFile: pets.yaml\n")
+	writeTestFile(t, filepath.Join(dir, "broken-config.yaml"), "plugin: rest\nresources:\n  - path: /pets\n   method: GET\n")
+	writeTestFile(t, filepath.Join(dir, "empty-config.yaml"), "resources: []\n")
+
+	problems, _, err := Validate(dir, false)
+	require.NoError(t, err)
+	require.Len(t, problems, 3)
+
+	require.Equal(t, Problem{File: filepath.Join(dir, "broken-config.yaml"), Line: 2, Message: "did not find expected '-' indicator"}, problems[0])
+
+	require.Equal(t, Problem{File: filepath.Join(dir, "empty-config.yaml"), Line: 1, Message: "plugin is required"}, problems[1])
+
+	require.Equal(t, filepath.Join(dir, "spec-config.yaml"), problems[2].File)
+	require.Equal(t, 2, problems[2].Line)
+	require.Contains(t, problems[2].Message, "failed to parse spec file pets.yaml")
+}
@@ -20,10 +20,12 @@ import (
 	"archive/zip"
 	"bytes"
 	"fmt"
+	"github.com/imposter-project/imposter-cli/internal/config"
 	"github.com/imposter-project/imposter-cli/internal/fileutil"
 	"io"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 )
 
@@ -54,11 +56,11 @@ func CreateDeploymentPackage(version string, dir string, arch string) (*[]byte,
 	if err != nil {
 		return nil, err
 	}
-	local, err := fileutil.ListFiles(dir, false)
+	local, err := config.ListConfigFiles(dir)
 	if err != nil {
 		return nil, err
 	}
-	pkg, err := addFilesToZip(binaryPath, local)
+	pkg, err := addFilesToZip(binaryPath, dir, local)
 	if err != nil {
 		return nil, err
 	}
@@ -67,7 +69,7 @@ func CreateDeploymentPackage(version string, dir string, arch string) (*[]byte,
 	return &contents, nil
 }
 
-func addFilesToZip(zipPath string, files []string) (*bytes.Buffer, error) {
+func addFilesToZip(zipPath string, dir string, files []string) (*bytes.Buffer, error) {
 	zr, err := zip.OpenReader(zipPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open source zip: %s: %v", zipPath, err)
@@ -97,10 +99,19 @@ func addFilesToZip(zipPath string, files []string) (*bytes.Buffer, error) {
 		}
 	}
 
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
 	logger.Infof("bundling %d files from workspace", len(files))
 	for _, localFile := range files {
 		logger.Tracef("bundling %s", localFile)
-		f, err := zw.Create(path.Join("config", path.Base(localFile)))
+		// files referenced by specs may be in subdirectories
+		relPath, err := filepath.Rel(absDir, localFile)
+		if err != nil {
+			return nil, err
+		}
+		f, err := zw.Create(path.Join("config", filepath.ToSlash(relPath)))
 		if err != nil {
 			return nil, err
 		}
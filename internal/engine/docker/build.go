@@ -6,7 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/docker/docker/api/types"
-	"github.com/imposter-project/imposter-cli/internal/fileutil"
+	"github.com/imposter-project/imposter-cli/internal/config"
 	"io"
 	"os"
 	"path/filepath"
@@ -51,7 +51,11 @@ func addFilesToTar(dir string, parentImage string) (*bytes.Buffer, error) {
 	buf := new(bytes.Buffer)
 	tarWriter := tar.NewWriter(buf)
 
-	local, err := fileutil.ListFiles(dir, false)
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	local, err := config.ListConfigFiles(dir)
 	if err != nil {
 		return nil, err
 	}
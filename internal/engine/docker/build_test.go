@@ -20,6 +20,19 @@ func Test_addFilesToTar(t *testing.T) {
 		t.Fatal(fmt.Errorf("error writing test config file: %s", err.Error()))
 	}
 
+	spec := []byte("openapi: 3.0.0\npaths:\n  /pets:\n    $ref: \"paths/pets.yaml\"\n")
+	fragment := []byte("get:\n  responses: {}\n")
+	specDir := t.TempDir()
+	if err := os.MkdirAll(specDir+"/paths", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(specDir+"/api.yaml", spec, 0644); err != nil {
+		t.Fatal(fmt.Errorf("error writing test spec file: %s", err.Error()))
+	}
+	if err := os.WriteFile(specDir+"/paths/pets.yaml", fragment, 0644); err != nil {
+		t.Fatal(fmt.Errorf("error writing test fragment file: %s", err.Error()))
+	}
+
 	type args struct {
 		dir         string
 		parentImage string
@@ -58,6 +71,37 @@ func Test_addFilesToTar(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "should add files referenced by specs in subdirectories",
+			args: args{
+				dir:         specDir,
+				parentImage: "imposter:latest",
+			},
+			want: []want{
+				{
+					header: tar.Header{
+						Name: "config/api.yaml",
+						Size: int64(len(spec)),
+					},
+					body: spec,
+				},
+				{
+					header: tar.Header{
+						Name: "config/paths/pets.yaml",
+						Size: int64(len(fragment)),
+					},
+					body: fragment,
+				},
+				{
+					header: tar.Header{
+						Name: "Dockerfile",
+						Size: int64(len(dockerfile)),
+					},
+					body: dockerfile,
+				},
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -18,15 +18,23 @@ package fileutil
 
 import (
 	"github.com/radovskyb/watcher"
+	"path/filepath"
 	"time"
 )
 
-const watchDebounceMs = 1000
+var (
+	// watchPollInterval is how often watched files are checked for changes.
+	watchPollInterval = 500 * time.Millisecond
+
+	// watchDebounce is how long changes are collected before notifying.
+	watchDebounce = time.Second
+)
 
 // WatchDir observes changes to the given directory
 // and notifies on a channel when they occur.
 func WatchDir(dir string) (updatedC chan bool) {
 	updatedC = make(chan bool)
+	pollInterval, debounce := watchPollInterval, watchDebounce
 
 	w := watcher.New()
 	if err := w.AddRecursive(dir); err != nil {
@@ -50,14 +58,14 @@ func WatchDir(dir string) (updatedC chan bool) {
 	}()
 
 	go func() {
-		if err := w.Start(time.Millisecond * 500); err != nil {
+		if err := w.Start(pollInterval); err != nil {
 			logger.Warnln(err)
 		}
 	}()
 
 	// debounce multiple events
 	go func() {
-		ticker := time.NewTicker(time.Millisecond * watchDebounceMs)
+		ticker := time.NewTicker(debounce)
 		defer ticker.Stop()
 		for {
 			<-ticker.C
@@ -70,3 +78,54 @@ func WatchDir(dir string) (updatedC chan bool) {
 
 	return updatedC
 }
+
+// WatchFile observes changes to the given file, including it being
+// replaced, as some editors do when saving, and notifies on a channel
+// once the changes have stopped for the debounce period.
+func WatchFile(file string) (updatedC chan bool) {
+	updatedC = make(chan bool)
+	pollInterval, debounce := watchPollInterval, watchDebounce
+
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		logger.Warnln(err)
+		absFile = file
+	}
+
+	// watch the parent, as the file itself may be removed and recreated
+	w := watcher.New()
+	if err := w.Add(filepath.Dir(absFile)); err != nil {
+		logger.Warnln(err)
+	}
+
+	go func() {
+		logger.Infof("watching for changes to: %v", file)
+
+		// debounce multiple events, such as from an editor writing the file
+		// in several steps, notifying once the file has stopped changing
+		var debounceC <-chan time.Time
+		for {
+			select {
+			case event := <-w.Event:
+				if event.Path == absFile || event.OldPath == absFile {
+					debounceC = time.After(debounce)
+				}
+			case <-debounceC:
+				debounceC = nil
+				updatedC <- true
+			case err := <-w.Error:
+				logger.Warnln(err)
+			case <-w.Closed:
+				return
+			}
+		}
+	}()
+
+	go func() {
+		if err := w.Start(pollInterval); err != nil {
+			logger.Warnln(err)
+		}
+	}()
+
+	return updatedC
+}
@@ -0,0 +1,43 @@
+package fileutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchFile_debounce(t *testing.T) {
+	pollInterval, debounce := watchPollInterval, watchDebounce
+	watchPollInterval, watchDebounce = 5*time.Millisecond, 100*time.Millisecond
+	t.Cleanup(func() {
+		watchPollInterval, watchDebounce = pollInterval, debounce
+	})
+
+	file := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(file, []byte("rules: []\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	updatedC := WatchFile(file)
+	time.Sleep(4 * watchPollInterval)
+
+	// write the file in several steps, as some editors do
+	for i := 0; i < 3; i++ {
+		if err := os.WriteFile(file, []byte(fmt.Sprintf("rules: []\n# edit %d\n", i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(4 * watchPollInterval)
+	}
+
+	select {
+	case <-updatedC:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected notification of change")
+	}
+	select {
+	case <-updatedC:
+		t.Fatal("expected a single notification for several writes")
+	case <-time.After(3 * watchDebounce):
+	}
+}
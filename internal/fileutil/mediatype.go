@@ -0,0 +1,87 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fileutil
+
+import (
+	"mime"
+	"regexp"
+	"strings"
+)
+
+// mediaTypeExtensions maps media types to the extension of files with
+// that content. A fixed table is used, rather than the mime package, so
+// that file names do not depend on the mime.types files of the machine.
+var mediaTypeExtensions = map[string]string{
+	"application/gzip":         ".gz",
+	"application/javascript":   ".js",
+	"application/json":         ".json",
+	"application/octet-stream": ".bin",
+	"application/pdf":          ".pdf",
+	"application/x-yaml":       ".yaml",
+	"application/xml":          ".xml",
+	"application/yaml":         ".yaml",
+	"application/zip":          ".zip",
+	"image/avif":               ".avif",
+	"image/gif":                ".gif",
+	"image/jpeg":               ".jpeg",
+	"image/png":                ".png",
+	"image/svg+xml":            ".svg",
+	"image/webp":               ".webp",
+	"text/css":                 ".css",
+	"text/csv":                 ".csv",
+	"text/html":                ".htm",
+	"text/javascript":          ".js",
+	"text/markdown":            ".md",
+	"text/plain":               ".txt",
+	"text/xml":                 ".xml",
+	"text/yaml":                ".yaml",
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// ExtensionForMediaType returns the file extension for the media type,
+// such as .json for 'application/json; charset=utf-8' or
+// 'application/problem+json', or an empty string if it is not known.
+func ExtensionForMediaType(mediaType string) string {
+	if parsed, _, err := mime.ParseMediaType(mediaType); err == nil {
+		mediaType = parsed
+	} else {
+		mediaType = strings.ToLower(strings.TrimSpace(strings.Split(mediaType, ";")[0]))
+	}
+	if extension, ok := mediaTypeExtensions[mediaType]; ok {
+		return extension
+	}
+	switch {
+	case strings.HasSuffix(mediaType, "+json"):
+		return ".json"
+	case strings.HasSuffix(mediaType, "+xml"):
+		return ".xml"
+	case strings.HasSuffix(mediaType, "+yaml"):
+		return ".yaml"
+	}
+	return ""
+}
+
+// SanitiseFileName replaces the characters of the name that are unsafe in
+// file names with hyphens, returning the fallback if none are left.
+func SanitiseFileName(name string, fallback string) string {
+	sanitised := strings.Trim(unsafeFileNameChars.ReplaceAllString(name, "-"), "-")
+	if sanitised == "" {
+		return fallback
+	}
+	return sanitised
+}
@@ -0,0 +1,34 @@
+package fileutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtensionForMediaType(t *testing.T) {
+	tests := []struct {
+		mediaType string
+		want      string
+	}{
+		{mediaType: "application/json", want: ".json"},
+		{mediaType: "application/json; charset=utf-8", want: ".json"},
+		{mediaType: "application/problem+json", want: ".json"},
+		{mediaType: "application/soap+xml; action=foo", want: ".xml"},
+		{mediaType: "Text/HTML", want: ".htm"},
+		{mediaType: "image/png", want: ".png"},
+		{mediaType: "application/x-unknown", want: ""},
+		{mediaType: "not a valid media type", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mediaType, func(t *testing.T) {
+			require.Equal(t, tt.want, ExtensionForMediaType(tt.mediaType))
+		})
+	}
+}
+
+func TestSanitiseFileName(t *testing.T) {
+	require.Equal(t, "Pet-Store", SanitiseFileName("Pet Store!", "fallback"))
+	require.Equal(t, "get-pets", SanitiseFileName("/get/pets/", "fallback"))
+	require.Equal(t, "fallback", SanitiseFileName("!!!", "fallback"))
+}
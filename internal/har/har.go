@@ -0,0 +1,194 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package har models HTTP Archive (HAR) 1.2 files.
+// See http://www.softwareishard.com/blog/har-12-spec/
+package har
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+type Har struct {
+	Log Log `json:"log"`
+}
+
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+}
+
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type Entry struct {
+	StartedDateTime string   `json:"startedDateTime"`
+	Time            float64  `json:"time"`
+	Request         Request  `json:"request"`
+	Response        Response `json:"response"`
+	Cache           struct{} `json:"cache"`
+	Timings         Timings  `json:"timings"`
+
+	// WebSocketMessages is a non-standard field, as written by Chrome
+	// developer tools, holding the messages of a WebSocket connection.
+	WebSocketMessages []WebSocketMessage `json:"_webSocketMessages,omitempty"`
+}
+
+// WebSocketMessage is a message sent ("send") or received ("receive") by the
+// client over a WebSocket. Time is in seconds since the epoch. Opcode 1 is a
+// text message; opcode 2 is a binary message, with base64 encoded data.
+type WebSocketMessage struct {
+	Type   string  `json:"type"`
+	Time   float64 `json:"time"`
+	Opcode int     `json:"opcode"`
+	Data   string  `json:"data"`
+}
+
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    *PostData   `json:"postData,omitempty"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+type PostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+type Content struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Timings holds the phases of an exchange, in milliseconds. A value
+// of -1 indicates the phase does not apply.
+type Timings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// Parse reads the HAR file at the given path.
+func Parse(harFile string) (*Har, error) {
+	raw, err := os.ReadFile(harFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HAR file: %s: %v", harFile, err)
+	}
+	var h Har
+	if err := json.Unmarshal(raw, &h); err != nil {
+		return nil, fmt.Errorf("failed to parse HAR file: %s: %v", harFile, err)
+	}
+	return &h, nil
+}
+
+// Writer appends entries to a HAR archive as they are recorded. Entries
+// are not held in memory: each is written after the previous one, then
+// the closing brackets of the archive are rewritten, so that the file
+// is valid after each entry.
+type Writer struct {
+	file   *os.File
+	offset int64
+	count  int
+}
+
+// harHeader and harFooter surround the entries of an archive written by a
+// Writer, which are indented to match.
+const (
+	harHeader      = "{\n  \"log\": {\n    \"version\": %s,\n    \"creator\": %s,\n    \"entries\": ["
+	harFooter      = "\n    ]\n  }\n}\n"
+	harEntryIndent = "      "
+)
+
+// NewWriter creates the HAR archive at the given path, without entries.
+func NewWriter(harFile string, version string, creator Creator) (*Writer, error) {
+	versionJson, _ := json.Marshal(version)
+	creatorJson, err := json.MarshalIndent(creator, "    ", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal HAR creator: %v", err)
+	}
+	file, err := os.Create(harFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HAR file: %s: %v", harFile, err)
+	}
+	w := &Writer{file: file}
+	if err := w.write([]byte(fmt.Sprintf(harHeader, versionJson, creatorJson))); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Append writes the entry after the existing entries, replacing the
+// closing brackets of the archive.
+func (w *Writer) Append(entry Entry) error {
+	entryJson, err := json.MarshalIndent(entry, harEntryIndent, "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal HAR entry: %v", err)
+	}
+	separator := "\n" + harEntryIndent
+	if w.count > 0 {
+		separator = "," + separator
+	}
+	if err := w.write(append([]byte(separator), entryJson...)); err != nil {
+		return err
+	}
+	w.count++
+	return nil
+}
+
+// write writes the data at the end of the entries, followed by the footer.
+func (w *Writer) write(data []byte) error {
+	if _, err := w.file.WriteAt(append(data, harFooter...), w.offset); err != nil {
+		return fmt.Errorf("failed to write HAR file: %s: %v", w.file.Name(), err)
+	}
+	w.offset += int64(len(data))
+	return nil
+}
+
+// Close closes the archive, which is already complete.
+func (w *Writer) Close() error {
+	return w.file.Close()
+}
@@ -0,0 +1,31 @@
+package har
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	harFile := filepath.Join(t.TempDir(), "test.har")
+	w, err := NewWriter(harFile, "1.2", Creator{Name: "imposter-cli", Version: "dev"})
+	require.NoError(t, err)
+	defer w.Close()
+
+	archive, err := Parse(harFile)
+	require.NoError(t, err, "archive without entries should be valid")
+	require.Equal(t, "1.2", archive.Log.Version)
+	require.Equal(t, "imposter-cli", archive.Log.Creator.Name)
+	require.Empty(t, archive.Log.Entries)
+
+	for _, url := range []string{"http://example.com/first", "http://example.com/second"} {
+		require.NoError(t, w.Append(Entry{Request: Request{Method: "GET", URL: url}}))
+
+		archive, err = Parse(harFile)
+		require.NoError(t, err, "archive should be valid after each entry")
+	}
+	require.Len(t, archive.Log.Entries, 2)
+	require.Equal(t, "http://example.com/first", archive.Log.Entries[0].Request.URL)
+	require.Equal(t, "http://example.com/second", archive.Log.Entries[1].Request.URL)
+}
@@ -37,12 +37,40 @@ type ConfigGenerationOptions struct {
 	ScriptFileName string
 	SpecFilePath   string
 	WSDLFilePath   string
-	ProtoFilePath  string
+	ProtoFilePaths []string
+}
+
+// ResourceGenerationOptions control the resources generated from a spec.
+type ResourceGenerationOptions struct {
+	ScriptEngine   ScriptEngine
+	ScriptFileName string
+	ForceOverwrite bool
+
+	// StatusTrigger selects the error responses of operations.
+	StatusTrigger StatusTrigger
+
+	// Seed is used to generate response data from schemas,
+	// so the same seed produces the same response files.
+	Seed int64
 }
 
 var logger = logging.GetLogger()
 
+// Create writes configuration for the specs in the directory, without
+// resources for error responses.
 func Create(configDir string, generateResources bool, forceOverwrite bool, scriptEngine ScriptEngine, requireSpecFiles bool) {
+	CreateWithOptions(configDir, generateResources, requireSpecFiles, ResourceGenerationOptions{
+		ScriptEngine:   scriptEngine,
+		ForceOverwrite: forceOverwrite,
+		StatusTrigger:  StatusTriggerNone,
+	})
+}
+
+// CreateWithOptions writes configuration for the specs in the directory,
+// generating resources with the options. The script file name of the
+// options is set for each spec.
+func CreateWithOptions(configDir string, generateResources bool, requireSpecFiles bool, options ResourceGenerationOptions) {
+	scriptEngine, forceOverwrite := options.ScriptEngine, options.ForceOverwrite
 	openApiSpecs := openapi.DiscoverOpenApiSpecs(configDir)
 	wsdlFiles := wsdl.DiscoverWSDLFiles(configDir)
 	protoFiles := protobuf.DiscoverProtoFiles(configDir)
@@ -55,8 +83,8 @@ func Create(configDir string, generateResources bool, forceOverwrite bool, scrip
 		specsFound = true
 		logger.Tracef("using openapi plugin")
 		for _, openApiSpec := range openApiSpecs {
-			scriptFileName := getScriptFileName(openApiSpec, scriptEngine, forceOverwrite)
-			writeOpenapiMockConfig(openApiSpec, generateResources, forceOverwrite, scriptEngine, scriptFileName)
+			options.ScriptFileName = getScriptFileName(openApiSpec, scriptEngine, forceOverwrite)
+			writeOpenapiMockConfig(openApiSpec, generateResources, options)
 		}
 	}
 
@@ -64,8 +92,8 @@ func Create(configDir string, generateResources bool, forceOverwrite bool, scrip
 		specsFound = true
 		logger.Tracef("using soap plugin")
 		for _, wsdlFile := range wsdlFiles {
-			scriptFileName := getScriptFileName(wsdlFile, scriptEngine, forceOverwrite)
-			writeWsdlMockConfig(wsdlFile, generateResources, forceOverwrite, scriptEngine, scriptFileName)
+			options.ScriptFileName = getScriptFileName(wsdlFile, scriptEngine, forceOverwrite)
+			writeWsdlMockConfig(wsdlFile, generateResources, options)
 		}
 	}
 
@@ -74,8 +102,8 @@ func Create(configDir string, generateResources bool, forceOverwrite bool, scrip
 		grpcFound = true
 		logger.Tracef("using grpc plugin")
 		for _, protoFile := range protoFiles {
-			scriptFileName := getScriptFileName(protoFile, scriptEngine, forceOverwrite)
-			writeGrpcMockConfig(protoFile, forceOverwrite, scriptEngine, scriptFileName)
+			options.ScriptFileName = getScriptFileName(protoFile, scriptEngine, forceOverwrite)
+			writeGrpcMockConfig(protoFile, generateResources, options)
 		}
 	}
 
@@ -111,9 +139,13 @@ func GenerateConfig(options ConfigGenerationOptions, resources []Resource) []byt
 	if options.WSDLFilePath != "" {
 		pluginConfig.WSDLFile = filepath.Base(options.WSDLFilePath)
 	}
-	if options.ProtoFilePath != "" {
+	if len(options.ProtoFilePaths) > 0 {
+		var protoFiles []string
+		for _, protoFilePath := range options.ProtoFilePaths {
+			protoFiles = append(protoFiles, filepath.Base(protoFilePath))
+		}
 		pluginConfig.Config = &GrpcPluginConfig{
-			ProtoFiles: []string{filepath.Base(options.ProtoFilePath)},
+			ProtoFiles: protoFiles,
 		}
 	}
 	if len(resources) > 0 {
@@ -190,3 +222,25 @@ func writeMockConfig(configFilePath string, resources []Resource, forceOverwrite
 
 	logger.Infof("wrote Imposter config: %v", configFilePath)
 }
+
+// writeResponseFile writes a response file to a directory named after the
+// anchor file, returning the path of the response file relative to the
+// directory of the anchor file, for use in its configuration.
+func writeResponseFile(anchorFilePath string, fileName string, body []byte, forceOverwrite bool) (string, error) {
+	responseDir := strings.TrimSuffix(anchorFilePath, filepath.Ext(anchorFilePath)) + "-responses"
+	if err := os.MkdirAll(responseDir, 0755); err != nil {
+		return "", err
+	}
+	responseFile := filepath.Join(responseDir, fileName)
+	fileutil.MustNotExist(responseFile, forceOverwrite)
+	if err := os.WriteFile(responseFile, body, 0644); err != nil {
+		return "", err
+	}
+	logger.Debugf("wrote response file: %v", responseFile)
+
+	relFile, err := filepath.Rel(filepath.Dir(anchorFilePath), responseFile)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(relFile), nil
+}
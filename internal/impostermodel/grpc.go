@@ -1,11 +1,69 @@
 package impostermodel
 
-func writeGrpcMockConfig(protoFilePath string, forceOverwrite bool, scriptEngine ScriptEngine, scriptFileName string) {
-	options := ConfigGenerationOptions{
+import (
+	"github.com/imposter-project/imposter-cli/internal/fileutil"
+	"github.com/imposter-project/imposter-cli/internal/protobuf"
+)
+
+func writeGrpcMockConfig(protoFilePath string, generateResources bool, options ResourceGenerationOptions) {
+	var resources []Resource
+	if generateResources {
+		resources = buildGrpcResources(protoFilePath, options)
+	} else {
+		logger.Debug("skipping resource generation")
+	}
+	configOptions := ConfigGenerationOptions{
 		PluginName:     "grpc",
-		ScriptEngine:   scriptEngine,
-		ScriptFileName: scriptFileName,
-		ProtoFilePath:  protoFilePath,
+		ScriptEngine:   options.ScriptEngine,
+		ScriptFileName: options.ScriptFileName,
+		ProtoFilePaths: []string{protoFilePath},
+	}
+	writeMockConfigAdjacent(protoFilePath, resources, options.ForceOverwrite, configOptions)
+}
+
+// buildGrpcResources generates a resource for each method of the services
+// in the proto file, with a response file containing a sample of the
+// output message. Imports are resolved relative to the proto file.
+func buildGrpcResources(protoFilePath string, options ResourceGenerationOptions) []Resource {
+	files, err := protobuf.Parse([]string{protoFilePath})
+	if err != nil {
+		logger.Fatalf("unable to parse proto file: %v: %v", protoFilePath, err)
 	}
-	writeMockConfigAdjacent(protoFilePath, nil, forceOverwrite, options)
+	generator := protobuf.NewSampleGenerator(options.Seed)
+
+	var resources []Resource
+	for _, file := range files {
+		services := file.Services()
+		for i := 0; i < services.Len(); i++ {
+			service := services.Get(i)
+			methods := service.Methods()
+			for j := 0; j < methods.Len(); j++ {
+				method := methods.Get(j)
+				resource := Resource{
+					Path:     "/" + string(service.FullName()) + "/" + string(method.Name()),
+					Method:   "POST",
+					Response: &ResponseConfig{},
+				}
+				if IsScriptEngineEnabled(options.ScriptEngine) {
+					resource.Steps = &[]StepConfig{{Type: StepTypeScript, File: options.ScriptFileName}}
+				}
+
+				body, err := generator.Response(method)
+				if err != nil {
+					logger.Warnf("unable to generate sample response for method %s: %v", method.FullName(), err)
+				} else {
+					fileName := fileutil.SanitiseFileName(string(service.Name())+"-"+string(method.Name()), "") + ".json"
+					relFile, err := writeResponseFile(protoFilePath, fileName, body, options.ForceOverwrite)
+					if err != nil {
+						logger.Fatalf("failed to write response file for method %s: %v", method.FullName(), err)
+					}
+					resource.Response.File = relFile
+				}
+				resources = append(resources, resource)
+			}
+		}
+	}
+
+	logger.Debugf("generated %d resources from proto file", len(resources))
+	return resources
 }
@@ -0,0 +1,53 @@
+package impostermodel
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildGrpcResources(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "common"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "common", "pet.proto"), []byte(`syntax = "proto3";
+package common;
+message Pet {
+  string name = 1;
+}
+`), 0644))
+	protoFile := filepath.Join(dir, "pet_store.proto")
+	require.NoError(t, os.WriteFile(protoFile, []byte(`syntax = "proto3";
+package store;
+import "common/pet.proto";
+service PetStore {
+  rpc GetPet (GetPetRequest) returns (common.Pet);
+  rpc ListPets (GetPetRequest) returns (stream common.Pet);
+}
+message GetPetRequest {
+  string id = 1;
+}
+`), 0644))
+
+	resources := buildGrpcResources(protoFile, ResourceGenerationOptions{Seed: 1})
+	require.Len(t, resources, 2)
+
+	getPet := resources[0]
+	require.Equal(t, "/store.PetStore/GetPet", getPet.Path)
+	require.Equal(t, "POST", getPet.Method)
+	require.Equal(t, "pet_store-responses/PetStore-GetPet.json", getPet.Response.File)
+	petJson, err := os.ReadFile(filepath.Join(dir, getPet.Response.File))
+	require.NoError(t, err)
+	var pet map[string]any
+	require.NoError(t, json.Unmarshal(petJson, &pet))
+	require.NotEmpty(t, pet["name"], "fields of imported messages should be generated")
+
+	listPets := resources[1]
+	require.Equal(t, "/store.PetStore/ListPets", listPets.Path)
+	petsJson, err := os.ReadFile(filepath.Join(dir, listPets.Response.File))
+	require.NoError(t, err)
+	var pets []map[string]any
+	require.NoError(t, json.Unmarshal(petsJson, &pets), "streamed responses should be an array")
+}
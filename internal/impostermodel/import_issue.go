@@ -0,0 +1,39 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package impostermodel
+
+import "fmt"
+
+// ImportIssue is a part of an imported file, such as a WireMock mapping or
+// a Postman collection, that could not be translated, or was translated
+// with a different meaning.
+type ImportIssue struct {
+	Source  string
+	Message string
+}
+
+func (i ImportIssue) String() string {
+	return i.Source + ": " + i.Message
+}
+
+// ImportIssues collects the issues found translating an imported file.
+type ImportIssues []ImportIssue
+
+// Report adds an issue for the source, formatting the message with the args.
+func (i *ImportIssues) Report(source string, format string, args ...any) {
+	*i = append(*i, ImportIssue{Source: source, Message: fmt.Sprintf(format, args...)})
+}
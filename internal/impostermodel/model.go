@@ -40,8 +40,9 @@ type RequestBody struct {
 
 type Resource struct {
 	Path           string             `json:"path,omitempty"`
-	Method         string             `json:"method"`
+	Method         string             `json:"method,omitempty"`
 	Operation      string             `json:"operation,omitempty"`
+	PathParams     *map[string]string `json:"pathParams,omitempty"`
 	QueryParams    *map[string]string `json:"queryParams,omitempty"`
 	RequestBody    *RequestBody       `json:"requestBody,omitempty"`
 	RequestHeaders *map[string]string `json:"requestHeaders,omitempty"`
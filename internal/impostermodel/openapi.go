@@ -17,78 +17,254 @@ limitations under the License.
 package impostermodel
 
 import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/imposter-project/imposter-cli/internal/fileutil"
 	"github.com/imposter-project/imposter-cli/internal/openapi"
 )
 
-type ResourceGenerationOptions struct {
-	ScriptEngine   ScriptEngine
-	ScriptFileName string
-}
+// preferredMediaTypes are chosen for response files, in order,
+// if an operation's response has several media types.
+var preferredMediaTypes = []string{"application/json", "application/xml", "text/plain"}
 
-func writeOpenapiMockConfig(specFilePath string, generateResources bool, forceOverwrite bool, scriptEngine ScriptEngine, scriptFileName string) {
+func writeOpenapiMockConfig(specFilePath string, generateResources bool, options ResourceGenerationOptions) {
+	if dependencies, err := openapi.Dependencies(specFilePath); err == nil && len(dependencies) > 0 {
+		logger.Infof("spec %s references %d other file(s)", filepath.Base(specFilePath), len(dependencies))
+		logger.Debugf("files referenced by spec: %v", dependencies)
+	}
 	var resources []Resource
 	if generateResources {
-		resources = buildOpenapiResources(specFilePath, scriptEngine, scriptFileName)
+		resources = GenerateResourcesFromSpec(specFilePath, options)
+		logger.Debugf("generated %d resources from spec", len(resources))
 	} else {
 		logger.Debug("skipping resource generation")
 	}
-	options := ConfigGenerationOptions{
+	configOptions := ConfigGenerationOptions{
 		PluginName:     "openapi",
-		ScriptEngine:   scriptEngine,
-		ScriptFileName: scriptFileName,
+		ScriptEngine:   options.ScriptEngine,
+		ScriptFileName: options.ScriptFileName,
 		SpecFilePath:   specFilePath,
 	}
-	writeMockConfigAdjacent(specFilePath, resources, forceOverwrite, options)
-}
-
-func buildOpenapiResources(specFilePath string, scriptEngine ScriptEngine, scriptFileName string) []Resource {
-	resources := GenerateResourcesFromSpec(specFilePath, ResourceGenerationOptions{
-		ScriptEngine:   scriptEngine,
-		ScriptFileName: scriptFileName,
-	})
-	logger.Debugf("generated %d resources from spec", len(resources))
-	return resources
+	writeMockConfigAdjacent(specFilePath, resources, options.ForceOverwrite, configOptions)
 }
 
+// GenerateResourcesFromSpec generates a resource for each operation in the
+// spec. Required parameters with examples become request matchers, and the
+// example for the response of the operation, or data generated from its
+// schema, is written to a response file next to the spec.
+//
+// If a status trigger is set, a resource is also generated for each error
+// response of the operation, matched by the header or query parameter
+// for the trigger in addition to the matchers for the operation.
 func GenerateResourcesFromSpec(specFilePath string, options ResourceGenerationOptions) []Resource {
 	var resources []Resource
-	partialSpec, err := openapi.Parse(specFilePath)
+	spec, err := openapi.Parse(specFilePath)
 	if err != nil {
 		logger.Fatalf("unable to parse openapi spec: %v: %v", specFilePath, err)
 	}
-	if partialSpec != nil {
-		for path, pathDetail := range partialSpec.Paths {
-			for verb, resp := range pathDetail {
-				resource := Resource{
-					Path:   path,
-					Method: strings.ToUpper(verb),
-					Response: &ResponseConfig{
-						StatusCode: chooseOpStatusCode(resp),
-					},
-				}
-				if IsScriptEngineEnabled(options.ScriptEngine) {
-					resource.Steps = &[]StepConfig{{Type: StepTypeScript, File: options.ScriptFileName}}
-				}
-				resources = append(resources, resource)
+	generator := openapi.NewFakeDataGenerator(options.Seed)
+	for _, path := range spec.SortedPaths() {
+		for _, verb := range spec.Paths.SortedMethods(path) {
+			op := spec.Paths[path][verb]
+			resource := buildOperationResource(specFilePath, path, verb, op, chooseOpStatusCode(op), generator, options)
+			resources = append(resources, resource)
+
+			if options.StatusTrigger == "" || options.StatusTrigger == StatusTriggerNone {
+				continue
+			}
+			for _, statusCode := range errorStatusCodes(op, resource.Response.StatusCode) {
+				errorResource := buildOperationResource(specFilePath, path, verb, op, statusCode, generator, options)
+				addStatusTrigger(&errorResource, options.StatusTrigger, statusCode)
+				resources = append(resources, errorResource)
 			}
 		}
-
 	}
 	return resources
 }
 
-func chooseOpStatusCode(resp openapi.Operation) int {
+// buildOperationResource builds a resource for the operation that
+// responds with the status code, writing the response file if
+// there is an example for the response or it has a schema.
+func buildOperationResource(
+	specFilePath string,
+	path string,
+	verb string,
+	op *openapi.Operation,
+	statusCode int,
+	generator *openapi.FakeDataGenerator,
+	options ResourceGenerationOptions,
+) Resource {
+	resource := Resource{
+		Path:   path,
+		Method: strings.ToUpper(verb),
+		Response: &ResponseConfig{
+			StatusCode: statusCode,
+		},
+	}
+	addParameterMatchers(&resource, op.Parameters)
+
+	fileName := op.OperationId
+	if fileName == "" {
+		fileName = strings.ToLower(resource.Method) + resource.Path
+	}
+	fileName = fileutil.SanitiseFileName(fileName, "")
+	if statusCode >= 400 {
+		fileName += "-" + strconv.Itoa(statusCode)
+	}
+	if err := addResponseFile(specFilePath, &resource, op, fileName, generator, options.ForceOverwrite); err != nil {
+		logger.Fatalf("unable to write response file for %s %s: %v", resource.Method, path, err)
+	}
+	if IsScriptEngineEnabled(options.ScriptEngine) {
+		resource.Steps = &[]StepConfig{{Type: StepTypeScript, File: options.ScriptFileName}}
+	}
+	return resource
+}
+
+// addParameterMatchers adds a matcher for each required path, query or
+// header parameter that has a scalar example value.
+func addParameterMatchers(resource *Resource, params []openapi.Parameter) {
+	for _, param := range params {
+		if !param.Required {
+			continue
+		}
+		example, ok := param.ExampleValue()
+		if !ok {
+			continue
+		}
+		value, ok := scalarString(example)
+		if !ok {
+			continue
+		}
+		var matchers **map[string]string
+		switch param.In {
+		case "path":
+			matchers = &resource.PathParams
+		case "query":
+			matchers = &resource.QueryParams
+		case "header":
+			matchers = &resource.RequestHeaders
+		default:
+			continue
+		}
+		if *matchers == nil {
+			*matchers = &map[string]string{}
+		}
+		(**matchers)[param.Name] = value
+	}
+}
+
+func scalarString(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case json.Number, bool, float64, int:
+		return fmt.Sprint(v), true
+	default:
+		return "", false
+	}
+}
+
+// addResponseFile writes the example for the response of the operation
+// with the status code of the resource to a file, and sets the file and
+// content type of the resource's response. If there is no example, one is
+// generated from the schema. If there is neither, the response is unchanged.
+func addResponseFile(specFilePath string, resource *Resource, op *openapi.Operation, fileName string, generator *openapi.FakeDataGenerator, forceOverwrite bool) error {
+	var response *openapi.Response
+	code := strconv.Itoa(resource.Response.StatusCode)
+	for _, key := range []string{code, code[:1] + "XX", code[:1] + "xx", "default"} {
+		if response = op.Responses[key]; response != nil {
+			break
+		}
+	}
+	if response == nil || len(response.Content) == 0 {
+		return nil
+	}
+	mediaTypeName := chooseMediaType(response.Content)
+	mediaType := response.Content[mediaTypeName]
+	example, ok := mediaType.ExampleValue()
+	if !ok {
+		if mediaType.Schema == nil {
+			logger.Debugf("no example or schema for response of %s %s", resource.Method, resource.Path)
+			return nil
+		}
+		example = generator.Generate(mediaType.Schema)
+	}
+	body, ok := formatExample(example, mediaTypeName)
+	if !ok {
+		logger.Debugf("unable to write %s example for response of %s %s", mediaTypeName, resource.Method, resource.Path)
+		return nil
+	}
+
+	relFile, err := writeResponseFile(specFilePath, fileName+mediaTypeExtension(mediaTypeName), body, forceOverwrite)
+	if err != nil {
+		return err
+	}
+	resource.Response.File = relFile
+	resource.Response.Headers = &map[string]string{"Content-Type": mediaTypeName}
+	return nil
+}
+
+// chooseMediaType returns the first preferred media type in the content,
+// then any JSON media type, otherwise the first in lexical order.
+func chooseMediaType(content map[string]openapi.MediaType) string {
+	for _, preferred := range preferredMediaTypes {
+		if _, ok := content[preferred]; ok {
+			return preferred
+		}
+	}
+	var mediaTypes []string
+	for mediaType := range content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+	for _, mediaType := range mediaTypes {
+		if isJsonMediaType(mediaType) {
+			return mediaType
+		}
+	}
+	return mediaTypes[0]
+}
+
+func isJsonMediaType(mediaType string) bool {
+	return strings.Contains(mediaType, "json")
+}
+
+// formatExample returns the example as a JSON document for JSON media
+// types, otherwise as text, which requires the example to be a string.
+func formatExample(example any, mediaType string) ([]byte, bool) {
+	if isJsonMediaType(mediaType) {
+		body, err := json.MarshalIndent(example, "", "  ")
+		if err != nil {
+			return nil, false
+		}
+		return append(body, '\n'), true
+	}
+	if text, ok := example.(string); ok {
+		return []byte(text), true
+	}
+	return nil, false
+}
+
+func mediaTypeExtension(mediaType string) string {
+	if extension := fileutil.ExtensionForMediaType(mediaType); extension != "" {
+		return extension
+	}
+	return ".txt"
+}
+
+func chooseOpStatusCode(resp *openapi.Operation) int {
 	if len(resp.Responses) == 0 {
 		logger.Tracef("no responses found for openapi operation - guessing 200 status code")
 		return 200
 	}
 	var statusCodes []int
 	for statusCode := range resp.Responses {
-		if sc, err := strconv.Atoi(statusCode); err == nil && sc >= 200 {
+		if sc, ok := parseStatusCode(statusCode); ok && sc >= 200 {
 			statusCodes = append(statusCodes, sc)
 		}
 	}
@@ -100,3 +276,30 @@ func chooseOpStatusCode(resp openapi.Operation) int {
 	logger.Tracef("unable to determine status code found for openapi operation - guessing 200")
 	return 200
 }
+
+// parseStatusCode parses the key of a response. A range
+// such as 4XX is represented by its first code.
+func parseStatusCode(key string) (int, bool) {
+	if len(key) == 3 && strings.HasSuffix(strings.ToUpper(key), "XX") {
+		key = key[:1] + "00"
+	}
+	code, err := strconv.Atoi(key)
+	return code, err == nil
+}
+
+// errorStatusCodes returns the 4xx and 5xx status codes documented for
+// the operation, in order, other than the status code of its resource.
+func errorStatusCodes(op *openapi.Operation, exclude int) []int {
+	codes := make(map[int]bool)
+	for key := range op.Responses {
+		if code, ok := parseStatusCode(key); ok && code >= 400 && code < 600 && code != exclude {
+			codes[code] = true
+		}
+	}
+	var statusCodes []int
+	for code := range codes {
+		statusCodes = append(statusCodes, code)
+	}
+	sort.Ints(statusCodes)
+	return statusCodes
+}
@@ -0,0 +1,210 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package impostermodel
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const petStoreSpec = `
+openapi: "3.0.1"
+info:
+  title: Pet store
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      parameters:
+        - name: limit
+          in: query
+          required: true
+          schema:
+            type: integer
+          example: 10
+        - name: X-Api-Version
+          in: header
+          required: true
+          schema:
+            type: string
+            example: "2"
+        - name: offset
+          in: query
+          schema:
+            type: integer
+          example: 5
+      responses:
+        "200":
+          description: pets
+          content:
+            application/xml:
+              example: "<pets/>"
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: "#/components/schemas/Pet"
+  /pets/{petId}:
+    get:
+      parameters:
+        - name: petId
+          in: path
+          required: true
+          schema:
+            type: string
+          examples:
+            fluffy:
+              value: "1"
+      responses:
+        2XX:
+          description: a pet
+          content:
+            application/vnd.pet+json:
+              examples:
+                fluffy:
+                  value:
+                    name: Fluffy
+        "404":
+          description: not found
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  message:
+                    type: string
+                    example: pet not found
+        5XX:
+          description: server error
+    delete:
+      parameters:
+        - name: petId
+          in: path
+          required: true
+          schema:
+            type: string
+      responses:
+        "204":
+          description: deleted
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name:
+          type: string
+          example: Rex
+        status:
+          type: string
+          enum: [available, sold]
+`
+
+func TestGenerateResourcesFromSpec(t *testing.T) {
+	dir := t.TempDir()
+	specFile := filepath.Join(dir, "pet_store.yaml")
+	require.NoError(t, os.WriteFile(specFile, []byte(petStoreSpec), 0644))
+
+	resources := GenerateResourcesFromSpec(specFile, ResourceGenerationOptions{Seed: 1})
+	require.Len(t, resources, 3)
+
+	list := resources[0]
+	require.Equal(t, "/pets", list.Path)
+	require.Equal(t, "GET", list.Method)
+	require.Equal(t, map[string]string{"limit": "10"}, *list.QueryParams, "only required parameters should be matched")
+	require.Equal(t, map[string]string{"X-Api-Version": "2"}, *list.RequestHeaders)
+	require.Nil(t, list.PathParams)
+	require.Equal(t, 200, list.Response.StatusCode)
+	require.Equal(t, "pet_store-responses/listPets.json", list.Response.File)
+	require.Equal(t, map[string]string{"Content-Type": "application/json"}, *list.Response.Headers)
+	listJson, err := os.ReadFile(filepath.Join(dir, list.Response.File))
+	require.NoError(t, err)
+	var pets []map[string]any
+	require.NoError(t, json.Unmarshal(listJson, &pets))
+	require.NotEmpty(t, pets, "data should be generated from the schema")
+	for _, pet := range pets {
+		require.Equal(t, "Rex", pet["name"], "schema examples should be used")
+		require.Contains(t, []any{"available", "sold"}, pet["status"])
+	}
+
+	deletePet := resources[1]
+	require.Equal(t, "DELETE", deletePet.Method)
+	require.Nil(t, deletePet.PathParams, "parameters without examples should not be matched")
+	require.Equal(t, 204, deletePet.Response.StatusCode)
+	require.Empty(t, deletePet.Response.File)
+
+	getPet := resources[2]
+	require.Equal(t, map[string]string{"petId": "1"}, *getPet.PathParams)
+	require.Equal(t, "pet_store-responses/get-pets-petId.json", getPet.Response.File)
+	require.Equal(t, map[string]string{"Content-Type": "application/vnd.pet+json"}, *getPet.Response.Headers)
+	petJson, err := os.ReadFile(filepath.Join(dir, getPet.Response.File))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":"Fluffy"}`, string(petJson))
+}
+
+func TestGenerateResourcesFromSpec_seed(t *testing.T) {
+	generate := func(seed int64) string {
+		dir := t.TempDir()
+		specFile := filepath.Join(dir, "pet_store.yaml")
+		require.NoError(t, os.WriteFile(specFile, []byte(petStoreSpec), 0644))
+		resources := GenerateResourcesFromSpec(specFile, ResourceGenerationOptions{Seed: seed})
+		body, err := os.ReadFile(filepath.Join(dir, resources[0].Response.File))
+		require.NoError(t, err)
+		return string(body)
+	}
+	require.Equal(t, generate(42), generate(42), "the same seed should generate the same response files")
+}
+
+func TestGenerateResourcesFromSpec_statusTrigger(t *testing.T) {
+	dir := t.TempDir()
+	specFile := filepath.Join(dir, "pet_store.yaml")
+	require.NoError(t, os.WriteFile(specFile, []byte(petStoreSpec), 0644))
+
+	resources := GenerateResourcesFromSpec(specFile, ResourceGenerationOptions{StatusTrigger: StatusTriggerHeader})
+	require.Len(t, resources, 5)
+
+	getPet := resources[2]
+	require.Equal(t, 200, getPet.Response.StatusCode)
+	require.Nil(t, getPet.RequestHeaders)
+
+	notFound := resources[3]
+	require.Equal(t, "GET", notFound.Method)
+	require.Equal(t, "/pets/{petId}", notFound.Path)
+	require.Equal(t, 404, notFound.Response.StatusCode)
+	require.Equal(t, map[string]string{"X-Mock-Status": "404"}, *notFound.RequestHeaders)
+	require.Equal(t, map[string]string{"petId": "1"}, *notFound.PathParams, "operation matchers should be retained")
+	require.Equal(t, "pet_store-responses/get-pets-petId-404.json", notFound.Response.File)
+	notFoundJson, err := os.ReadFile(filepath.Join(dir, notFound.Response.File))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"message":"pet not found"}`, string(notFoundJson))
+
+	serverError := resources[4]
+	require.Equal(t, 500, serverError.Response.StatusCode, "ranges should use their first status code")
+	require.Equal(t, map[string]string{"X-Mock-Status": "500"}, *serverError.RequestHeaders)
+	require.Empty(t, serverError.Response.File)
+
+	queryResources := GenerateResourcesFromSpec(specFile, ResourceGenerationOptions{
+		StatusTrigger:  StatusTriggerQuery,
+		ForceOverwrite: true,
+	})
+	require.Equal(t, map[string]string{"mockStatus": "404"}, *queryResources[3].QueryParams)
+	require.Nil(t, queryResources[3].RequestHeaders)
+}
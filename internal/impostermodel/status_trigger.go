@@ -0,0 +1,71 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package impostermodel
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// StatusTrigger is how a request selects one of the error
+// responses documented for an operation.
+type StatusTrigger string
+
+const (
+	StatusTriggerNone   StatusTrigger = "none"
+	StatusTriggerHeader StatusTrigger = "header"
+	StatusTriggerQuery  StatusTrigger = "query"
+)
+
+const (
+	// StatusTriggerHeaderName is the request header that selects a response status.
+	StatusTriggerHeaderName = "X-Mock-Status"
+
+	// StatusTriggerQueryName is the query parameter that selects a response status.
+	StatusTriggerQueryName = "mockStatus"
+)
+
+func ParseStatusTrigger(statusTrigger string) StatusTrigger {
+	trigger := StatusTrigger(statusTrigger)
+	switch trigger {
+	case StatusTriggerNone, StatusTriggerHeader, StatusTriggerQuery:
+		return trigger
+	case "":
+		return StatusTriggerNone
+	default:
+		panic(fmt.Errorf("unsupported status trigger: %v", statusTrigger))
+	}
+}
+
+// addStatusTrigger adds a matcher to the resource for the request
+// header or query parameter that selects the status code.
+func addStatusTrigger(resource *Resource, trigger StatusTrigger, statusCode int) {
+	var matchers **map[string]string
+	var name string
+	switch trigger {
+	case StatusTriggerHeader:
+		matchers, name = &resource.RequestHeaders, StatusTriggerHeaderName
+	case StatusTriggerQuery:
+		matchers, name = &resource.QueryParams, StatusTriggerQueryName
+	default:
+		return
+	}
+	if *matchers == nil {
+		*matchers = &map[string]string{}
+	}
+	(**matchers)[name] = strconv.Itoa(statusCode)
+}
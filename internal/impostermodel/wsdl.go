@@ -1,46 +1,107 @@
 package impostermodel
 
 import (
+	"sort"
+
+	"github.com/imposter-project/imposter-cli/internal/fileutil"
+	"github.com/imposter-project/imposter-cli/internal/wsdl"
 	wsdlparser "github.com/outofcoffee/go-wsdl-parser"
 )
 
-func writeWsdlMockConfig(wsdlFilePath string, generateResources bool, forceOverwrite bool, scriptEngine ScriptEngine, scriptFileName string) {
+// soapFaultStatusCode is the status of a response containing a SOAP fault.
+const soapFaultStatusCode = 500
+
+func writeWsdlMockConfig(wsdlFilePath string, generateResources bool, options ResourceGenerationOptions) {
 	var resources []Resource
 	if generateResources {
-		resources = buildWsdlResources(wsdlFilePath, scriptEngine, scriptFileName)
+		resources = buildWsdlResources(wsdlFilePath, options)
 	} else {
 		logger.Debug("skipping resource generation")
 	}
-	options := ConfigGenerationOptions{
+	configOptions := ConfigGenerationOptions{
 		PluginName:     "soap",
-		ScriptEngine:   scriptEngine,
-		ScriptFileName: scriptFileName,
+		ScriptEngine:   options.ScriptEngine,
+		ScriptFileName: options.ScriptFileName,
 		WSDLFilePath:   wsdlFilePath,
 	}
-	writeMockConfigAdjacent(wsdlFilePath, resources, forceOverwrite, options)
+	writeMockConfigAdjacent(wsdlFilePath, resources, options.ForceOverwrite, configOptions)
 }
 
-func buildWsdlResources(wsdlFilePath string, scriptEngine ScriptEngine, scriptFileName string) []Resource {
+func buildWsdlResources(wsdlFilePath string, options ResourceGenerationOptions) []Resource {
 	parser, err := wsdlparser.NewWSDLParser(wsdlFilePath)
 	if err != nil {
 		logger.Fatalf("unable to parse WSDL file: %v: %v", wsdlFilePath, err)
 	}
+	generator, err := wsdl.NewSampleGenerator(parser, options.Seed)
+	if err != nil {
+		logger.Fatalf("unable to read schemas of WSDL file: %v: %v", wsdlFilePath, err)
+	}
+
+	// operations are visited in order, so the same seed produces the same samples
+	operations := parser.GetOperations()
+	opNames := make([]string, 0, len(operations))
+	for name := range operations {
+		opNames = append(opNames, name)
+	}
+	sort.Strings(opNames)
 
 	var resources []Resource
-	for _, op := range parser.GetOperations() {
-		resource := Resource{
-			Method:    "POST",
-			Operation: op.Name,
-			Response: &ResponseConfig{
-				StatusCode: 200,
-			},
-		}
-		if IsScriptEngineEnabled(scriptEngine) {
-			resource.Steps = &[]StepConfig{{Type: StepTypeScript, File: scriptFileName}}
+	for _, opName := range opNames {
+		op := operations[opName]
+		fileName := fileutil.SanitiseFileName(op.Name, "")
+		contentType := generator.SoapVersion(op).ContentType()
+
+		resource := buildWsdlResource(op, 200, options.ScriptEngine, options.ScriptFileName)
+		if body, err := generator.ResponseEnvelope(op); err != nil {
+			logger.Warnf("unable to generate sample response for operation %s: %v", op.Name, err)
+		} else if err := addEnvelopeFile(wsdlFilePath, &resource, fileName+".xml", contentType, body, options.ForceOverwrite); err != nil {
+			logger.Fatalf("failed to write response file for operation %s: %v", op.Name, err)
 		}
 		resources = append(resources, resource)
+
+		if options.StatusTrigger == StatusTriggerNone {
+			continue
+		}
+		body, ok, err := generator.FaultEnvelope(op)
+		if err != nil {
+			logger.Warnf("unable to generate sample fault for operation %s: %v", op.Name, err)
+			continue
+		} else if !ok {
+			continue
+		}
+		faultResource := buildWsdlResource(op, soapFaultStatusCode, options.ScriptEngine, options.ScriptFileName)
+		if err := addEnvelopeFile(wsdlFilePath, &faultResource, fileName+"-fault.xml", contentType, body, options.ForceOverwrite); err != nil {
+			logger.Fatalf("failed to write fault file for operation %s: %v", op.Name, err)
+		}
+		addStatusTrigger(&faultResource, options.StatusTrigger, soapFaultStatusCode)
+		resources = append(resources, faultResource)
 	}
 
 	logger.Debugf("generated %d resources from WSDL", len(resources))
 	return resources
 }
+
+func buildWsdlResource(op *wsdlparser.Operation, statusCode int, scriptEngine ScriptEngine, scriptFileName string) Resource {
+	resource := Resource{
+		Method:    "POST",
+		Operation: op.Name,
+		Response: &ResponseConfig{
+			StatusCode: statusCode,
+		},
+	}
+	if IsScriptEngineEnabled(scriptEngine) {
+		resource.Steps = &[]StepConfig{{Type: StepTypeScript, File: scriptFileName}}
+	}
+	return resource
+}
+
+// addEnvelopeFile writes the envelope to a response file for the resource.
+func addEnvelopeFile(wsdlFilePath string, resource *Resource, fileName string, contentType string, body []byte, forceOverwrite bool) error {
+	relFile, err := writeResponseFile(wsdlFilePath, fileName, body, forceOverwrite)
+	if err != nil {
+		return err
+	}
+	resource.Response.File = relFile
+	resource.Response.Headers = &map[string]string{"Content-Type": contentType}
+	return nil
+}
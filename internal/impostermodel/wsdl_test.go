@@ -0,0 +1,46 @@
+package impostermodel
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/imposter-project/imposter-cli/internal/fileutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildWsdlResources(t *testing.T) {
+	wsdlFile := filepath.Join(t.TempDir(), "pet_service.wsdl")
+	require.NoError(t, fileutil.CopyFile(filepath.Join("testdata", "pet_service_fault.wsdl"), wsdlFile))
+
+	resources := buildWsdlResources(wsdlFile, ResourceGenerationOptions{Seed: 1, StatusTrigger: StatusTriggerHeader})
+	require.Len(t, resources, 2)
+
+	response := resources[0]
+	require.Equal(t, "getPetById", response.Operation)
+	require.Equal(t, 200, response.Response.StatusCode)
+	require.Equal(t, "pet_service-responses/getPetById.xml", response.Response.File)
+	require.Equal(t, map[string]string{"Content-Type": "text/xml"}, *response.Response.Headers)
+	require.Nil(t, response.RequestHeaders)
+	envelope, err := os.ReadFile(filepath.Join(filepath.Dir(wsdlFile), response.Response.File))
+	require.NoError(t, err)
+	require.Contains(t, string(envelope), `<getPetByIdResponse xmlns="urn:com:example:petstore">`)
+	require.Contains(t, string(envelope), `<name xmlns="">`, "local elements should be unqualified by default")
+
+	fault := resources[1]
+	require.Equal(t, "getPetById", fault.Operation)
+	require.Equal(t, 500, fault.Response.StatusCode)
+	require.Equal(t, "pet_service-responses/getPetById-fault.xml", fault.Response.File)
+	require.Equal(t, map[string]string{StatusTriggerHeaderName: "500"}, *fault.RequestHeaders)
+	envelope, err = os.ReadFile(filepath.Join(filepath.Dir(wsdlFile), fault.Response.File))
+	require.NoError(t, err)
+	require.Contains(t, string(envelope), `<faultcode>soap:Server</faultcode>`)
+}
+
+func TestBuildWsdlResources_noStatusTrigger(t *testing.T) {
+	wsdlFile := filepath.Join(t.TempDir(), "pet_service.wsdl")
+	require.NoError(t, fileutil.CopyFile(filepath.Join("testdata", "pet_service_fault.wsdl"), wsdlFile))
+
+	resources := buildWsdlResources(wsdlFile, ResourceGenerationOptions{Seed: 1, StatusTrigger: StatusTriggerNone})
+	require.Len(t, resources, 1, "faults should only be generated with a status trigger")
+}
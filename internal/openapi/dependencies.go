@@ -0,0 +1,102 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Dependencies returns the absolute paths of the files referenced by the
+// spec, directly or through other referenced files, such as the fragments
+// of a spec split into paths/*.yaml and components/*.yaml files.
+func Dependencies(specFile string) ([]string, error) {
+	absFile, err := filepath.Abs(specFile)
+	if err != nil {
+		return nil, err
+	}
+	r := newRefResolver()
+	pending := []string{absFile}
+	for len(pending) > 0 {
+		file := pending[0]
+		pending = pending[1:]
+		if _, loaded := r.documents[file]; loaded {
+			continue
+		}
+		doc, err := r.load(file)
+		if err != nil {
+			if file == absFile {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to load reference in %s: %v", specFile, err)
+		}
+		refFiles, err := referencedFiles(doc, file)
+		if err != nil {
+			return nil, err
+		}
+		pending = append(pending, refFiles...)
+	}
+	return r.files[1:], nil
+}
+
+// referencedFiles returns the absolute paths of the files
+// referenced by the $ref values within the document.
+func referencedFiles(value any, file string) ([]string, error) {
+	var refFiles []string
+	switch v := value.(type) {
+	case map[string]any:
+		if ref, ok := v["$ref"].(string); ok {
+			refFile, _, _ := strings.Cut(ref, "#")
+			if refFile != "" && !strings.Contains(refFile, "://") {
+				refFile, err := url.PathUnescape(refFile)
+				if err != nil {
+					return nil, fmt.Errorf("invalid reference %s in %s: %v", ref, file, err)
+				}
+				target := filepath.FromSlash(refFile)
+				if !filepath.IsAbs(target) {
+					target = filepath.Join(filepath.Dir(file), target)
+				}
+				refFiles = append(refFiles, target)
+			}
+		}
+		// members are visited in order, so dependencies are listed consistently
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			memberFiles, err := referencedFiles(v[key], file)
+			if err != nil {
+				return nil, err
+			}
+			refFiles = append(refFiles, memberFiles...)
+		}
+	case []any:
+		for _, item := range v {
+			itemFiles, err := referencedFiles(item, file)
+			if err != nil {
+				return nil, err
+			}
+			refFiles = append(refFiles, itemFiles...)
+		}
+	}
+	return refFiles, nil
+}
@@ -31,11 +31,13 @@ var logger = logging.GetLogger()
 // DiscoverOpenApiSpecs finds JSON and YAML OpenAPI specification files
 // within the given directory. It returns fully qualified paths
 // to the files discovered.
+//
+// Only root specs are returned, so a spec that is referenced by
+// another, such as part of a multi-file spec, is not included.
 func DiscoverOpenApiSpecs(configDir string) []string {
-	var openApiSpecs []string
+	var candidates []string
 
-	candidates := fileutil.FindFilesWithExtension(configDir, ".yaml", ".yml", ".json")
-	for _, candidate := range candidates {
+	for _, candidate := range fileutil.FindFilesWithExtension(configDir, ".yaml", ".yml", ".json") {
 		fullyQualifiedPath := filepath.Join(configDir, candidate)
 
 		var jsonContent []byte
@@ -49,13 +51,57 @@ func DiscoverOpenApiSpecs(configDir string) []string {
 			logger.Fatal(err)
 		}
 		if isOpenApiSpec(jsonContent) {
-			openApiSpecs = append(openApiSpecs, fullyQualifiedPath)
+			candidates = append(candidates, fullyQualifiedPath)
 		}
 	}
 
+	referenced := make(map[string]bool)
+	for _, candidate := range candidates {
+		for _, dependency := range specDependencies(candidate) {
+			referenced[dependency] = true
+		}
+	}
+	var openApiSpecs []string
+	for _, candidate := range candidates {
+		if absPath, _ := filepath.Abs(candidate); referenced[absPath] {
+			logger.Debugf("skipping spec referenced by another spec: %s", candidate)
+			continue
+		}
+		openApiSpecs = append(openApiSpecs, candidate)
+	}
 	return openApiSpecs
 }
 
+// DiscoverDependencies returns the absolute paths of the files referenced
+// by the root OpenAPI specs within the given directory, such as fragments
+// in subdirectories, that must accompany the specs when they are bundled
+// or deployed.
+func DiscoverDependencies(configDir string) []string {
+	var dependencies []string
+	seen := make(map[string]bool)
+	for _, spec := range DiscoverOpenApiSpecs(configDir) {
+		for _, dependency := range specDependencies(spec) {
+			if !seen[dependency] {
+				seen[dependency] = true
+				dependencies = append(dependencies, dependency)
+			}
+		}
+	}
+	return dependencies
+}
+
+// specDependencies returns the dependencies of the spec, logging
+// rather than failing if they cannot be determined, as the spec
+// may still be usable by the engine.
+func specDependencies(specFile string) []string {
+	dependencies, err := Dependencies(specFile)
+	if err != nil {
+		logger.Warnf("unable to determine files referenced by spec: %v", err)
+		return nil
+	}
+	return dependencies
+}
+
 func loadYamlAsJson(yamlFile string) ([]byte, error) {
 	y, err := os.ReadFile(yamlFile)
 	if err != nil {
@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"sort"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestDiscoverOpenApiSpecs(t *testing.T) {
@@ -100,6 +102,65 @@ info:
 	}
 }
 
+func TestDiscoverOpenApiSpecs_multiFile(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"api.yaml": `
+openapi: 3.0.0
+info:
+  title: Split API
+  version: 1.0.0
+paths:
+  /pets:
+    $ref: "pets.yaml#/paths/~1pets"
+`,
+		// a partial spec, referenced by the root spec
+		"pets.yaml": `
+openapi: 3.0.0
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: pets
+          content:
+            application/json:
+              schema:
+                $ref: "components/schemas.yaml#/Pet"
+`,
+		"components/schemas.yaml": `
+Pet:
+  type: object
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	specs := DiscoverOpenApiSpecs(tempDir)
+	require.Equal(t, []string{filepath.Join(tempDir, "api.yaml")}, specs, "referenced specs should not be treated as root specs")
+
+	require.Equal(t, []string{
+		filepath.Join(tempDir, "pets.yaml"),
+		filepath.Join(tempDir, "components", "schemas.yaml"),
+	}, DiscoverDependencies(tempDir))
+}
+
+func TestDependencies(t *testing.T) {
+	dependencies, err := Dependencies("testdata/pet_store_refs.yaml")
+	require.NoError(t, err)
+	absFile, _ := filepath.Abs("testdata/common/schemas.yaml")
+	require.Equal(t, []string{absFile}, dependencies)
+
+	_, err = Dependencies("testdata/missing.yaml")
+	require.Error(t, err)
+}
+
 func TestLoadYamlAsJson(t *testing.T) {
 	// Create temporary test file
 	tempFile, err := os.CreateTemp("", "yaml_test_*.yaml")
@@ -0,0 +1,437 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is an OpenAPI 3 specification that can be modified, such as
+// by adding examples, while retaining the order of its members and any
+// comments when it is saved.
+type Document struct {
+	root *yaml.Node
+	json bool
+}
+
+// OperationMatch identifies the operation in a Document that a request matches.
+type OperationMatch struct {
+	// PathTemplate is the key of the path item, such as /pets/{petId}.
+	PathTemplate string
+
+	// Method is the lower case HTTP method of the operation.
+	Method string
+
+	// PathParams holds the value of each parameter in the path template.
+	PathParams map[string]string
+}
+
+// LoadDocument reads a JSON or YAML OpenAPI 3 specification.
+func LoadDocument(specFile string) (*Document, error) {
+	raw, err := os.ReadFile(specFile)
+	if err != nil {
+		return nil, err
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(raw, &root); err != nil {
+		return nil, fmt.Errorf("error parsing spec %s: %v", specFile, err)
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("spec %s is not an object", specFile)
+	}
+	doc := &Document{
+		root: root.Content[0],
+		json: filepath.Ext(specFile) == ".json",
+	}
+	if valueOf(doc.root, "swagger") != nil {
+		return nil, fmt.Errorf("spec %s is a Swagger 2 specification, which does not describe response content to add examples to - convert it to OpenAPI 3", specFile)
+	}
+	if version := valueOf(doc.root, "openapi"); version == nil || !strings.HasPrefix(version.Value, "3.") {
+		return nil, fmt.Errorf("spec %s is not an OpenAPI 3 specification", specFile)
+	}
+	return doc, nil
+}
+
+// BasePath returns the path of the first server URL, without a trailing
+// slash, which prefixes the paths in the specification.
+func (d *Document) BasePath() string {
+	servers := valueOf(d.root, "servers")
+	if servers == nil || servers.Kind != yaml.SequenceNode || len(servers.Content) == 0 {
+		return ""
+	}
+	serverUrl := valueOf(servers.Content[0], "url")
+	if serverUrl == nil {
+		return ""
+	}
+	return serverBasePath(serverUrl.Value)
+}
+
+// FindOperation returns the operation matching the method and request path,
+// which includes any base path. Where the path matches more than one path
+// template, the one with the most literal segments is chosen, so /pets/mine
+// is preferred over /pets/{petId}.
+func (d *Document) FindOperation(method string, reqPath string) *OperationMatch {
+	basePath := d.BasePath()
+	if !strings.HasPrefix(reqPath, basePath) {
+		return nil
+	}
+	reqPath = strings.TrimPrefix(reqPath, basePath)
+	if reqPath == "" {
+		reqPath = "/"
+	}
+	paths := valueOf(d.root, "paths")
+	if paths == nil {
+		return nil
+	}
+	method = strings.ToLower(method)
+
+	var best *OperationMatch
+	bestScore := -1
+	for i := 0; i+1 < len(paths.Content); i += 2 {
+		template := paths.Content[i].Value
+		if valueOf(paths.Content[i+1], method) == nil {
+			continue
+		}
+		params, score, ok := matchPathTemplate(template, reqPath)
+		if ok && score > bestScore {
+			best = &OperationMatch{PathTemplate: template, Method: method, PathParams: params}
+			bestScore = score
+		}
+	}
+	return best
+}
+
+// matchPathTemplate matches the request path against an OpenAPI path
+// template, returning the parameter values and the number of literal
+// segments that matched.
+func matchPathTemplate(template string, reqPath string) (map[string]string, int, bool) {
+	templateSegments := strings.Split(strings.Trim(template, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(reqPath, "/"), "/")
+	if len(templateSegments) != len(pathSegments) {
+		return nil, 0, false
+	}
+	params := make(map[string]string)
+	literals := 0
+	for i, segment := range templateSegments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if pathSegments[i] == "" {
+				return nil, 0, false
+			}
+			value, err := url.PathUnescape(pathSegments[i])
+			if err != nil {
+				value = pathSegments[i]
+			}
+			params[segment[1:len(segment)-1]] = value
+		} else if segment == pathSegments[i] {
+			literals++
+		} else {
+			return nil, 0, false
+		}
+	}
+	return params, literals, true
+}
+
+// FindResponse returns the key of the response for the status code in the
+// operation, preferring an exact status, then a range such as 2XX, then
+// the default response. An empty string is returned if there is none.
+func (d *Document) FindResponse(op *OperationMatch, status int) string {
+	responses := valueOf(d.operation(op), "responses")
+	if responses == nil {
+		return ""
+	}
+	code := strconv.Itoa(status)
+	candidates := []string{code, code[:1] + "XX", code[:1] + "xx", "default"}
+	for _, candidate := range candidates {
+		if valueOf(responses, candidate) != nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// FindMediaType returns the media type in the response content matching
+// the content type, including wildcard media types such as application/*.
+// An empty string is returned if there is none.
+func (d *Document) FindMediaType(op *OperationMatch, responseKey string, contentType string) string {
+	content := d.content(op, responseKey)
+	if content == nil {
+		return ""
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+	typeOnly, _, _ := strings.Cut(mediaType, "/")
+	for _, candidate := range []string{mediaType, typeOnly + "/*", "*/*"} {
+		for i := 0; i+1 < len(content.Content); i += 2 {
+			if strings.EqualFold(content.Content[i].Value, candidate) {
+				return content.Content[i].Value
+			}
+		}
+	}
+	return ""
+}
+
+// AddExample adds the body as a named example for the media type of the
+// response. If the name is already taken, a numeric suffix is added.
+// Where the response is a reference, such as to a component shared by
+// several operations, the example is added to the referenced response.
+// JSON bodies are added as structured values, and other bodies as strings.
+// Returns the name of the example.
+func (d *Document) AddExample(op *OperationMatch, responseKey string, mediaTypeKey string, name string, body []byte) (string, error) {
+	mediaType := d.resolve(valueOf(d.content(op, responseKey), mediaTypeKey))
+	if mediaType == nil || mediaType.Kind != yaml.MappingNode {
+		return "", fmt.Errorf("no media type %s for response %s of %s %s", mediaTypeKey, responseKey, strings.ToUpper(op.Method), op.PathTemplate)
+	}
+
+	// an empty media type is often written as {}, so use block style once it has members
+	mediaType.Style = 0
+	examples := valueOf(mediaType, "examples")
+	if examples == nil {
+		examples = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		// 'example' and 'examples' are mutually exclusive, so move any existing example
+		if example := valueOf(mediaType, "example"); example != nil {
+			setValue(examples, "default", exampleNode(example))
+			removeKey(mediaType, "example")
+		}
+		setValue(mediaType, "examples", examples)
+	}
+
+	exampleName := name
+	for i := 2; valueOf(examples, exampleName) != nil; i++ {
+		exampleName = fmt.Sprintf("%s-%d", name, i)
+	}
+	setValue(examples, exampleName, exampleNode(bodyNode(body, mediaTypeKey)))
+	return exampleName, nil
+}
+
+// Save writes the document to the file, as JSON if the document
+// was loaded from a JSON file, otherwise as YAML.
+func (d *Document) Save(specFile string) error {
+	var content []byte
+	if d.json {
+		var buf bytes.Buffer
+		if err := writeNodeAsJson(&buf, d.root); err != nil {
+			return err
+		}
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, buf.Bytes(), "", "  "); err != nil {
+			return err
+		}
+		content = append(indented.Bytes(), '\n')
+	} else {
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(d.root); err != nil {
+			return err
+		}
+		if err := encoder.Close(); err != nil {
+			return err
+		}
+		content = buf.Bytes()
+	}
+
+	// write then rename, so the spec is never seen partially written
+	tmpFile := specFile + ".tmp"
+	if err := os.WriteFile(tmpFile, content, 0644); err != nil {
+		return fmt.Errorf("failed to write spec %s: %v", specFile, err)
+	}
+	if err := os.Rename(tmpFile, specFile); err != nil {
+		return fmt.Errorf("failed to write spec %s: %v", specFile, err)
+	}
+	return nil
+}
+
+func (d *Document) operation(op *OperationMatch) *yaml.Node {
+	return valueOf(valueOf(valueOf(d.root, "paths"), op.PathTemplate), op.Method)
+}
+
+// exampleNode wraps the value in an Example Object.
+func exampleNode(value *yaml.Node) *yaml.Node {
+	example := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	setValue(example, "value", value)
+	return example
+}
+
+// bodyNode converts a JSON body to a node, so it is written as a structured
+// value, falling back to a string for other bodies.
+func bodyNode(body []byte, mediaType string) *yaml.Node {
+	if strings.Contains(mediaType, "json") && json.Valid(body) {
+		var parsed yaml.Node
+		if err := yaml.Unmarshal(body, &parsed); err == nil && len(parsed.Content) > 0 {
+			value := parsed.Content[0]
+			resetStyle(value)
+			return value
+		}
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: string(body)}
+}
+
+// resetStyle clears the flow style with which JSON is parsed,
+// so that values are written in the block style of the document.
+func resetStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, child := range n.Content {
+		resetStyle(child)
+	}
+}
+
+func (d *Document) content(op *OperationMatch, responseKey string) *yaml.Node {
+	return valueOf(d.resolve(valueOf(valueOf(d.operation(op), "responses"), responseKey)), "content")
+}
+
+// maxRefDepth limits the number of references followed to resolve
+// a node, in case they form a cycle.
+const maxRefDepth = 10
+
+// resolve returns the node referred to by the $ref of the node, if it has
+// one, such as '#/components/responses/Pet'. Only references within the
+// document are followed; nil is returned for references to other files.
+func (d *Document) resolve(n *yaml.Node) *yaml.Node {
+	for depth := 0; n != nil; depth++ {
+		ref := valueOf(n, "$ref")
+		if ref == nil {
+			return n
+		}
+		if depth == maxRefDepth || !strings.HasPrefix(ref.Value, "#/") {
+			return nil
+		}
+		n = d.root
+		for _, token := range strings.Split(strings.TrimPrefix(ref.Value, "#/"), "/") {
+			if unescaped, err := url.PathUnescape(token); err == nil {
+				token = unescaped
+			}
+			n = valueOf(n, jsonPointerEscapes.Replace(token))
+		}
+	}
+	return nil
+}
+
+// jsonPointerEscapes unescapes a JSON pointer token, from RFC 6901.
+var jsonPointerEscapes = strings.NewReplacer("~1", "/", "~0", "~")
+
+// valueOf returns the value of the key in a mapping node, following any
+// alias, or nil if the node is nil, not a mapping, or has no such key.
+func valueOf(n *yaml.Node, key string) *yaml.Node {
+	if n != nil && n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			value := n.Content[i+1]
+			if value.Kind == yaml.AliasNode {
+				return value.Alias
+			}
+			return value
+		}
+	}
+	return nil
+}
+
+// setValue sets the value of the key in a mapping node, appending
+// the key if it is not already present.
+func setValue(n *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			n.Content[i+1] = value
+			return
+		}
+	}
+	n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+func removeKey(n *yaml.Node, key string) {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			n.Content = append(n.Content[:i], n.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// writeNodeAsJson writes the node as compact JSON, retaining the order of
+// mapping keys. Scalars are written according to their resolved tag.
+func writeNodeAsJson(buf *bytes.Buffer, n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeNodeAsJson(buf, n.Content[0])
+	case yaml.AliasNode:
+		return writeNodeAsJson(buf, n.Alias)
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(n.Content[i].Value)
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := writeNodeAsJson(buf, n.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range n.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeNodeAsJson(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case yaml.ScalarNode:
+		switch n.ShortTag() {
+		case "!!null":
+			buf.WriteString("null")
+		case "!!bool", "!!int", "!!float":
+			var value any
+			if err := n.Decode(&value); err != nil {
+				return err
+			}
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return fmt.Errorf("cannot write %q as JSON: %v", n.Value, err)
+			}
+			buf.Write(encoded)
+		default:
+			encoded, _ := json.Marshal(n.Value)
+			buf.Write(encoded)
+		}
+	}
+	return nil
+}
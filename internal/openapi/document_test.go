@@ -0,0 +1,223 @@
+package openapi
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const petStoreSpec = `openapi: "3.0.1"
+info:
+  title: Pet store
+  version: 1.0.0
+servers:
+  - url: https://example.com/v1
+paths:
+  # pet operations
+  /pets/{petId}:
+    get:
+      responses:
+        "200":
+          description: a pet
+          content:
+            application/json:
+              example:
+                id: 0
+                name: Example
+        4XX:
+          description: client error
+          content:
+            text/plain: {}
+  /pets/mine:
+    get:
+      responses:
+        default:
+          description: my pets
+          content:
+            application/*: {}
+`
+
+func writeSpec(t *testing.T, name string, content string) string {
+	specFile := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(specFile, []byte(content), 0644))
+	return specFile
+}
+
+func TestLoadDocument_notOpenApi3(t *testing.T) {
+	_, err := LoadDocument(writeSpec(t, "swagger.yaml", "swagger: \"2.0\"\n"))
+	require.ErrorContains(t, err, "is a Swagger 2 specification")
+
+	_, err = LoadDocument(writeSpec(t, "other.yaml", "title: other\n"))
+	require.ErrorContains(t, err, "is not an OpenAPI 3 specification")
+}
+
+func TestDocument_FindOperation(t *testing.T) {
+	doc, err := LoadDocument(writeSpec(t, "spec.yaml", petStoreSpec))
+	require.NoError(t, err)
+	require.Equal(t, "/v1", doc.BasePath())
+
+	op := doc.FindOperation("GET", "/v1/pets/1")
+	require.NotNil(t, op)
+	require.Equal(t, "/pets/{petId}", op.PathTemplate)
+	require.Equal(t, map[string]string{"petId": "1"}, op.PathParams)
+
+	op = doc.FindOperation("GET", "/v1/pets/mine")
+	require.NotNil(t, op)
+	require.Equal(t, "/pets/mine", op.PathTemplate, "literal segments should be preferred")
+
+	require.Nil(t, doc.FindOperation("DELETE", "/v1/pets/1"))
+	require.Nil(t, doc.FindOperation("GET", "/pets/1"), "base path should be required")
+}
+
+func TestDocument_FindResponse(t *testing.T) {
+	doc, err := LoadDocument(writeSpec(t, "spec.yaml", petStoreSpec))
+	require.NoError(t, err)
+	op := doc.FindOperation("GET", "/v1/pets/1")
+
+	require.Equal(t, "200", doc.FindResponse(op, 200))
+	require.Equal(t, "4XX", doc.FindResponse(op, 404))
+	require.Equal(t, "", doc.FindResponse(op, 500))
+	require.Equal(t, "default", doc.FindResponse(doc.FindOperation("GET", "/v1/pets/mine"), 500))
+
+	require.Equal(t, "application/json", doc.FindMediaType(op, "200", "application/json; charset=utf-8"))
+	require.Equal(t, "", doc.FindMediaType(op, "200", "text/html"))
+	require.Equal(t, "application/*", doc.FindMediaType(doc.FindOperation("GET", "/v1/pets/mine"), "default", "application/xml"))
+}
+
+func TestDocument_AddExample(t *testing.T) {
+	specFile := writeSpec(t, "spec.yaml", petStoreSpec)
+	doc, err := LoadDocument(specFile)
+	require.NoError(t, err)
+	op := doc.FindOperation("GET", "/v1/pets/1")
+
+	name, err := doc.AddExample(op, "200", "application/json", "pets-1", []byte(`{"id":1,"name":"Fluffy","tags":["cat"]}`))
+	require.NoError(t, err)
+	require.Equal(t, "pets-1", name)
+
+	name, err = doc.AddExample(op, "200", "application/json", "pets-1", []byte(`{"id":1,"name":"Fluffy"}`))
+	require.NoError(t, err)
+	require.Equal(t, "pets-1-2", name, "name should be made unique")
+
+	name, err = doc.AddExample(op, "4XX", "text/plain", "pets-1", []byte("not found"))
+	require.NoError(t, err)
+	require.Equal(t, "pets-1", name)
+
+	_, err = doc.AddExample(op, "200", "text/html", "pets-1", []byte("<p/>"))
+	require.Error(t, err)
+
+	require.NoError(t, doc.Save(specFile))
+	saved, err := os.ReadFile(specFile)
+	require.NoError(t, err)
+	require.Contains(t, string(saved), "# pet operations", "comments should be retained")
+	require.Contains(t, string(saved), `              examples:
+                default:
+                  value:
+                    id: 0
+                    name: Example
+                pets-1:
+                  value:
+                    id: 1
+                    name: Fluffy
+                    tags:
+                      - cat
+`)
+	require.NotContains(t, string(saved), "example:\n", "existing example should be moved to examples")
+	require.Contains(t, string(saved), `                pets-1:
+                  value: not found
+`)
+
+	reloaded, err := LoadDocument(specFile)
+	require.NoError(t, err)
+	require.NotNil(t, reloaded.FindOperation("GET", "/v1/pets/1"))
+}
+
+func TestDocument_AddExample_ref(t *testing.T) {
+	specFile := writeSpec(t, "spec.yaml", `openapi: "3.0.1"
+paths:
+  /pets/{petId}:
+    get:
+      responses:
+        "200":
+          $ref: "#/components/responses/Pet"
+        default:
+          $ref: "#/components/responses/Missing"
+components:
+  responses:
+    Pet:
+      description: a pet
+      content:
+        application/json:
+          $ref: "#/components/x-media-types/pet~1json"
+  x-media-types:
+    pet/json:
+      schema:
+        type: object
+`)
+	doc, err := LoadDocument(specFile)
+	require.NoError(t, err)
+	op := doc.FindOperation("GET", "/pets/1")
+
+	require.Equal(t, "200", doc.FindResponse(op, 200))
+	require.Equal(t, "application/json", doc.FindMediaType(op, "200", "application/json"))
+	name, err := doc.AddExample(op, "200", "application/json", "pets-1", []byte(`{"id":1}`))
+	require.NoError(t, err)
+	require.Equal(t, "pets-1", name)
+
+	_, err = doc.AddExample(op, "default", "application/json", "pets-1", []byte(`{}`))
+	require.Error(t, err, "unresolved reference should not have media types")
+
+	require.NoError(t, doc.Save(specFile))
+	saved, err := os.ReadFile(specFile)
+	require.NoError(t, err)
+	require.Contains(t, string(saved), `    pet/json:
+      schema:
+        type: object
+      examples:
+        pets-1:
+          value:
+            id: 1
+`, "example should be added to the referenced media type")
+}
+
+func TestDocument_Save_json(t *testing.T) {
+	specFile := writeSpec(t, "spec.json", `{
+  "openapi": "3.0.1",
+  "paths": {
+    "/pets": {
+      "get": {
+        "responses": {
+          "200": {"content": {"application/json": {}}}
+        }
+      }
+    }
+  }
+}`)
+	doc, err := LoadDocument(specFile)
+	require.NoError(t, err)
+	op := doc.FindOperation("GET", "/pets")
+	require.NotNil(t, op)
+
+	_, err = doc.AddExample(op, "200", "application/json", "pets", []byte(`[{"id":1,"name":"Fluffy","age":1.5,"cat":true,"owner":null,"code":"007"}]`))
+	require.NoError(t, err)
+	require.NoError(t, doc.Save(specFile))
+
+	saved, err := os.ReadFile(specFile)
+	require.NoError(t, err)
+	var spec map[string]any
+	require.NoError(t, json.Unmarshal(saved, &spec), "saved spec should be JSON")
+	require.Contains(t, string(saved), `"openapi": "3.0.1",
+  "paths"`, "member order should be retained")
+	require.Contains(t, string(saved), `"value": [
+                      {
+                        "id": 1,
+                        "name": "Fluffy",
+                        "age": 1.5,
+                        "cat": true,
+                        "owner": null,
+                        "code": "007"
+                      }
+                    ]`)
+}
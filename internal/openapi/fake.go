@@ -0,0 +1,396 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxFakeDepth limits the nesting of generated values.
+	maxFakeDepth = 10
+
+	// defaultMaxFakeItems is the most items generated for an
+	// array without a maxItems constraint.
+	defaultMaxFakeItems = 3
+
+	defaultMaxFakeNumber = 1000
+
+	// maxFakeInteger bounds generated integers, so that the span between
+	// the bounds of a schema neither overflows nor loses precision.
+	maxFakeInteger = 1 << 53
+
+	// maxMultipleDenominator is the largest denominator of a fractional
+	// multipleOf, such as 2 for 2.5, for which integers are generated.
+	maxMultipleDenominator = 1000
+)
+
+var fakeWords = []string{
+	"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel",
+	"india", "juliet", "kilo", "lima", "mike", "november", "oscar", "papa",
+	"quebec", "romeo", "sierra", "tango", "uniform", "victor", "whiskey", "yankee",
+}
+
+// fakeEpoch is the earliest date generated, so that
+// output depends only on the seed, not the current time.
+var fakeEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// FakeDataGenerator generates values conforming to a schema, using the
+// examples, defaults and enums it declares where present. Values are
+// derived from the seed, so a generator with the same seed produces the
+// same values for the same sequence of schemas.
+type FakeDataGenerator struct {
+	random *rand.Rand
+}
+
+func NewFakeDataGenerator(seed int64) *FakeDataGenerator {
+	return &FakeDataGenerator{random: rand.New(rand.NewSource(seed))}
+}
+
+// Generate returns a value conforming to the schema.
+func (g *FakeDataGenerator) Generate(schema *Schema) any {
+	return g.generate(schema, 0)
+}
+
+func (g *FakeDataGenerator) generate(schema *Schema, depth int) any {
+	if schema == nil {
+		return g.word()
+	}
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case len(schema.Examples) > 0:
+		return schema.Examples[0]
+	case schema.Const != nil:
+		return schema.Const
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[g.random.Intn(len(schema.Enum))]
+	case len(schema.AllOf) > 0:
+		return g.generateAllOf(schema, depth)
+	case len(schema.OneOf) > 0:
+		return g.generate(schema.OneOf[g.random.Intn(len(schema.OneOf))], depth)
+	case len(schema.AnyOf) > 0:
+		return g.generate(schema.AnyOf[g.random.Intn(len(schema.AnyOf))], depth)
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		return g.generateObject(schema, depth)
+	case "array":
+		return g.generateArray(schema, depth)
+	case "integer":
+		return g.generateInteger(schema)
+	case "number":
+		return g.generateNumber(schema)
+	case "boolean":
+		return g.random.Intn(2) == 1
+	case "null":
+		return nil
+	default:
+		return g.generateString(schema)
+	}
+}
+
+// schemaType returns the type of the schema, inferring
+// it from the members present if it is not declared.
+func schemaType(schema *Schema) string {
+	if primary := schema.Type.Primary(); primary != "" {
+		return primary
+	}
+	switch {
+	case len(schema.Properties) > 0 || len(schema.Required) > 0 || len(schema.AdditionalProperties) > 0:
+		return "object"
+	case schema.Items != nil:
+		return "array"
+	case schema.Minimum != nil || schema.Maximum != nil:
+		return "number"
+	default:
+		return "string"
+	}
+}
+
+// generateAllOf merges the objects generated for each schema, or returns
+// the value for the first schema if they are not objects.
+func (g *FakeDataGenerator) generateAllOf(schema *Schema, depth int) any {
+	merged := make(map[string]any)
+	var first any
+	for i, subschema := range schema.AllOf {
+		value := g.generate(subschema, depth)
+		if object, ok := value.(map[string]any); ok {
+			for name, property := range object {
+				merged[name] = property
+			}
+		} else if i == 0 {
+			first = value
+		}
+	}
+	// members alongside allOf also describe the object
+	if len(schema.Properties) > 0 {
+		for name, property := range g.generateObject(schema, depth).(map[string]any) {
+			merged[name] = property
+		}
+	}
+	if len(merged) == 0 && first != nil {
+		return first
+	}
+	return merged
+}
+
+// generateObject generates every property, in lexical order so output
+// is reproducible, along with any required property that is not described.
+func (g *FakeDataGenerator) generateObject(schema *Schema, depth int) any {
+	object := make(map[string]any)
+	if depth >= maxFakeDepth {
+		return object
+	}
+	var names []string
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		object[name] = g.generate(schema.Properties[name], depth+1)
+	}
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			object[name] = g.word()
+		}
+	}
+	if len(names) == 0 && len(schema.AdditionalProperties) > 0 {
+		var additional Schema
+		if err := json.Unmarshal(schema.AdditionalProperties, &additional); err == nil {
+			object[g.word()] = g.generate(&additional, depth+1)
+		}
+	}
+	return object
+}
+
+func (g *FakeDataGenerator) generateArray(schema *Schema, depth int) any {
+	items := []any{}
+	if depth >= maxFakeDepth {
+		return items
+	}
+	minItems := 1
+	if schema.MinItems != nil {
+		minItems = *schema.MinItems
+	}
+	maxItems := max(minItems, defaultMaxFakeItems)
+	if schema.MaxItems != nil {
+		maxItems = *schema.MaxItems
+		minItems = min(minItems, maxItems)
+	}
+	count := minItems + g.random.Intn(maxItems-minItems+1)
+
+	seen := make(map[string]bool)
+	for attempts := 0; len(items) < count && attempts < count*10; attempts++ {
+		item := g.generate(schema.Items, depth+1)
+		if schema.UniqueItems {
+			key := fmt.Sprint(item)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// numericBounds returns the inclusive bounds of a numeric schema. The
+// step is used to adjust exclusive bounds, and a range of defaultMaxFakeNumber
+// is used when a bound is missing.
+func numericBounds(schema *Schema, step float64) (float64, float64) {
+	lower, upper := 0.0, float64(defaultMaxFakeNumber)
+	if schema.Minimum != nil {
+		lower = *schema.Minimum
+		if schema.Maximum == nil {
+			upper = lower + defaultMaxFakeNumber
+		}
+	}
+	if schema.Maximum != nil {
+		upper = *schema.Maximum
+		if schema.Minimum == nil {
+			lower = math.Min(0, upper-defaultMaxFakeNumber)
+		}
+	}
+	// OpenAPI 3.0 uses booleans that modify minimum and maximum,
+	// whereas OpenAPI 3.1 uses numbers that replace them
+	if exclusive, ok := exclusiveBound(schema.ExclusiveMinimum); ok {
+		lower = exclusive + step
+	} else if string(schema.ExclusiveMinimum) == "true" {
+		lower += step
+	}
+	if exclusive, ok := exclusiveBound(schema.ExclusiveMaximum); ok {
+		upper = exclusive - step
+	} else if string(schema.ExclusiveMaximum) == "true" {
+		upper -= step
+	}
+	if upper < lower {
+		upper = lower
+	}
+	return lower, upper
+}
+
+func exclusiveBound(raw json.RawMessage) (float64, bool) {
+	if len(raw) == 0 {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(string(raw), 64)
+	return value, err == nil
+}
+
+func (g *FakeDataGenerator) generateInteger(schema *Schema) any {
+	lower, upper := numericBounds(schema, 1)
+	lower = math.Max(-maxFakeInteger, math.Min(maxFakeInteger, lower))
+	upper = math.Max(-maxFakeInteger, math.Min(maxFakeInteger, upper))
+	low, high := int64(math.Ceil(lower)), int64(math.Floor(upper))
+	if high < low {
+		high = low
+	}
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		if multiple, ok := integerMultiple(*schema.MultipleOf); ok {
+			first, last := ceilDiv(low, multiple), floorDiv(high, multiple)
+			if first <= last {
+				return (first + g.random.Int63n(last-first+1)) * multiple
+			}
+		}
+		// no integer multiple lies within the bounds, so the bounds take precedence
+	}
+	return low + g.random.Int63n(high-low+1)
+}
+
+// integerMultiple returns the smallest positive integer that is a multiple
+// of m, such as 5 for 2.5, or 1 for 0.25. Every integer multiple of m is a
+// multiple of it. Returns false if there is none of a practical size.
+func integerMultiple(m float64) (int64, bool) {
+	for n := 1; n <= maxMultipleDenominator; n++ {
+		product := m * float64(n)
+		rounded := math.Round(product)
+		if rounded > maxFakeInteger {
+			return 0, false
+		}
+		if rounded >= 1 && math.Abs(product-rounded) <= 1e-9*product {
+			return int64(rounded), true
+		}
+	}
+	return 0, false
+}
+
+func (g *FakeDataGenerator) generateNumber(schema *Schema) any {
+	lower, upper := numericBounds(schema, 0.01)
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		multiple := *schema.MultipleOf
+		first, last := math.Ceil(lower/multiple), math.Floor(upper/multiple)
+		if first <= last {
+			return (first + math.Floor(g.random.Float64()*(last-first+1))) * multiple
+		}
+	}
+	value := lower + g.random.Float64()*(upper-lower)
+	return math.Round(value*100) / 100
+}
+
+// floorDiv returns a/b rounded towards negative infinity, for b > 0.
+func floorDiv(a int64, b int64) int64 {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
+
+// ceilDiv returns a/b rounded towards positive infinity, for b > 0.
+func ceilDiv(a int64, b int64) int64 {
+	q := a / b
+	if a%b != 0 && a > 0 {
+		q++
+	}
+	return q
+}
+
+func (g *FakeDataGenerator) generateString(schema *Schema) any {
+	switch schema.Format {
+	case "date":
+		return g.time().Format(time.DateOnly)
+	case "date-time":
+		return g.time().Format(time.RFC3339)
+	case "time":
+		return g.time().Format(time.TimeOnly)
+	case "email":
+		return g.word() + "@example.com"
+	case "uuid":
+		return g.uuid()
+	case "uri", "url":
+		return "https://example.com/" + g.word()
+	case "hostname":
+		return g.word() + ".example.com"
+	case "ipv4":
+		return fmt.Sprintf("192.0.2.%d", 1+g.random.Intn(254))
+	case "ipv6":
+		return fmt.Sprintf("2001:db8::%x", 1+g.random.Intn(0xfffe))
+	case "byte":
+		return base64.StdEncoding.EncodeToString([]byte(g.word()))
+	}
+
+	value := g.word()
+	minLength, maxLength := 0, math.MaxInt
+	if schema.MinLength != nil {
+		minLength = *schema.MinLength
+	}
+	if schema.MaxLength != nil {
+		maxLength = *schema.MaxLength
+	}
+	for len(value) < minLength {
+		value += "-" + g.word()
+	}
+	if len(value) > maxLength {
+		value = value[:maxLength]
+	}
+	return value
+}
+
+func (g *FakeDataGenerator) word() string {
+	return fakeWords[g.random.Intn(len(fakeWords))]
+}
+
+// time returns a time within a few years of fakeEpoch, to the second.
+func (g *FakeDataGenerator) time() time.Time {
+	return fakeEpoch.Add(time.Duration(g.random.Int63n(5*365*24*3600)) * time.Second)
+}
+
+func (g *FakeDataGenerator) uuid() string {
+	b := make([]byte, 16)
+	g.random.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return strings.Join([]string{
+		fmt.Sprintf("%x", b[0:4]),
+		fmt.Sprintf("%x", b[4:6]),
+		fmt.Sprintf("%x", b[6:8]),
+		fmt.Sprintf("%x", b[8:10]),
+		fmt.Sprintf("%x", b[10:16]),
+	}, "-")
+}
@@ -0,0 +1,209 @@
+package openapi
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func parseSchema(t *testing.T, schemaJson string) *Schema {
+	var schema Schema
+	require.NoError(t, json.Unmarshal([]byte(schemaJson), &schema))
+	return &schema
+}
+
+func TestFakeDataGenerator_Generate(t *testing.T) {
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	tests := []struct {
+		name   string
+		schema string
+		check  func(t *testing.T, value any)
+	}{
+		{
+			name:   "integer within bounds",
+			schema: `{"type": "integer", "minimum": 5, "maximum": 7}`,
+			check: func(t *testing.T, value any) {
+				require.GreaterOrEqual(t, value, int64(5))
+				require.LessOrEqual(t, value, int64(7))
+			},
+		},
+		{
+			name:   "integer within exclusive bounds",
+			schema: `{"type": "integer", "minimum": 5, "maximum": 7, "exclusiveMinimum": true, "exclusiveMaximum": true}`,
+			check: func(t *testing.T, value any) {
+				require.Equal(t, int64(6), value)
+			},
+		},
+		{
+			name:   "number within OpenAPI 3.1 exclusive bounds",
+			schema: `{"type": "number", "exclusiveMinimum": 1, "exclusiveMaximum": 2}`,
+			check: func(t *testing.T, value any) {
+				require.Greater(t, value, 1.0)
+				require.Less(t, value, 2.0)
+			},
+		},
+		{
+			name:   "integer multiple",
+			schema: `{"type": "integer", "minimum": 1, "maximum": 100, "multipleOf": 10}`,
+			check: func(t *testing.T, value any) {
+				require.Zero(t, value.(int64)%10)
+				require.GreaterOrEqual(t, value, int64(10))
+			},
+		},
+		{
+			name:   "negative integer multiple",
+			schema: `{"type": "integer", "minimum": -10, "maximum": -5, "multipleOf": 3}`,
+			check: func(t *testing.T, value any) {
+				require.Contains(t, []any{int64(-9), int64(-6)}, value)
+			},
+		},
+		{
+			name:   "integer without a multiple within bounds",
+			schema: `{"type": "integer", "minimum": 5, "maximum": 7, "multipleOf": 10}`,
+			check: func(t *testing.T, value any) {
+				require.GreaterOrEqual(t, value, int64(5))
+				require.LessOrEqual(t, value, int64(7))
+			},
+		},
+		{
+			name:   "integer with a fractional multiple",
+			schema: `{"type": "integer", "minimum": 1, "maximum": 100, "multipleOf": 2.5}`,
+			check: func(t *testing.T, value any) {
+				require.Zero(t, value.(int64)%5, "integer multiples of 2.5 are multiples of 5")
+				require.GreaterOrEqual(t, value, int64(5))
+			},
+		},
+		{
+			name:   "integer with a multiple below 1",
+			schema: `{"type": "integer", "minimum": 1, "maximum": 10, "multipleOf": 0.3}`,
+			check: func(t *testing.T, value any) {
+				require.Contains(t, []any{int64(3), int64(6), int64(9)}, value)
+			},
+		},
+		{
+			name:   "integer without a fractional multiple within bounds",
+			schema: `{"type": "integer", "minimum": 1, "maximum": 4, "multipleOf": 2.5}`,
+			check: func(t *testing.T, value any) {
+				require.GreaterOrEqual(t, value, int64(1))
+				require.LessOrEqual(t, value, int64(4))
+			},
+		},
+		{
+			name:   "integer with the widest bounds",
+			schema: `{"type": "integer", "minimum": -9223372036854775808, "maximum": 9223372036854775807}`,
+			check: func(t *testing.T, value any) {
+				require.IsType(t, int64(0), value)
+			},
+		},
+		{
+			name:   "number multiple",
+			schema: `{"type": "number", "minimum": 0.1, "maximum": 0.3, "multipleOf": 0.25}`,
+			check: func(t *testing.T, value any) {
+				require.Equal(t, 0.25, value)
+			},
+		},
+		{
+			name:   "string length",
+			schema: `{"type": "string", "minLength": 20, "maxLength": 25}`,
+			check: func(t *testing.T, value any) {
+				require.GreaterOrEqual(t, len(value.(string)), 20)
+				require.LessOrEqual(t, len(value.(string)), 25)
+			},
+		},
+		{
+			name:   "date-time format",
+			schema: `{"type": "string", "format": "date-time"}`,
+			check: func(t *testing.T, value any) {
+				_, err := time.Parse(time.RFC3339, value.(string))
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:   "uuid format",
+			schema: `{"type": "string", "format": "uuid"}`,
+			check: func(t *testing.T, value any) {
+				require.Regexp(t, uuidPattern, value)
+			},
+		},
+		{
+			name:   "email format",
+			schema: `{"type": "string", "format": "email"}`,
+			check: func(t *testing.T, value any) {
+				require.Regexp(t, `^[a-z]+@example\.com$`, value)
+			},
+		},
+		{
+			name:   "enum",
+			schema: `{"type": "string", "enum": ["a", "b"]}`,
+			check: func(t *testing.T, value any) {
+				require.Contains(t, []any{"a", "b"}, value)
+			},
+		},
+		{
+			name:   "array items",
+			schema: `{"type": "array", "minItems": 2, "maxItems": 2, "items": {"type": "boolean"}}`,
+			check: func(t *testing.T, value any) {
+				require.Len(t, value, 2)
+				require.IsType(t, true, value.([]any)[0])
+			},
+		},
+		{
+			name:   "object with required and example properties",
+			schema: `{"properties": {"id": {"type": "integer"}, "name": {"type": "string", "example": "Rex"}}, "required": ["id", "tag"]}`,
+			check: func(t *testing.T, value any) {
+				object := value.(map[string]any)
+				require.Equal(t, "Rex", object["name"])
+				require.IsType(t, int64(0), object["id"])
+				require.Contains(t, object, "tag", "undescribed required properties should be generated")
+			},
+		},
+		{
+			name:   "allOf merges objects",
+			schema: `{"allOf": [{"properties": {"id": {"type": "integer"}}}, {"properties": {"name": {"type": "string"}}}]}`,
+			check: func(t *testing.T, value any) {
+				require.Contains(t, value, "id")
+				require.Contains(t, value, "name")
+			},
+		},
+		{
+			name:   "oneOf chooses an alternative",
+			schema: `{"oneOf": [{"properties": {"cat": {"type": "string"}}}, {"properties": {"dog": {"type": "string"}}}]}`,
+			check: func(t *testing.T, value any) {
+				require.Len(t, value, 1)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := parseSchema(t, tt.schema)
+			for seed := int64(0); seed < 20; seed++ {
+				tt.check(t, NewFakeDataGenerator(seed).Generate(schema))
+			}
+		})
+	}
+}
+
+func TestFakeDataGenerator_seed(t *testing.T) {
+	schema := parseSchema(t, `{
+		"type": "array",
+		"items": {
+			"properties": {
+				"id": {"type": "string", "format": "uuid"},
+				"created": {"type": "string", "format": "date"},
+				"score": {"type": "number"},
+				"tags": {"type": "array", "items": {"type": "string"}}
+			}
+		}
+	}`)
+	generate := func(seed int64) string {
+		value, err := json.Marshal(NewFakeDataGenerator(seed).Generate(schema))
+		require.NoError(t, err)
+		return string(value)
+	}
+	require.Equal(t, generate(1), generate(1))
+	require.NotEqual(t, generate(1), generate(2))
+}
@@ -0,0 +1,184 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"sort"
+	"time"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// InferSchema returns a schema describing the JSON value, as decoded by
+// encoding/json, with or without UseNumber. Each property of an object is
+// required, and a null value is described by a nullable schema without a
+// type, so that merging it with another schema makes that schema nullable.
+func InferSchema(value any) *Schema {
+	switch v := value.(type) {
+	case nil:
+		return &Schema{Nullable: true}
+	case bool:
+		return &Schema{Type: SchemaType{"boolean"}}
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return &Schema{Type: SchemaType{"integer"}}
+		}
+		return &Schema{Type: SchemaType{"number"}}
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return &Schema{Type: SchemaType{"integer"}}
+		}
+		return &Schema{Type: SchemaType{"number"}}
+	case string:
+		return &Schema{Type: SchemaType{"string"}, Format: stringFormat(v)}
+	case []any:
+		schema := &Schema{Type: SchemaType{"array"}}
+		for _, item := range v {
+			schema.Items = MergeSchemas(schema.Items, InferSchema(item))
+		}
+		return schema
+	case map[string]any:
+		schema := &Schema{Type: SchemaType{"object"}, Properties: make(map[string]*Schema)}
+		for name, property := range v {
+			schema.Properties[name] = InferSchema(property)
+			schema.Required = append(schema.Required, name)
+		}
+		sort.Strings(schema.Required)
+		return schema
+	default:
+		return &Schema{}
+	}
+}
+
+// stringFormat returns the format of the string, if it is a date,
+// date-time or UUID, or an empty string otherwise.
+func stringFormat(value string) string {
+	if _, err := time.Parse(time.RFC3339, value); err == nil {
+		return "date-time"
+	}
+	if _, err := time.Parse(time.DateOnly, value); err == nil {
+		return "date"
+	}
+	if uuidPattern.MatchString(value) {
+		return "uuid"
+	}
+	return ""
+}
+
+// MergeSchemas returns a schema describing the values described by either
+// of the schemas, which are expected to have been built by InferSchema.
+// A property is only required if both schemas require it, an integer is
+// widened to a number, and values of different types are described using
+// anyOf. Neither schema is modified.
+func MergeSchemas(a *Schema, b *Schema) *Schema {
+	if a == nil {
+		return b
+	} else if b == nil {
+		return a
+	}
+	merged := alternatives(a)
+	for _, alt := range alternatives(b) {
+		found := false
+		for i, existing := range merged {
+			if sameKind(existing, alt) {
+				merged[i] = mergeSameKind(existing, alt)
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, alt)
+		}
+	}
+
+	nullable := a.Nullable || b.Nullable
+	switch len(merged) {
+	case 0:
+		return &Schema{Nullable: nullable}
+	case 1:
+		merged[0].Nullable = nullable
+		return merged[0]
+	default:
+		return &Schema{AnyOf: merged, Nullable: nullable}
+	}
+}
+
+// alternatives returns copies of the typed schemas that make up
+// the schema, without their nullability.
+func alternatives(schema *Schema) []*Schema {
+	var alts []*Schema
+	if len(schema.AnyOf) > 0 {
+		for _, alt := range schema.AnyOf {
+			copied := *alt
+			alts = append(alts, &copied)
+		}
+	} else if len(schema.Type) > 0 {
+		copied := *schema
+		alts = append(alts, &copied)
+	}
+	for _, alt := range alts {
+		alt.Nullable = false
+	}
+	return alts
+}
+
+func sameKind(a *Schema, b *Schema) bool {
+	aType, bType := a.Type.Primary(), b.Type.Primary()
+	return aType == bType || (isNumeric(aType) && isNumeric(bType))
+}
+
+func isNumeric(schemaType string) bool {
+	return schemaType == "integer" || schemaType == "number"
+}
+
+// mergeSameKind merges two schemas of the same, or numeric, type.
+func mergeSameKind(a *Schema, b *Schema) *Schema {
+	merged := *a
+	if a.Type.Primary() != b.Type.Primary() {
+		merged.Type = SchemaType{"number"}
+	}
+	if a.Format != b.Format {
+		merged.Format = ""
+	}
+	switch merged.Type.Primary() {
+	case "array":
+		merged.Items = MergeSchemas(a.Items, b.Items)
+	case "object":
+		merged.Properties = make(map[string]*Schema)
+		for name, property := range a.Properties {
+			merged.Properties[name] = MergeSchemas(property, b.Properties[name])
+		}
+		for name, property := range b.Properties {
+			if _, ok := a.Properties[name]; !ok {
+				merged.Properties[name] = property
+			}
+		}
+		merged.Required = nil
+		for _, name := range a.Required {
+			for _, other := range b.Required {
+				if name == other {
+					merged.Required = append(merged.Required, name)
+					break
+				}
+			}
+		}
+	}
+	return &merged
+}
@@ -0,0 +1,53 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferSchema(t *testing.T) {
+	var value any
+	require.NoError(t, json.Unmarshal([]byte(`{
+  "id": 1,
+  "price": 9.99,
+  "name": "Fluffy",
+  "born": "2020-01-02",
+  "updated": "2024-05-06T07:08:09Z",
+  "tags": ["a", "b"],
+  "owner": null
+}`), &value))
+
+	schema := InferSchema(value)
+	require.Equal(t, SchemaType{"object"}, schema.Type)
+	require.Equal(t, []string{"born", "id", "name", "owner", "price", "tags", "updated"}, schema.Required)
+	require.Equal(t, SchemaType{"integer"}, schema.Properties["id"].Type)
+	require.Equal(t, SchemaType{"number"}, schema.Properties["price"].Type)
+	require.Equal(t, "date", schema.Properties["born"].Format)
+	require.Equal(t, "date-time", schema.Properties["updated"].Format)
+	require.Equal(t, SchemaType{"string"}, schema.Properties["tags"].Items.Type)
+	require.Equal(t, &Schema{Nullable: true}, schema.Properties["owner"])
+}
+
+func TestMergeSchemas(t *testing.T) {
+	infer := func(body string) *Schema {
+		var value any
+		require.NoError(t, json.Unmarshal([]byte(body), &value))
+		return InferSchema(value)
+	}
+
+	merged := MergeSchemas(
+		infer(`{"id": 1, "name": "Fluffy", "owner": null, "tags": []}`),
+		infer(`{"id": 2.5, "owner": "alice", "tags": ["a"], "age": 3}`),
+	)
+	require.Equal(t, []string{"id", "owner", "tags"}, merged.Required, "only properties present in both should be required")
+	require.Equal(t, SchemaType{"number"}, merged.Properties["id"].Type, "integers should be widened to numbers")
+	require.Equal(t, &Schema{Type: SchemaType{"string"}, Nullable: true}, merged.Properties["owner"])
+	require.Equal(t, SchemaType{"string"}, merged.Properties["tags"].Items.Type)
+	require.Equal(t, SchemaType{"integer"}, merged.Properties["age"].Type)
+	require.Equal(t, SchemaType{"string"}, merged.Properties["name"].Type)
+
+	mixed := MergeSchemas(MergeSchemas(infer(`"a"`), infer(`1`)), infer(`"b"`))
+	require.Equal(t, &Schema{AnyOf: []*Schema{{Type: SchemaType{"string"}}, {Type: SchemaType{"integer"}}}}, mixed)
+}
@@ -0,0 +1,226 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// Spec is an OpenAPI 3.x specification, with all references resolved.
+// Swagger 2 specifications are converted to this model when parsed.
+type Spec struct {
+	// Version is the value of the 'openapi' or 'swagger' member.
+	Version string    `json:"openapi"`
+	Info    *Info     `json:"info,omitempty"`
+	Servers []Server  `json:"servers,omitempty"`
+	Paths   PathItems `json:"paths,omitempty"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Server struct {
+	Url string `json:"url"`
+}
+
+// PathItems holds the operations for each path, keyed by path, then by
+// lower case HTTP method.
+type PathItems map[string]map[string]*Operation
+
+type Operation struct {
+	OperationId string               `json:"operationId,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses,omitempty"`
+}
+
+// Parameter is a path, query, header or cookie parameter.
+type Parameter struct {
+	Name     string             `json:"name"`
+	In       string             `json:"in"`
+	Required bool               `json:"required,omitempty"`
+	Schema   *Schema            `json:"schema,omitempty"`
+	Example  any                `json:"example,omitempty"`
+	Examples map[string]Example `json:"examples,omitempty"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content,omitempty"`
+}
+
+type Response struct {
+	Description string               `json:"description,omitempty"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Schema  *Schema `json:"schema,omitempty"`
+	Example any     `json:"example,omitempty"`
+}
+
+type MediaType struct {
+	Schema   *Schema            `json:"schema,omitempty"`
+	Example  any                `json:"example,omitempty"`
+	Examples map[string]Example `json:"examples,omitempty"`
+}
+
+type Example struct {
+	Summary       string `json:"summary,omitempty"`
+	Value         any    `json:"value,omitempty"`
+	ExternalValue string `json:"externalValue,omitempty"`
+}
+
+// Schema is a JSON schema, as used by OpenAPI to describe parameters
+// and bodies.
+type Schema struct {
+	Type                 SchemaType         `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Const                any                `json:"const,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Example              any                `json:"example,omitempty"`
+	Examples             []any              `json:"examples,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     json.RawMessage    `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     json.RawMessage    `json:"exclusiveMaximum,omitempty"`
+	MultipleOf           *float64           `json:"multipleOf,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	UniqueItems          bool               `json:"uniqueItems,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+}
+
+// SchemaType holds the types of a schema. OpenAPI 3.0 permits a single
+// type, whereas OpenAPI 3.1 permits a list, such as [string, "null"].
+type SchemaType []string
+
+func (t *SchemaType) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = SchemaType{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*t = multiple
+	return nil
+}
+
+func (t SchemaType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// Primary returns the first type other than null, or an empty
+// string if the schema has no type.
+func (t SchemaType) Primary() string {
+	for _, schemaType := range t {
+		if schemaType != "null" {
+			return schemaType
+		}
+	}
+	return ""
+}
+
+// SortedPaths returns the paths in the spec in lexical order.
+func (s *Spec) SortedPaths() []string {
+	var paths []string
+	for path := range s.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// BasePath returns the path of the first server URL, without a trailing slash.
+func (s *Spec) BasePath() string {
+	if len(s.Servers) == 0 {
+		return ""
+	}
+	return serverBasePath(s.Servers[0].Url)
+}
+
+// SortedMethods returns the lower case HTTP methods of
+// the operations for the path, in lexical order.
+func (p PathItems) SortedMethods(path string) []string {
+	var methods []string
+	for method := range p[path] {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// ExampleValue returns the value of the parameter's example, if any,
+// preferring the parameter's own example to that of its schema.
+func (p Parameter) ExampleValue() (any, bool) {
+	if p.Example != nil {
+		return p.Example, true
+	}
+	if value, ok := firstExample(p.Examples); ok {
+		return value, true
+	}
+	if p.Schema != nil && p.Schema.Example != nil {
+		return p.Schema.Example, true
+	}
+	return nil, false
+}
+
+// ExampleValue returns the value of the media type's example, if any,
+// choosing the first named example in lexical order if there are several.
+func (m MediaType) ExampleValue() (any, bool) {
+	if m.Example != nil {
+		return m.Example, true
+	}
+	return firstExample(m.Examples)
+}
+
+func firstExample(examples map[string]Example) (any, bool) {
+	var names []string
+	for name, example := range examples {
+		if example.Value != nil {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, false
+	}
+	sort.Strings(names)
+	return examples[names[0]].Value, true
+}
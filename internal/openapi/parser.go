@@ -18,47 +18,130 @@ package openapi
 
 import (
 	"fmt"
-	"gopkg.in/yaml.v2"
-	"io"
-	"os"
+	"path/filepath"
+	"strings"
 )
 
-type OperationResponse struct {
-	Description string
+// httpMethods are the members of a path item that are operations.
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
 
-	// key is content type
-	Content map[string]interface{}
-}
-
-type Operation struct {
-	// key is status code
-	Responses   map[string]OperationResponse
-	Description string
-}
-
-type PartialModel struct {
-	// key is path
-	Paths map[string]map[string]Operation
-}
-
-func Parse(specFile string) (*PartialModel, error) {
-	reader, err := os.Open(specFile)
+// Parse reads an OpenAPI 3.x or Swagger 2 specification, resolving local
+// and file-relative references. Swagger 2 specifications are converted to
+// the OpenAPI 3 model, and parameters declared for a path are added to each
+// of its operations.
+func Parse(specFile string) (*Spec, error) {
+	absFile, err := filepath.Abs(specFile)
 	if err != nil {
 		return nil, err
 	}
-	defer reader.Close()
-
-	raw, err := io.ReadAll(reader)
+	resolver := newRefResolver()
+	doc, err := resolver.load(absFile)
 	if err != nil {
 		return nil, err
 	}
+	resolved, err := resolver.resolve(doc, absFile)
+	if err != nil {
+		return nil, err
+	}
+	root, ok := resolved.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("spec %s is not an object", specFile)
+	}
+	if _, ok := root["swagger"]; ok {
+		root = convertSwagger2(root)
+	}
 
-	o := PartialModel{}
-	err = yaml.Unmarshal(raw, &o)
+	spec, err := buildSpec(root)
 	if err != nil {
-		return nil, fmt.Errorf("error: %v\n", err)
+		return nil, fmt.Errorf("error parsing spec %s: %v", specFile, err)
 	}
+	logger.Tracef("openapi parsed:\n%v\n\n", spec)
+	return spec, nil
+}
 
-	logger.Tracef("openapi parsed:\n%v\n\n", o)
-	return &o, nil
+func buildSpec(root map[string]any) (*Spec, error) {
+	spec := &Spec{
+		Version: fmt.Sprint(root["openapi"]),
+		Paths:   make(PathItems),
+	}
+	if info, ok := root["info"]; ok {
+		if err := convert(info, &spec.Info); err != nil {
+			return nil, fmt.Errorf("invalid info: %v", err)
+		}
+	}
+	if servers, ok := root["servers"]; ok {
+		if err := convert(servers, &spec.Servers); err != nil {
+			return nil, fmt.Errorf("invalid servers: %v", err)
+		}
+	}
+	paths, ok := root["paths"]
+	if !ok || paths == nil {
+		return spec, nil
+	}
+	pathItems, ok := paths.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("paths is not an object")
+	}
+	for path, item := range pathItems {
+		pathItem, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("path item %s is not an object", path)
+		}
+		var pathParams []Parameter
+		if params, ok := pathItem["parameters"]; ok {
+			if err := convert(params, &pathParams); err != nil {
+				return nil, fmt.Errorf("invalid parameters for path %s: %v", path, err)
+			}
+		}
+		operations := make(map[string]*Operation)
+		for _, method := range httpMethods {
+			op, ok := pathItem[method]
+			if !ok {
+				continue
+			}
+			var operation Operation
+			if err := convert(op, &operation); err != nil {
+				return nil, fmt.Errorf("invalid operation %s %s: %v", strings.ToUpper(method), path, err)
+			}
+			operation.Parameters = mergeParameters(pathParams, operation.Parameters)
+			operations[method] = &operation
+		}
+		spec.Paths[path] = operations
+	}
+	return spec, nil
+}
+
+// mergeParameters adds the parameters declared for a path to those of
+// an operation, unless the operation overrides them.
+func mergeParameters(pathParams []Parameter, opParams []Parameter) []Parameter {
+	merged := opParams
+	for _, pathParam := range pathParams {
+		overridden := false
+		for _, opParam := range opParams {
+			if opParam.Name == pathParam.Name && opParam.In == pathParam.In {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			merged = append(merged, pathParam)
+		}
+	}
+	return merged
+}
+
+// serverBasePath returns the path of a server URL, without a trailing
+// slash. The URL may be relative, or contain variables such as {scheme}.
+func serverBasePath(serverUrl string) string {
+	basePath := serverUrl
+	if _, afterScheme, ok := strings.Cut(serverUrl, "://"); ok {
+		slash := strings.Index(afterScheme, "/")
+		if slash < 0 {
+			return ""
+		}
+		basePath = afterScheme[slash:]
+	}
+	basePath, _, _ = strings.Cut(basePath, "?")
+	basePath, _, _ = strings.Cut(basePath, "#")
+	return strings.TrimSuffix(basePath, "/")
 }
@@ -1,9 +1,13 @@
 package openapi
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestParse(t *testing.T) {
@@ -155,3 +159,148 @@ info:
 		})
 	}
 }
+
+func TestParse_references(t *testing.T) {
+	spec, err := Parse("testdata/pet_store_refs.yaml")
+	require.NoError(t, err)
+	require.Equal(t, "3.0.1", spec.Version)
+	require.Equal(t, "/api", spec.BasePath())
+
+	op := spec.Paths["/pets/{petId}"]["get"]
+	require.NotNil(t, op)
+	require.Equal(t, "getPet", op.OperationId)
+
+	require.Len(t, op.Parameters, 2, "path parameters should be added to the operation")
+	petId := op.Parameters[1]
+	require.Equal(t, "petId", petId.Name)
+	require.Equal(t, "path", petId.In)
+	example, ok := petId.ExampleValue()
+	require.True(t, ok)
+	require.Equal(t, "42", example)
+
+	pet := op.Responses["200"].Content["application/json"].Schema
+	require.NotNil(t, pet)
+	require.Equal(t, "object", pet.Type.Primary())
+	require.Equal(t, "Fluffy", pet.Properties["name"].Example, "file-relative reference should be resolved")
+	require.Equal(t, []any{"cat", "dog"}, pet.Properties["tags"].Items.Enum)
+
+	owner := pet.Properties["owner"]
+	require.Equal(t, "Alice", owner.Properties["name"].Example)
+	require.Empty(t, owner.Properties["pets"].Items.Properties, "circular reference should be cut")
+
+	notFound := op.Responses["404"]
+	require.Equal(t, "not found", notFound.Description, "local reference should be resolved")
+	example, ok = notFound.Content["text/plain"].ExampleValue()
+	require.True(t, ok)
+	require.Equal(t, "not found", example)
+}
+
+func TestParse_missingReference(t *testing.T) {
+	specFile := filepath.Join(t.TempDir(), "spec.yaml")
+	require.NoError(t, os.WriteFile(specFile, []byte(`
+openapi: 3.0.0
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          $ref: "missing.yaml#/Response"
+`), 0644))
+	_, err := Parse(specFile)
+	require.ErrorContains(t, err, "missing.yaml")
+}
+
+func TestParse_swagger2(t *testing.T) {
+	specFile := filepath.Join(t.TempDir(), "swagger.yaml")
+	require.NoError(t, os.WriteFile(specFile, []byte(`
+swagger: "2.0"
+host: example.com
+basePath: /v2
+schemes: [http]
+produces: [application/json]
+paths:
+  /pets:
+    post:
+      consumes: [application/xml]
+      parameters:
+        - name: body
+          in: body
+          required: true
+          schema:
+            $ref: "#/definitions/Pet"
+        - name: limit
+          in: query
+          required: true
+          type: integer
+          maximum: 100
+          x-example: 10
+      responses:
+        "201":
+          description: created
+          headers:
+            Location:
+              type: string
+          schema:
+            $ref: "#/definitions/Pet"
+          examples:
+            application/json:
+              name: Fluffy
+  /pets/{petId}:
+    put:
+      parameters:
+        - name: petId
+          in: path
+          required: true
+          type: string
+        - name: name
+          in: formData
+          required: true
+          type: string
+      responses:
+        "204":
+          description: updated
+definitions:
+  Pet:
+    type: object
+    properties:
+      name:
+        type: string
+`), 0644))
+
+	spec, err := Parse(specFile)
+	require.NoError(t, err)
+	require.Equal(t, "2.0", spec.Version)
+	require.Equal(t, []Server{{Url: "http://example.com/v2"}}, spec.Servers)
+
+	post := spec.Paths["/pets"]["post"]
+	require.NotNil(t, post)
+	require.Len(t, post.Parameters, 1, "body parameter should become the request body")
+	limit := post.Parameters[0]
+	require.Equal(t, "integer", limit.Schema.Type.Primary())
+	require.Equal(t, 100.0, *limit.Schema.Maximum)
+	example, _ := limit.ExampleValue()
+	require.Equal(t, "10", fmt.Sprint(example))
+
+	require.True(t, post.RequestBody.Required)
+	require.Equal(t, "object", post.RequestBody.Content["application/xml"].Schema.Type.Primary())
+
+	created := post.Responses["201"]
+	require.Equal(t, "string", created.Headers["Location"].Schema.Type.Primary())
+	mediaType := created.Content["application/json"]
+	require.Equal(t, "string", mediaType.Schema.Properties["name"].Type.Primary())
+	example, _ = mediaType.ExampleValue()
+	require.Equal(t, map[string]any{"name": "Fluffy"}, example)
+
+	put := spec.Paths["/pets/{petId}"]["put"]
+	form := put.RequestBody.Content["application/x-www-form-urlencoded"].Schema
+	require.Equal(t, []string{"name"}, form.Required)
+	require.Empty(t, put.Responses["204"].Content)
+}
+
+func TestSchemaType_openapi31(t *testing.T) {
+	var schema Schema
+	require.NoError(t, json.Unmarshal([]byte(`{"type":["null","string"]}`), &schema))
+	require.Equal(t, "string", schema.Type.Primary())
+	require.NoError(t, json.Unmarshal([]byte(`{"type":"integer"}`), &schema))
+	require.Equal(t, SchemaType{"integer"}, schema.Type)
+}
@@ -0,0 +1,224 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// refResolver replaces each $ref object in a document with the value it
+// refers to. References may be local, such as #/components/schemas/Pet,
+// or relative to the file containing them, such as common.yaml#/Pet.
+type refResolver struct {
+	// documents holds each parsed document, keyed by absolute file path.
+	documents map[string]any
+
+	// files lists the documents in the order they were loaded.
+	files []string
+
+	// resolved holds the value of each reference, keyed by absolute
+	// file path and JSON pointer.
+	resolved   map[string]any
+	inProgress map[string]bool
+}
+
+func newRefResolver() *refResolver {
+	return &refResolver{
+		documents:  make(map[string]any),
+		resolved:   make(map[string]any),
+		inProgress: make(map[string]bool),
+	}
+}
+
+// load parses the JSON or YAML document at the absolute file path,
+// unless it has already been loaded.
+func (r *refResolver) load(file string) (any, error) {
+	if doc, ok := r.documents[file]; ok {
+		return doc, nil
+	}
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	jsonContent, err := yaml.YAMLToJSON(raw)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", file, err)
+	}
+	var doc any
+	if err := decodeJson(jsonContent, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", file, err)
+	}
+	r.documents[file] = doc
+	r.files = append(r.files, file)
+	return doc, nil
+}
+
+// resolve returns a copy of the value, in which each $ref object is
+// replaced by the value it refers to. A reference that refers back to
+// itself, such as in a recursive schema, is replaced by an empty object.
+func (r *refResolver) resolve(value any, file string) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		if ref, ok := v["$ref"].(string); ok {
+			return r.resolveRef(ref, v, file)
+		}
+		resolved := make(map[string]any, len(v))
+		for key, item := range v {
+			resolvedItem, err := r.resolve(item, file)
+			if err != nil {
+				return nil, err
+			}
+			resolved[key] = resolvedItem
+		}
+		return resolved, nil
+	case []any:
+		resolved := make([]any, len(v))
+		for i, item := range v {
+			resolvedItem, err := r.resolve(item, file)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = resolvedItem
+		}
+		return resolved, nil
+	default:
+		return v, nil
+	}
+}
+
+func (r *refResolver) resolveRef(ref string, refObject map[string]any, file string) (any, error) {
+	refFile, pointer, _ := strings.Cut(ref, "#")
+	target := file
+	if refFile != "" {
+		if strings.Contains(refFile, "://") {
+			return nil, fmt.Errorf("remote reference %s in %s is not supported", ref, file)
+		}
+		refFile, err := url.PathUnescape(refFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reference %s in %s: %v", ref, file, err)
+		}
+		target = filepath.FromSlash(refFile)
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(file), target)
+		}
+	}
+
+	key := target + "#" + pointer
+	if resolved, ok := r.resolved[key]; ok {
+		return withSiblings(resolved, refObject), nil
+	}
+	if r.inProgress[key] {
+		logger.Tracef("circular reference %s in %s", ref, file)
+		return map[string]any{}, nil
+	}
+
+	doc, err := r.load(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load reference %s in %s: %v", ref, file, err)
+	}
+	value, err := lookupPointer(doc, pointer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve reference %s in %s: %v", ref, file, err)
+	}
+
+	r.inProgress[key] = true
+	resolved, err := r.resolve(value, target)
+	delete(r.inProgress, key)
+	if err != nil {
+		return nil, err
+	}
+	r.resolved[key] = resolved
+	return withSiblings(resolved, refObject), nil
+}
+
+// withSiblings adds any members alongside $ref, such as a description,
+// to a copy of the resolved object.
+func withSiblings(resolved any, refObject map[string]any) any {
+	object, ok := resolved.(map[string]any)
+	if !ok || len(refObject) == 1 {
+		return resolved
+	}
+	merged := make(map[string]any, len(object)+len(refObject))
+	for key, value := range object {
+		merged[key] = value
+	}
+	for key, value := range refObject {
+		if key != "$ref" {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// lookupPointer returns the value at the JSON pointer, such as
+// /components/schemas/Pet, within the document.
+func lookupPointer(doc any, pointer string) (any, error) {
+	if pointer == "" || pointer == "/" {
+		return doc, nil
+	}
+	current := doc
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if unescaped, err := url.PathUnescape(token); err == nil {
+			token = unescaped
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch v := current.(type) {
+		case map[string]any:
+			value, ok := v[token]
+			if !ok {
+				return nil, fmt.Errorf("no member %s", token)
+			}
+			current = value
+		case []any:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, fmt.Errorf("no item %s", token)
+			}
+			current = v[index]
+		default:
+			return nil, fmt.Errorf("cannot resolve %s in a scalar value", token)
+		}
+	}
+	return current, nil
+}
+
+// decodeJson decodes JSON, retaining numbers as json.Number,
+// so that example values are written as they appear in the spec.
+func decodeJson(content []byte, target any) error {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	return decoder.Decode(target)
+}
+
+// convert decodes a generic value, such as a parsed document,
+// into the target type.
+func convert(value any, target any) error {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return decodeJson(content, target)
+}
@@ -0,0 +1,278 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"fmt"
+	"sort"
+)
+
+const defaultSwagger2MediaType = "application/json"
+
+// schemaMembers are the members of a Swagger 2 non-body parameter or
+// header that describe its value, and so form its schema in OpenAPI 3.
+var schemaMembers = []string{
+	"type", "format", "items", "enum", "default", "minimum", "maximum",
+	"exclusiveMinimum", "exclusiveMaximum", "multipleOf", "minLength",
+	"maxLength", "pattern", "minItems", "maxItems", "uniqueItems",
+}
+
+// convertSwagger2 converts a Swagger 2 specification, whose references
+// have been resolved, to the shape of an OpenAPI 3 specification. The
+// 'swagger' version is retained as the 'openapi' member.
+func convertSwagger2(root map[string]any) map[string]any {
+	converted := map[string]any{
+		"openapi": fmt.Sprint(root["swagger"]),
+	}
+	if serverUrl := swagger2ServerUrl(root); serverUrl != "" {
+		converted["servers"] = []any{map[string]any{"url": serverUrl}}
+	}
+
+	produces := stringList(root["produces"], defaultSwagger2MediaType)
+	consumes := stringList(root["consumes"], defaultSwagger2MediaType)
+
+	paths, ok := root["paths"].(map[string]any)
+	if !ok {
+		return converted
+	}
+	convertedPaths := make(map[string]any, len(paths))
+	for path, item := range paths {
+		pathItem, ok := item.(map[string]any)
+		if !ok {
+			convertedPaths[path] = item
+			continue
+		}
+		convertedItem := make(map[string]any)
+		if params, ok := pathItem["parameters"].([]any); ok {
+			// body parameters for a path cannot be converted to a request body here,
+			// as the media types are those of the operation
+			convertedItem["parameters"] = convertSwagger2Parameters(params)
+		}
+		for _, method := range httpMethods {
+			op, ok := pathItem[method].(map[string]any)
+			if !ok {
+				continue
+			}
+			convertedItem[method] = convertSwagger2Operation(op, pathItem, produces, consumes)
+		}
+		convertedPaths[path] = convertedItem
+	}
+	converted["paths"] = convertedPaths
+	return converted
+}
+
+func swagger2ServerUrl(root map[string]any) string {
+	host, _ := root["host"].(string)
+	basePath, _ := root["basePath"].(string)
+	if host == "" {
+		return basePath
+	}
+	scheme := "https"
+	if schemes := stringList(root["schemes"], ""); len(schemes) > 0 && schemes[0] != "" {
+		scheme = schemes[0]
+	}
+	return scheme + "://" + host + basePath
+}
+
+func convertSwagger2Operation(op map[string]any, pathItem map[string]any, produces []string, consumes []string) map[string]any {
+	converted := make(map[string]any)
+	for _, member := range []string{"operationId", "summary", "description"} {
+		if value, ok := op[member]; ok {
+			converted[member] = value
+		}
+	}
+	opProduces := stringList(op["produces"], produces...)
+	opConsumes := stringList(op["consumes"], consumes...)
+
+	// body and form parameters may be declared for the path or the operation
+	var allParams []any
+	if pathParams, ok := pathItem["parameters"].([]any); ok {
+		allParams = append(allParams, pathParams...)
+	}
+	if opParams, ok := op["parameters"].([]any); ok {
+		allParams = append(allParams, opParams...)
+		converted["parameters"] = convertSwagger2Parameters(opParams)
+	}
+	if requestBody := convertSwagger2RequestBody(allParams, opConsumes); requestBody != nil {
+		converted["requestBody"] = requestBody
+	}
+
+	if responses, ok := op["responses"].(map[string]any); ok {
+		convertedResponses := make(map[string]any, len(responses))
+		for status, resp := range responses {
+			if response, ok := resp.(map[string]any); ok {
+				convertedResponses[status] = convertSwagger2Response(response, opProduces)
+			}
+		}
+		converted["responses"] = convertedResponses
+	}
+	return converted
+}
+
+// convertSwagger2Parameters converts the path, query and header
+// parameters. Body and form parameters are converted to a request body.
+func convertSwagger2Parameters(params []any) []any {
+	var converted []any
+	for _, p := range params {
+		param, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		in, _ := param["in"].(string)
+		if in == "body" || in == "formData" {
+			continue
+		}
+		convertedParam := map[string]any{
+			"name":   param["name"],
+			"in":     in,
+			"schema": swagger2Schema(param),
+		}
+		if required, ok := param["required"]; ok {
+			convertedParam["required"] = required
+		}
+		if example, ok := param["x-example"]; ok {
+			convertedParam["example"] = example
+		}
+		converted = append(converted, convertedParam)
+	}
+	return converted
+}
+
+func convertSwagger2RequestBody(params []any, consumes []string) map[string]any {
+	formSchema := map[string]any{"type": "object", "properties": map[string]any{}}
+	var formRequired []any
+	var body map[string]any
+	for _, p := range params {
+		param, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		switch param["in"] {
+		case "body":
+			body = param
+		case "formData":
+			name := fmt.Sprint(param["name"])
+			formSchema["properties"].(map[string]any)[name] = swagger2Schema(param)
+			if required, _ := param["required"].(bool); required {
+				formRequired = append(formRequired, name)
+			}
+		}
+	}
+
+	content := make(map[string]any)
+	required := false
+	if body != nil {
+		for _, mediaType := range consumes {
+			content[mediaType] = map[string]any{"schema": body["schema"]}
+		}
+		required, _ = body["required"].(bool)
+	} else if len(formSchema["properties"].(map[string]any)) > 0 {
+		if len(formRequired) > 0 {
+			formSchema["required"] = formRequired
+		}
+		mediaType := "application/x-www-form-urlencoded"
+		for _, candidate := range consumes {
+			if candidate == "multipart/form-data" {
+				mediaType = candidate
+			}
+		}
+		content[mediaType] = map[string]any{"schema": formSchema}
+		required = len(formRequired) > 0
+	} else {
+		return nil
+	}
+	return map[string]any{"required": required, "content": content}
+}
+
+func convertSwagger2Response(response map[string]any, produces []string) map[string]any {
+	converted := map[string]any{
+		"description": response["description"],
+	}
+	if headers, ok := response["headers"].(map[string]any); ok {
+		convertedHeaders := make(map[string]any, len(headers))
+		for name, h := range headers {
+			if header, ok := h.(map[string]any); ok {
+				convertedHeaders[name] = map[string]any{"schema": swagger2Schema(header)}
+			}
+		}
+		converted["headers"] = convertedHeaders
+	}
+
+	schema, hasSchema := response["schema"]
+	examples, _ := response["examples"].(map[string]any)
+	if !hasSchema && len(examples) == 0 {
+		return converted
+	}
+	content := make(map[string]any)
+	for _, mediaType := range produces {
+		mediaTypeObject := map[string]any{}
+		if hasSchema {
+			mediaTypeObject["schema"] = schema
+		}
+		content[mediaType] = mediaTypeObject
+	}
+	// examples are keyed by media type, which may not be listed in 'produces'
+	var exampleTypes []string
+	for mediaType := range examples {
+		exampleTypes = append(exampleTypes, mediaType)
+	}
+	sort.Strings(exampleTypes)
+	for _, mediaType := range exampleTypes {
+		mediaTypeObject, ok := content[mediaType].(map[string]any)
+		if !ok {
+			mediaTypeObject = map[string]any{}
+			if hasSchema {
+				mediaTypeObject["schema"] = schema
+			}
+			content[mediaType] = mediaTypeObject
+		}
+		mediaTypeObject["example"] = examples[mediaType]
+	}
+	converted["content"] = content
+	return converted
+}
+
+// swagger2Schema builds a schema from the members of a Swagger 2
+// non-body parameter or header.
+func swagger2Schema(param map[string]any) map[string]any {
+	schema := make(map[string]any)
+	for _, member := range schemaMembers {
+		if value, ok := param[member]; ok {
+			schema[member] = value
+		}
+	}
+	return schema
+}
+
+// stringList returns the strings in a list value, or the defaults
+// if the value is not a list of strings.
+func stringList(value any, defaults ...string) []string {
+	list, ok := value.([]any)
+	if !ok {
+		return defaults
+	}
+	var values []string
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			values = append(values, s)
+		}
+	}
+	if len(values) == 0 {
+		return defaults
+	}
+	return values
+}
@@ -0,0 +1,288 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/imposter-project/imposter-cli/internal/fileutil"
+	"github.com/imposter-project/imposter-cli/internal/impostermodel"
+	"github.com/imposter-project/imposter-cli/internal/logging"
+)
+
+var logger = logging.GetLogger()
+
+// ProviderStateHeaderName is the request header that selects
+// the interactions for a provider state.
+const ProviderStateHeaderName = "X-Provider-State"
+
+// ProviderConfig holds the resources for the interactions with a provider.
+type ProviderConfig struct {
+	Provider string
+
+	// BaseName is the provider name, safe for use in file names.
+	BaseName string
+
+	Resources []impostermodel.Resource
+}
+
+type converter struct {
+	outputDir      string
+	forceOverwrite bool
+	issues         impostermodel.ImportIssues
+	fileNames      map[string]bool
+}
+
+// Convert translates each interaction in the pacts to a resource for the
+// Imposter REST plugin, grouped by provider, in the order the providers
+// appear. Response bodies are written to files in a directory named after
+// the provider, within the output directory.
+//
+// Interactions are matched by method, path, query, headers and body.
+// Interactions that require provider states are selected by the
+// ProviderStateHeaderName header, holding the names of the states.
+//
+// Returns the configuration for each provider, along with the issues found
+// translating the interactions.
+func Convert(pacts []*Pact, outputDir string, forceOverwrite bool) ([]ProviderConfig, []impostermodel.ImportIssue, error) {
+	c := &converter{
+		outputDir:      outputDir,
+		forceOverwrite: forceOverwrite,
+		fileNames:      make(map[string]bool),
+	}
+	var configs []ProviderConfig
+	indices := make(map[string]int)
+	for _, pact := range pacts {
+		index, ok := indices[pact.Provider.Name]
+		if !ok {
+			index = len(configs)
+			indices[pact.Provider.Name] = index
+			configs = append(configs, ProviderConfig{
+				Provider: pact.Provider.Name,
+				BaseName: fileutil.SanitiseFileName(pact.Provider.Name, "provider"),
+			})
+		}
+		for _, interaction := range pact.Interactions {
+			source := filepath.Base(pact.File) + ": " + interaction.Description
+			resource, err := c.convertInteraction(interaction, source, configs[index].BaseName+"-responses")
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to convert interaction %s: %v", source, err)
+			}
+			configs[index].Resources = append(configs[index].Resources, *resource)
+		}
+	}
+	return configs, c.issues, nil
+}
+
+func (c *converter) convertInteraction(interaction Interaction, source string, responseDir string) (*impostermodel.Resource, error) {
+	req := interaction.Request
+	rules := parseMatchingRules(req.MatchingRules)
+
+	resource := &impostermodel.Resource{
+		Path:   req.Path,
+		Method: strings.ToUpper(req.Method),
+		Response: &impostermodel.ResponseConfig{
+			StatusCode: interaction.Response.Status,
+		},
+	}
+	if resource.Response.StatusCode == 0 {
+		resource.Response.StatusCode = 200
+	}
+	if rules.path {
+		c.issues.Report(source, "path matching rules are not supported - matching path %s", req.Path)
+	}
+
+	if len(req.Query) > 0 {
+		queryParams := make(map[string]string)
+		for _, name := range slices.Sorted(maps.Keys(req.Query)) {
+			if rules.query[name] {
+				c.issues.Report(source, "matching rules for query parameter %s are not supported - ignored", name)
+				continue
+			}
+			values := req.Query[name]
+			if len(values) > 1 {
+				c.issues.Report(source, "query parameter %s has several values - matching the first", name)
+			}
+			if len(values) > 0 {
+				queryParams[name] = values[0]
+			}
+		}
+		if len(queryParams) > 0 {
+			resource.QueryParams = &queryParams
+		}
+	}
+
+	requestHeaders := make(map[string]string)
+	for _, name := range slices.Sorted(maps.Keys(req.Headers)) {
+		if rules.headers[strings.ToLower(name)] {
+			c.issues.Report(source, "matching rules for header %s are not supported - ignored", name)
+			continue
+		}
+		requestHeaders[name] = req.Headers[name]
+	}
+	if states := stateNames(interaction); len(states) > 0 {
+		requestHeaders[ProviderStateHeaderName] = strings.Join(states, ", ")
+	}
+	if len(requestHeaders) > 0 {
+		resource.RequestHeaders = &requestHeaders
+	}
+
+	if len(req.Body) > 0 && string(req.Body) != "null" {
+		var text string
+		if rules.body {
+			c.issues.Report(source, "matching rules for the request body are not supported - body is not matched")
+		} else if err := json.Unmarshal(req.Body, &text); err != nil {
+			// clients rarely send JSON formatted exactly as in the pact
+			c.issues.Report(source, "JSON request bodies are not matched exactly, as clients may format them differently - body is not matched")
+		} else {
+			resource.RequestBody = &impostermodel.RequestBody{Operator: "EqualTo", Value: text}
+		}
+	}
+
+	if len(interaction.Response.Headers) > 0 {
+		headers := map[string]string(interaction.Response.Headers)
+		resource.Response.Headers = &headers
+	}
+	if err := c.convertResponseBody(interaction, responseDir, resource.Response); err != nil {
+		return nil, err
+	}
+	return resource, nil
+}
+
+// stateNames returns the names of the provider states of the interaction.
+func stateNames(interaction Interaction) []string {
+	if interaction.ProviderState != "" {
+		return []string{interaction.ProviderState}
+	}
+	var names []string
+	for _, state := range interaction.ProviderStates {
+		names = append(names, state.Name)
+	}
+	return names
+}
+
+// convertResponseBody writes the body of the response to a file, formatting
+// JSON bodies, and sets the file of the response.
+func (c *converter) convertResponseBody(interaction Interaction, responseDir string, response *impostermodel.ResponseConfig) error {
+	body := interaction.Response.Body
+	if len(body) == 0 || string(body) == "null" {
+		return nil
+	}
+	var content []byte
+	extension := ""
+	var text string
+	if err := json.Unmarshal(body, &text); err == nil {
+		content = []byte(text)
+	} else {
+		var formatted bytes.Buffer
+		if err := json.Indent(&formatted, body, "", "  "); err != nil {
+			return fmt.Errorf("invalid response body: %v", err)
+		}
+		content = formatted.Bytes()
+		extension = ".json"
+	}
+	for name, value := range interaction.Response.Headers {
+		if strings.EqualFold(name, "Content-Type") {
+			if extension == "" {
+				extension = fileutil.ExtensionForMediaType(value)
+			}
+		}
+	}
+	if extension == "" {
+		extension = ".txt"
+	}
+
+	baseName := fileutil.SanitiseFileName(interaction.Description, "interaction")
+	fileName := baseName + extension
+	for i := 2; c.fileNames[filepath.Join(responseDir, fileName)]; i++ {
+		fileName = fmt.Sprintf("%s-%d%s", baseName, i, extension)
+	}
+	relFile := filepath.Join(responseDir, fileName)
+	c.fileNames[relFile] = true
+
+	file := filepath.Join(c.outputDir, relFile)
+	fileutil.MustNotExist(file, c.forceOverwrite)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, content, 0644); err != nil {
+		return fmt.Errorf("failed to write response file: %s: %v", file, err)
+	}
+	logger.Debugf("wrote response file: %s", file)
+	response.File = filepath.ToSlash(relFile)
+	return nil
+}
+
+// matchingRules identifies the parts of a request that have matching
+// rules, rather than being matched exactly.
+type matchingRules struct {
+	path    bool
+	body    bool
+	query   map[string]bool
+	headers map[string]bool
+}
+
+// parseMatchingRules reads v2 matching rules, keyed by paths such as
+// '$.headers.Accept', and v3 matching rules, keyed by category.
+func parseMatchingRules(raw json.RawMessage) matchingRules {
+	rules := matchingRules{query: make(map[string]bool), headers: make(map[string]bool)}
+	var members map[string]json.RawMessage
+	if len(raw) == 0 || json.Unmarshal(raw, &members) != nil {
+		return rules
+	}
+	for key, value := range members {
+		if strings.HasPrefix(key, "$.") {
+			category, name := strings.TrimPrefix(key, "$."), ""
+			if i := strings.IndexAny(category, ".["); i >= 0 {
+				category, name = category[:i], category[i:]
+			}
+			rules.add(category, strings.Trim(name, `.[]'"`))
+			continue
+		}
+		var names map[string]json.RawMessage
+		_ = json.Unmarshal(value, &names)
+		switch key {
+		case "query", "header":
+			for name := range names {
+				rules.add(key, name)
+			}
+		default:
+			rules.add(key, "")
+		}
+	}
+	return rules
+}
+
+func (r *matchingRules) add(category string, name string) {
+	switch category {
+	case "path":
+		r.path = true
+	case "body":
+		r.body = true
+	case "query":
+		r.query[name] = true
+	case "header", "headers":
+		r.headers[strings.ToLower(name)] = true
+	}
+}
@@ -0,0 +1,81 @@
+package pact
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/imposter-project/imposter-cli/internal/impostermodel"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseMatchingRules(t *testing.T) {
+	v2 := parseMatchingRules(json.RawMessage(`{
+  "$.path": {"match": "regex", "regex": "/pets/[0-9]+"},
+  "$.query.name": {"match": "type"},
+  "$.headers['X-Request.Id']": {"match": "type"},
+  "$.body.items[0].name": {"match": "type"}
+}`))
+	require.True(t, v2.path)
+	require.True(t, v2.body)
+	require.Equal(t, map[string]bool{"name": true}, v2.query)
+	require.Equal(t, map[string]bool{"x-request.id": true}, v2.headers)
+
+	v3 := parseMatchingRules(json.RawMessage(`{
+  "query": {"name": {"matchers": [{"match": "type"}]}},
+  "header": {"Accept": {"matchers": [{"match": "regex", "regex": ".*json"}]}}
+}`))
+	require.False(t, v3.path)
+	require.False(t, v3.body)
+	require.Equal(t, map[string]bool{"name": true}, v3.query)
+	require.Equal(t, map[string]bool{"accept": true}, v3.headers)
+}
+
+func TestParse(t *testing.T) {
+	dir := t.TempDir()
+	v3File := filepath.Join(dir, "v3.json")
+	require.NoError(t, os.WriteFile(v3File, []byte(`{
+  "consumer": {"name": "web"},
+  "provider": {"name": "pets"},
+  "interactions": [{
+    "description": "list pets",
+    "request": {"method": "GET", "path": "/pets", "query": {"tag": ["a", "b"]}, "headers": {"Accept": ["application/json", "text/plain"]}},
+    "response": {"status": 200}
+  }],
+  "metadata": {"pactSpecification": {"version": "3.0.0"}}
+}`), 0644))
+	pact, err := Parse(v3File)
+	require.NoError(t, err)
+	require.Equal(t, "3", pact.Version())
+	require.Equal(t, Query{"tag": {"a", "b"}}, pact.Interactions[0].Request.Query)
+	require.Equal(t, "application/json, text/plain", pact.Interactions[0].Request.Headers["Accept"])
+
+	v4File := filepath.Join(dir, "v4.json")
+	require.NoError(t, os.WriteFile(v4File, []byte(`{
+  "consumer": {"name": "web"},
+  "provider": {"name": "pets"},
+  "interactions": [],
+  "metadata": {"pactSpecification": {"version": "4.0"}}
+}`), 0644))
+	_, err = Parse(v4File)
+	require.ErrorContains(t, err, "unsupported Pact specification version 4")
+}
+
+func TestConvert_requestBody(t *testing.T) {
+	pact := &Pact{
+		File:     "web-pets.json",
+		Provider: Pacticipant{Name: "pets"},
+		Interactions: []Interaction{
+			{Description: "create pet", Request: Request{Method: "POST", Path: "/pets", Body: json.RawMessage(`{"name": "Rex"}`)}},
+			{Description: "send note", Request: Request{Method: "POST", Path: "/notes", Body: json.RawMessage(`"hello"`)}},
+		},
+	}
+	configs, issues, err := Convert([]*Pact{pact}, t.TempDir(), false)
+	require.NoError(t, err)
+	resources := configs[0].Resources
+	require.Nil(t, resources[0].RequestBody, "JSON request bodies should not be matched")
+	require.Equal(t, &impostermodel.RequestBody{Operator: "EqualTo", Value: "hello"}, resources[1].RequestBody)
+	require.Len(t, issues, 1)
+	require.Contains(t, issues[0].Message, "JSON request bodies are not matched")
+}
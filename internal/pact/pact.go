@@ -0,0 +1,165 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pact models Pact v2 and v3 contract files.
+// See https://github.com/pact-foundation/pact-specification
+package pact
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+type Pact struct {
+	Consumer     Pacticipant   `json:"consumer"`
+	Provider     Pacticipant   `json:"provider"`
+	Interactions []Interaction `json:"interactions"`
+	Metadata     Metadata      `json:"metadata"`
+
+	// File is the file the pact was read from.
+	File string `json:"-"`
+}
+
+type Pacticipant struct {
+	Name string `json:"name"`
+}
+
+type Metadata struct {
+	PactSpecification        *SpecificationVersion `json:"pactSpecification"`
+	LegacyPactSpecification  *SpecificationVersion `json:"pact-specification"`
+	PactSpecificationVersion string                `json:"pactSpecificationVersion"`
+}
+
+type SpecificationVersion struct {
+	Version string `json:"version"`
+}
+
+type Interaction struct {
+	Description string `json:"description"`
+
+	// ProviderState is the state of the provider in v2 pacts.
+	ProviderState string `json:"providerState"`
+
+	// ProviderStates are the states of the provider in v3 pacts.
+	ProviderStates []ProviderState `json:"providerStates"`
+
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type ProviderState struct {
+	Name   string         `json:"name"`
+	Params map[string]any `json:"params"`
+}
+
+type Request struct {
+	Method        string          `json:"method"`
+	Path          string          `json:"path"`
+	Query         Query           `json:"query"`
+	Headers       Headers         `json:"headers"`
+	Body          json.RawMessage `json:"body"`
+	MatchingRules json.RawMessage `json:"matchingRules"`
+}
+
+type Response struct {
+	Status        int             `json:"status"`
+	Headers       Headers         `json:"headers"`
+	Body          json.RawMessage `json:"body"`
+	MatchingRules json.RawMessage `json:"matchingRules"`
+}
+
+// Query holds the query parameters of a request, which are a query
+// string in v2 pacts, and a map of names to values in v3 pacts.
+type Query map[string][]string
+
+func (q *Query) UnmarshalJSON(data []byte) error {
+	var queryString string
+	if err := json.Unmarshal(data, &queryString); err == nil {
+		values, err := url.ParseQuery(queryString)
+		if err != nil {
+			return fmt.Errorf("invalid query: %s: %v", queryString, err)
+		}
+		*q = Query(values)
+		return nil
+	}
+	var values map[string][]string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*q = values
+	return nil
+}
+
+// Headers holds header values, which may be a list of values in v3 pacts.
+type Headers map[string]string
+
+func (h *Headers) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*h = make(Headers, len(raw))
+	for name, rawValue := range raw {
+		var value string
+		var values []string
+		if err := json.Unmarshal(rawValue, &value); err == nil {
+			(*h)[name] = value
+		} else if err := json.Unmarshal(rawValue, &values); err == nil {
+			(*h)[name] = strings.Join(values, ", ")
+		} else {
+			return fmt.Errorf("invalid value for header %s: %s", name, rawValue)
+		}
+	}
+	return nil
+}
+
+// Version returns the major version of the Pact specification of the
+// pact, defaulting to 2 if the metadata does not declare it.
+func (p *Pact) Version() string {
+	version := p.Metadata.PactSpecificationVersion
+	if p.Metadata.PactSpecification != nil {
+		version = p.Metadata.PactSpecification.Version
+	} else if p.Metadata.LegacyPactSpecification != nil {
+		version = p.Metadata.LegacyPactSpecification.Version
+	}
+	major, _, _ := strings.Cut(version, ".")
+	if major == "" || major == "1" {
+		return "2"
+	}
+	return major
+}
+
+// Parse reads a v2 or v3 pact file.
+func Parse(pactFile string) (*Pact, error) {
+	data, err := os.ReadFile(pactFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pact file: %s: %v", pactFile, err)
+	}
+	pact := &Pact{File: pactFile}
+	if err := json.Unmarshal(data, pact); err != nil {
+		return nil, fmt.Errorf("failed to parse pact file: %s: %v", pactFile, err)
+	}
+	if version := pact.Version(); version != "2" && version != "3" {
+		return nil, fmt.Errorf("unsupported Pact specification version %s in pact file: %s - only v2 and v3 are supported", version, pactFile)
+	}
+	if pact.Provider.Name == "" {
+		return nil, fmt.Errorf("no provider name in pact file: %s", pactFile)
+	}
+	return pact, nil
+}
@@ -0,0 +1,153 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package postman models Postman v2.0 and v2.1 collections.
+// See https://schema.postman.com/
+package postman
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+type Collection struct {
+	Info     Info       `json:"info"`
+	Item     []Item     `json:"item"`
+	Variable []Variable `json:"variable"`
+}
+
+type Info struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+// Item is a folder, which holds other items, or a request,
+// which holds the examples saved for the request.
+type Item struct {
+	Name     string     `json:"name"`
+	Item     []Item     `json:"item"`
+	Request  *Request   `json:"request"`
+	Response []Example  `json:"response"`
+	Variable []Variable `json:"variable"`
+}
+
+type Request struct {
+	Method string     `json:"method"`
+	URL    URL        `json:"url"`
+	Header HeaderList `json:"header"`
+}
+
+// URL is a request URL, which is either a string or an object.
+type URL struct {
+	Raw      string     `json:"raw"`
+	Protocol string     `json:"protocol"`
+	Host     StringList `json:"host"`
+	Path     StringList `json:"path"`
+	Query    []KeyValue `json:"query"`
+	Variable []Variable `json:"variable"`
+}
+
+func (u *URL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		*u = URL{Raw: raw}
+		return nil
+	}
+	type plainURL URL
+	return json.Unmarshal(data, (*plainURL)(u))
+}
+
+// StringList is a list of strings, which may be given as a single
+// string, such as the host or path of a URL.
+type StringList []string
+
+func (l *StringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = strings.Split(strings.TrimPrefix(single, "/"), "/")
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*l = multiple
+	return nil
+}
+
+// HeaderList is a list of headers, which may be given as a string of
+// lines in the form 'Name: value'.
+type HeaderList []KeyValue
+
+func (l *HeaderList) UnmarshalJSON(data []byte) error {
+	var lines string
+	if err := json.Unmarshal(data, &lines); err == nil {
+		*l = nil
+		for _, line := range strings.Split(lines, "\n") {
+			if name, value, ok := strings.Cut(line, ":"); ok {
+				*l = append(*l, KeyValue{Key: strings.TrimSpace(name), Value: strings.TrimSpace(value)})
+			}
+		}
+		return nil
+	}
+	var headers []KeyValue
+	if err := json.Unmarshal(data, &headers); err != nil {
+		return err
+	}
+	*l = headers
+	return nil
+}
+
+type KeyValue struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled"`
+}
+
+type Variable struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+}
+
+// Example is a response saved for a request, along with
+// the request that produced it.
+type Example struct {
+	Name            string     `json:"name"`
+	OriginalRequest *Request   `json:"originalRequest"`
+	Status          string     `json:"status"`
+	Code            int        `json:"code"`
+	Header          HeaderList `json:"header"`
+	Body            string     `json:"body"`
+	PreviewLanguage string     `json:"_postman_previewlanguage"`
+}
+
+// Parse reads a Postman collection file.
+func Parse(collectionFile string) (*Collection, error) {
+	data, err := os.ReadFile(collectionFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Postman collection: %s: %v", collectionFile, err)
+	}
+	var collection Collection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, fmt.Errorf("failed to parse Postman collection: %s: %v", collectionFile, err)
+	}
+	if collection.Info.Schema != "" && !strings.Contains(collection.Info.Schema, "/v2.") {
+		return nil, fmt.Errorf("unsupported Postman collection schema: %s - export the collection as v2.1", collection.Info.Schema)
+	}
+	return &collection, nil
+}
@@ -0,0 +1,370 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postman
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/imposter-project/imposter-cli/internal/fileutil"
+	"github.com/imposter-project/imposter-cli/internal/impostermodel"
+	"github.com/imposter-project/imposter-cli/internal/logging"
+	"github.com/imposter-project/imposter-cli/internal/stringutil"
+)
+
+var logger = logging.GetLogger()
+
+var variablePattern = regexp.MustCompile(`\{\{([^{}]+)}}`)
+
+// maxVariableDepth limits the resolution of variables
+// whose values refer to other variables.
+const maxVariableDepth = 10
+
+// skipResponseHeaders are not added to responses, as they describe
+// the original response, rather than the content of the example.
+var skipResponseHeaders = []string{
+	"Connection",
+	"Content-Encoding",
+	"Content-Length",
+	"Date",
+	"Keep-Alive",
+	"Server",
+	"Transfer-Encoding",
+}
+
+// previewLanguageExtensions maps the languages Postman
+// uses to display example bodies to file extensions.
+var previewLanguageExtensions = map[string]string{
+	"json": ".json",
+	"xml":  ".xml",
+	"html": ".html",
+	"text": ".txt",
+}
+
+type converter struct {
+	configDir      string
+	responseDir    string
+	forceOverwrite bool
+	issues         impostermodel.ImportIssues
+	fileNames      map[string]bool
+
+	// defaults holds the first resource for each request,
+	// keyed by method, path and query.
+	defaults map[string]*exampleResource
+}
+
+type exampleResource struct {
+	source   string
+	resource *impostermodel.Resource
+}
+
+// Convert translates each example saved in the collection to a resource for
+// the Imposter REST plugin, walking the folders of the collection in order.
+// Variables are resolved using the variables of the collection and of the
+// folders containing each request.
+//
+// The bodies of the examples are written to files in the responseDir, which
+// is relative to the configDir, in which the configuration is written.
+//
+// If several examples are saved for the same request, such as for a success
+// and an error, the first is returned by default and the others are selected
+// by the status trigger header, such as 'X-Mock-Status: 404'.
+//
+// Returns the resources, along with the issues found translating them.
+func Convert(collection *Collection, configDir string, responseDir string, forceOverwrite bool) ([]impostermodel.Resource, []impostermodel.ImportIssue, error) {
+	c := &converter{
+		configDir:      configDir,
+		responseDir:    responseDir,
+		forceOverwrite: forceOverwrite,
+		fileNames:      make(map[string]bool),
+		defaults:       make(map[string]*exampleResource),
+	}
+	var examples []*exampleResource
+	if err := c.convertItems(collection.Item, nil, withVariables(nil, collection.Variable), &examples); err != nil {
+		return nil, nil, err
+	}
+	resources := make([]impostermodel.Resource, 0, len(examples))
+	for _, example := range examples {
+		resources = append(resources, *example.resource)
+	}
+	return resources, c.issues, nil
+}
+
+func (c *converter) convertItems(items []Item, folders []string, variables map[string]string, examples *[]*exampleResource) error {
+	for _, item := range items {
+		names := append(append([]string{}, folders...), item.Name)
+		itemVariables := withVariables(variables, item.Variable)
+		if item.Request == nil {
+			if err := c.convertItems(item.Item, names, itemVariables, examples); err != nil {
+				return err
+			}
+			continue
+		}
+		source := strings.Join(names, " / ")
+		if len(item.Response) == 0 {
+			c.issues.Report(source, "no saved examples - skipped request")
+			continue
+		}
+		for _, example := range item.Response {
+			exampleSource := source + " / " + example.Name
+			resource, err := c.convertExample(item, example, exampleSource, itemVariables)
+			if err != nil {
+				return fmt.Errorf("failed to convert Postman example %s: %v", exampleSource, err)
+			}
+			if resource != nil {
+				*examples = append(*examples, resource)
+			}
+		}
+	}
+	return nil
+}
+
+// withVariables returns the variables, overridden by the additional variables.
+func withVariables(variables map[string]string, additional []Variable) map[string]string {
+	combined := make(map[string]string, len(variables)+len(additional))
+	for key, value := range variables {
+		combined[key] = value
+	}
+	for _, variable := range additional {
+		if variable.Value != nil {
+			combined[variable.Key] = fmt.Sprint(variable.Value)
+		}
+	}
+	return combined
+}
+
+func (c *converter) convertExample(item Item, example Example, source string, variables map[string]string) (*exampleResource, error) {
+	request := example.OriginalRequest
+	if request == nil {
+		request = item.Request
+	}
+	resolve := func(value string) string {
+		return c.resolve(source, value, variables)
+	}
+
+	method := strings.ToUpper(resolve(request.Method))
+	if method == "" {
+		method = "GET"
+	}
+	resource := &impostermodel.Resource{
+		Method:   method,
+		Response: &impostermodel.ResponseConfig{StatusCode: example.Code},
+	}
+	if resource.Response.StatusCode == 0 {
+		resource.Response.StatusCode = 200
+	}
+	if err := c.convertUrl(source, request.URL, resolve, resource); err != nil {
+		return nil, err
+	}
+
+	headers := make(map[string]string)
+	for _, header := range example.Header {
+		if !header.Disabled && !stringutil.Contains(skipResponseHeaders, http.CanonicalHeaderKey(header.Key)) {
+			headers[header.Key] = resolve(header.Value)
+		}
+	}
+	if len(headers) > 0 {
+		resource.Response.Headers = &headers
+	}
+
+	if example.Body != "" {
+		file, err := c.writeBodyFile(item.Name+"-"+example.Name, example, headers)
+		if err != nil {
+			return nil, err
+		}
+		resource.Response.File = file
+	}
+
+	converted := &exampleResource{source: source, resource: resource}
+	c.selectByStatus(converted)
+	return converted, nil
+}
+
+// resolve replaces the variables in the value, reporting those without a value.
+func (c *converter) resolve(source string, value string, variables map[string]string) string {
+	for depth := 0; depth < maxVariableDepth && variablePattern.MatchString(value); depth++ {
+		resolved := variablePattern.ReplaceAllStringFunc(value, func(ref string) string {
+			if variable, ok := variables[strings.TrimSpace(ref[2:len(ref)-2])]; ok {
+				return variable
+			}
+			return ref
+		})
+		if resolved == value {
+			break
+		}
+		value = resolved
+	}
+	for _, match := range variablePattern.FindAllStringSubmatch(value, -1) {
+		c.issues.Report(source, "variable %s has no value", match[1])
+	}
+	return value
+}
+
+// convertUrl sets the path and query parameters of the resource. Path
+// variables, such as ':id', and unresolved variables that make up a path
+// segment, are matched as path parameters. An unresolved base URL
+// variable, such as '{{baseUrl}}', is dropped.
+func (c *converter) convertUrl(source string, requestUrl URL, resolve func(string) string, resource *impostermodel.Resource) error {
+	raw := requestUrl.Raw
+	if raw == "" {
+		raw = buildRawUrl(requestUrl)
+	}
+	raw = resolve(raw)
+	if strings.HasPrefix(raw, "{{") {
+		if end := strings.Index(raw, "}}"); end >= 0 {
+			raw = raw[end+2:]
+		}
+	}
+	if !strings.Contains(raw, "://") {
+		if !strings.HasPrefix(raw, "/") {
+			raw = "/" + raw
+		}
+		raw = "http://localhost" + raw
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %s: %v", raw, err)
+	}
+
+	pathVariables := make(map[string]string)
+	for _, variable := range requestUrl.Variable {
+		if variable.Value != nil {
+			pathVariables[variable.Key] = resolve(fmt.Sprint(variable.Value))
+		}
+	}
+	segments := strings.Split(parsed.Path, "/")
+	pathParams := make(map[string]string)
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok && name != "" {
+			segments[i] = "{" + name + "}"
+			if value := pathVariables[name]; value != "" {
+				pathParams[name] = value
+			}
+		} else if match := variablePattern.FindStringSubmatch(segment); match != nil && match[0] == segment {
+			segments[i] = "{" + strings.TrimSpace(match[1]) + "}"
+		}
+	}
+	resource.Path = strings.Join(segments, "/")
+	if resource.Path == "" {
+		resource.Path = "/"
+	}
+	if len(pathParams) > 0 {
+		resource.PathParams = &pathParams
+	}
+
+	if query := parsed.Query(); len(query) > 0 {
+		queryParams := make(map[string]string)
+		for name, values := range query {
+			queryParams[name] = values[0]
+			if len(values) > 1 {
+				c.issues.Report(source, "query parameter %s has several values - matching the first", name)
+			}
+		}
+		resource.QueryParams = &queryParams
+	}
+	return nil
+}
+
+// buildRawUrl builds a URL from its parts, for URLs without a raw form.
+func buildRawUrl(requestUrl URL) string {
+	var raw strings.Builder
+	if requestUrl.Protocol != "" {
+		raw.WriteString(requestUrl.Protocol + "://")
+	}
+	raw.WriteString(strings.Join(requestUrl.Host, "."))
+	raw.WriteString("/" + strings.Join(requestUrl.Path, "/"))
+	var query []string
+	for _, param := range requestUrl.Query {
+		if !param.Disabled {
+			query = append(query, param.Key+"="+param.Value)
+		}
+	}
+	if len(query) > 0 {
+		raw.WriteString("?" + strings.Join(query, "&"))
+	}
+	return raw.String()
+}
+
+// selectByStatus makes the resource the default for its request, if it is
+// the first, otherwise it is selected by the status trigger header.
+func (c *converter) selectByStatus(example *exampleResource) {
+	resource := example.resource
+	key := resource.Method + " " + resource.Path
+	if resource.QueryParams != nil {
+		var query []string
+		for name, value := range *resource.QueryParams {
+			query = append(query, name+"="+value)
+		}
+		sort.Strings(query)
+		key += "?" + strings.Join(query, "&")
+	}
+
+	first, ok := c.defaults[key]
+	if !ok {
+		c.defaults[key] = example
+		return
+	}
+	if first.resource.Response.StatusCode == resource.Response.StatusCode {
+		c.issues.Report(example.source, "same request and status as example %s - it will not be matched", first.source)
+		return
+	}
+	resource.RequestHeaders = &map[string]string{
+		impostermodel.StatusTriggerHeaderName: strconv.Itoa(resource.Response.StatusCode),
+	}
+}
+
+// writeBodyFile writes the body of the example to a file in the response
+// directory, returning its path relative to the configuration directory.
+func (c *converter) writeBodyFile(name string, example Example, headers map[string]string) (string, error) {
+	extension := previewLanguageExtensions[example.PreviewLanguage]
+	for key, value := range headers {
+		if strings.EqualFold(key, "Content-Type") {
+			if mediaTypeExtension := fileutil.ExtensionForMediaType(value); mediaTypeExtension != "" {
+				extension = mediaTypeExtension
+			}
+		}
+	}
+	if extension == "" {
+		extension = ".txt"
+	}
+
+	baseName := fileutil.SanitiseFileName(name, "")
+	fileName := baseName + extension
+	for i := 2; c.fileNames[fileName]; i++ {
+		fileName = fmt.Sprintf("%s-%d%s", baseName, i, extension)
+	}
+	c.fileNames[fileName] = true
+
+	relFile := filepath.Join(c.responseDir, fileName)
+	file := filepath.Join(c.configDir, relFile)
+	fileutil.MustNotExist(file, c.forceOverwrite)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(file, []byte(example.Body), 0644); err != nil {
+		return "", fmt.Errorf("failed to write response file: %s: %v", file, err)
+	}
+	logger.Debugf("wrote response file: %s", file)
+	return filepath.ToSlash(relFile), nil
+}
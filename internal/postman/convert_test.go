@@ -0,0 +1,42 @@
+package postman
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert_unresolvedVariables(t *testing.T) {
+	var collection Collection
+	require.NoError(t, json.Unmarshal([]byte(`{
+  "info": {"name": "Users"},
+  "variable": [{"key": "usersPath", "value": "{{prefix}}/users"}, {"key": "prefix", "value": "/api"}],
+  "item": [{
+    "name": "List users",
+    "request": {"method": "get", "url": "{{baseUrl}}{{usersPath}}/{{tenant}}"},
+    "response": [
+      {"name": "First", "code": 200},
+      {"name": "Second", "code": 200}
+    ]
+  }]
+}`), &collection))
+
+	resources, issues, err := Convert(&collection, t.TempDir(), "responses", false)
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+	require.Equal(t, "GET", resources[0].Method)
+	require.Equal(t, "/api/users/{tenant}", resources[0].Path, "variables should be resolved recursively")
+
+	var messages []string
+	for _, issue := range issues {
+		messages = append(messages, issue.String())
+	}
+	require.Equal(t, []string{
+		"List users / First: variable baseUrl has no value",
+		"List users / First: variable tenant has no value",
+		"List users / Second: variable baseUrl has no value",
+		"List users / Second: variable tenant has no value",
+		"List users / Second: same request and status as example List users / First - it will not be matched",
+	}, messages)
+}
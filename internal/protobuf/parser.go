@@ -0,0 +1,75 @@
+package protobuf
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Parse compiles the given proto files. Imports are resolved relative
+// to the directory containing each file, then the given import paths,
+// then the standard well-known types, such as google/protobuf/timestamp.proto.
+func Parse(protoFiles []string, importPaths ...string) ([]protoreflect.FileDescriptor, error) {
+	var dirs []string
+	var names []string
+	for _, protoFile := range protoFiles {
+		absPath, err := filepath.Abs(protoFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve proto file path: %s: %v", protoFile, err)
+		}
+		dirs = appendUnique(dirs, filepath.Dir(absPath))
+		names = append(names, filepath.Base(absPath))
+	}
+	for _, importPath := range importPaths {
+		dirs = appendUnique(dirs, importPath)
+	}
+
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: dirs}),
+	}
+	files, err := compiler.Compile(context.Background(), names...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse proto files: %v", err)
+	}
+
+	descriptors := make([]protoreflect.FileDescriptor, 0, len(files))
+	for _, file := range files {
+		descriptors = append(descriptors, file)
+	}
+	return descriptors, nil
+}
+
+// FindMethod returns the method for a gRPC request path, in the
+// form '/package.Service/Method', or nil if no such method exists.
+func FindMethod(files []protoreflect.FileDescriptor, rpcPath string) protoreflect.MethodDescriptor {
+	serviceName, methodName, ok := strings.Cut(strings.TrimPrefix(rpcPath, "/"), "/")
+	if !ok {
+		return nil
+	}
+	for _, file := range files {
+		services := file.Services()
+		for i := 0; i < services.Len(); i++ {
+			service := services.Get(i)
+			if string(service.FullName()) != serviceName {
+				continue
+			}
+			if method := service.Methods().ByName(protoreflect.Name(methodName)); method != nil {
+				return method
+			}
+		}
+	}
+	return nil
+}
+
+func appendUnique(entries []string, entry string) []string {
+	for _, existing := range entries {
+		if existing == entry {
+			return entries
+		}
+	}
+	return append(entries, entry)
+}
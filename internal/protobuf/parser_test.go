@@ -0,0 +1,28 @@
+package protobuf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	files, err := Parse([]string{"testdata/store.proto"})
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, "store.proto", files[0].Path())
+
+	method := FindMethod(files, "/store.PetStore/GetPet")
+	require.NotNil(t, method, "method should be found")
+	require.Equal(t, "store.Pet", string(method.Output().FullName()))
+	require.NotNil(t, method.Output().Fields().ByName("born"), "imported message should be resolved")
+
+	require.Nil(t, FindMethod(files, "/store.PetStore/Missing"))
+	require.Nil(t, FindMethod(files, "/store.Missing/GetPet"))
+	require.Nil(t, FindMethod(files, "invalid"))
+}
+
+func TestParse_invalid(t *testing.T) {
+	_, err := Parse([]string{"testdata/missing.proto"})
+	require.Error(t, err)
+}
@@ -0,0 +1,191 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protobuf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/imposter-project/imposter-cli/internal/openapi"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// SampleGenerator builds sample JSON messages for the output of gRPC
+// methods, in the JSON form of the protobuf messages. Values are generated
+// from the field types, using the seed, so the same seed produces the same
+// messages.
+type SampleGenerator struct {
+	fake *openapi.FakeDataGenerator
+}
+
+func NewSampleGenerator(seed int64) *SampleGenerator {
+	return &SampleGenerator{fake: openapi.NewFakeDataGenerator(seed)}
+}
+
+// Response returns a sample response for the method. The response for
+// a server streaming method is a JSON array containing a single message,
+// otherwise it is a JSON object.
+func (g *SampleGenerator) Response(method protoreflect.MethodDescriptor) ([]byte, error) {
+	messageJson, err := g.message(method.Output())
+	if err != nil {
+		return nil, fmt.Errorf("failed to build output of method %s: %v", method.FullName(), err)
+	}
+	if method.IsStreamingServer() {
+		messageJson = append(append([]byte("["), messageJson...), ']')
+	}
+	var formatted bytes.Buffer
+	if err := json.Indent(&formatted, messageJson, "", "  "); err != nil {
+		return nil, err
+	}
+	return append(formatted.Bytes(), '\n'), nil
+}
+
+// message generates a value for the message type, then round trips it
+// through a dynamic message, so the result is canonical protobuf JSON.
+func (g *SampleGenerator) message(messageType protoreflect.MessageDescriptor) ([]byte, error) {
+	value := g.fake.Generate(messageSchema(messageType, make(map[protoreflect.FullName]bool)))
+	generated, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	message := dynamicpb.NewMessage(messageType)
+	if err := protojson.Unmarshal(generated, message); err != nil {
+		return nil, fmt.Errorf("invalid %s message: %v", messageType.FullName(), err)
+	}
+	return protojson.MarshalOptions{EmitDefaultValues: true}.Marshal(message)
+}
+
+// messageSchema returns a schema for the JSON form of the message type.
+// Fields of types already being visited are omitted, so recursive
+// messages terminate, as are all but the first field of each oneof.
+func messageSchema(messageType protoreflect.MessageDescriptor, visiting map[protoreflect.FullName]bool) *openapi.Schema {
+	if schema, ok := wellKnownSchema(messageType, visiting); ok {
+		return schema
+	}
+	visiting[messageType.FullName()] = true
+	defer delete(visiting, messageType.FullName())
+
+	schema := &openapi.Schema{Type: openapi.SchemaType{"object"}, Properties: make(map[string]*openapi.Schema)}
+	fields := messageType.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if oneof := field.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() && oneof.Fields().Get(0) != field {
+			continue
+		}
+		if fieldSchema := fieldSchema(field, visiting); fieldSchema != nil {
+			schema.Properties[field.JSONName()] = fieldSchema
+		}
+	}
+	return schema
+}
+
+// fieldSchema returns a schema for the field, or nil if it should be omitted.
+func fieldSchema(field protoreflect.FieldDescriptor, visiting map[protoreflect.FullName]bool) *openapi.Schema {
+	if field.IsMap() {
+		valueSchema := singularSchema(field.MapValue(), visiting)
+		if valueSchema == nil {
+			return nil
+		}
+		// keys of other kinds must parse as their kind, so use a fixed key
+		key := "key"
+		switch field.MapKey().Kind() {
+		case protoreflect.StringKind:
+		case protoreflect.BoolKind:
+			key = "true"
+		default:
+			key = "1"
+		}
+		return &openapi.Schema{Type: openapi.SchemaType{"object"}, Properties: map[string]*openapi.Schema{key: valueSchema}}
+	}
+	itemSchema := singularSchema(field, visiting)
+	if itemSchema == nil || !field.IsList() {
+		return itemSchema
+	}
+	return &openapi.Schema{Type: openapi.SchemaType{"array"}, Items: itemSchema}
+}
+
+func singularSchema(field protoreflect.FieldDescriptor, visiting map[protoreflect.FullName]bool) *openapi.Schema {
+	switch field.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if visiting[field.Message().FullName()] {
+			return nil
+		}
+		return messageSchema(field.Message(), visiting)
+	case protoreflect.EnumKind:
+		return enumSchema(field.Enum())
+	case protoreflect.BoolKind:
+		return &openapi.Schema{Type: openapi.SchemaType{"boolean"}}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return &openapi.Schema{Type: openapi.SchemaType{"integer"}}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return &openapi.Schema{Type: openapi.SchemaType{"number"}}
+	case protoreflect.BytesKind:
+		return &openapi.Schema{Type: openapi.SchemaType{"string"}, Format: "byte"}
+	default:
+		return &openapi.Schema{Type: openapi.SchemaType{"string"}}
+	}
+}
+
+// enumSchema returns a schema for the names of the enum values. The zero
+// value is excluded if there are others, as it conventionally means
+// the value is unspecified.
+func enumSchema(enum protoreflect.EnumDescriptor) *openapi.Schema {
+	if enum.FullName() == "google.protobuf.NullValue" {
+		return nil
+	}
+	var names []any
+	values := enum.Values()
+	for i := 0; i < values.Len(); i++ {
+		if value := values.Get(i); value.Number() != 0 || values.Len() == 1 {
+			names = append(names, string(value.Name()))
+		}
+	}
+	return &openapi.Schema{Enum: names}
+}
+
+// wellKnownSchema returns a schema for the JSON form of well-known types,
+// which differs from that of other messages. Returns a nil schema for
+// types which cannot be generated, such as Any.
+func wellKnownSchema(messageType protoreflect.MessageDescriptor, visiting map[protoreflect.FullName]bool) (*openapi.Schema, bool) {
+	switch messageType.FullName() {
+	case "google.protobuf.Timestamp":
+		return &openapi.Schema{Type: openapi.SchemaType{"string"}, Format: "date-time"}, true
+	case "google.protobuf.Duration":
+		return &openapi.Schema{Example: "30s"}, true
+	case "google.protobuf.FieldMask":
+		return &openapi.Schema{Example: "name"}, true
+	case "google.protobuf.Struct":
+		return &openapi.Schema{Type: openapi.SchemaType{"object"}, AdditionalProperties: json.RawMessage(`{"type":"string"}`)}, true
+	case "google.protobuf.Value":
+		return &openapi.Schema{Type: openapi.SchemaType{"string"}}, true
+	case "google.protobuf.ListValue":
+		return &openapi.Schema{Type: openapi.SchemaType{"array"}, Items: &openapi.Schema{Type: openapi.SchemaType{"string"}}}, true
+	case "google.protobuf.Any":
+		return nil, true
+	case "google.protobuf.DoubleValue", "google.protobuf.FloatValue",
+		"google.protobuf.Int64Value", "google.protobuf.UInt64Value",
+		"google.protobuf.Int32Value", "google.protobuf.UInt32Value",
+		"google.protobuf.BoolValue", "google.protobuf.StringValue", "google.protobuf.BytesValue":
+		return singularSchema(messageType.Fields().ByName("value"), visiting), true
+	}
+	return nil, false
+}
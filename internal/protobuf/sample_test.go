@@ -0,0 +1,50 @@
+package protobuf
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampleGenerator_Response(t *testing.T) {
+	files, err := Parse([]string{"testdata/sample.proto"})
+	require.NoError(t, err)
+
+	method := FindMethod(files, "/sample.Samples/GetSample")
+	require.NotNil(t, method)
+	response, err := NewSampleGenerator(1).Response(method)
+	require.NoError(t, err)
+
+	var sample map[string]any
+	require.NoError(t, json.Unmarshal(response, &sample))
+	require.IsType(t, "", sample["id"], "64-bit integers should be strings")
+	require.Equal(t, "KIND_SMALL", sample["kind"], "the zero value of an enum should not be chosen")
+	require.NotEmpty(t, sample["tags"])
+	require.Contains(t, sample["petsById"], "1")
+	require.Contains(t, sample["petsById"].(map[string]any)["1"], "born", "imported messages should be generated")
+	require.Contains(t, sample, "text")
+	require.NotContains(t, sample, "number", "only one field of a oneof should be generated")
+	require.NotContains(t, sample, "parent", "recursive fields should be omitted")
+	require.Equal(t, "30s", sample["ttl"])
+	require.IsType(t, "", sample["nickname"])
+	require.Contains(t, sample, "active", "default values should be included")
+
+	again, err := NewSampleGenerator(1).Response(method)
+	require.NoError(t, err)
+	require.Equal(t, string(response), string(again), "the same seed should produce the same response")
+}
+
+func TestSampleGenerator_Response_streaming(t *testing.T) {
+	files, err := Parse([]string{"testdata/sample.proto"})
+	require.NoError(t, err)
+
+	method := FindMethod(files, "/sample.Samples/WatchSamples")
+	require.NotNil(t, method)
+	response, err := NewSampleGenerator(1).Response(method)
+	require.NoError(t, err)
+
+	var samples []map[string]any
+	require.NoError(t, json.Unmarshal(response, &samples))
+	require.Len(t, samples, 1)
+}
@@ -0,0 +1,55 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import "bytes"
+
+// cappedBuffer retains up to max bytes written to it, discarding the
+// remainder. Writes never fail, so it can be used with io.TeeReader
+// without interrupting the stream. A max of zero means no limit.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	max       int64
+	written   int64
+	truncated bool
+}
+
+func newCappedBuffer(max int64) *cappedBuffer {
+	return &cappedBuffer{max: max}
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.written += int64(len(p))
+	if b.max <= 0 {
+		return b.buf.Write(p)
+	}
+	remaining := b.max - int64(b.buf.Len())
+	if int64(len(p)) > remaining {
+		b.truncated = true
+		if remaining > 0 {
+			b.buf.Write(p[:remaining])
+		}
+		return len(p), nil
+	}
+	b.buf.Write(p)
+	return len(p), nil
+}
+
+// Bytes returns the retained bytes.
+func (b *cappedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
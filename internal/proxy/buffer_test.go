@@ -0,0 +1,27 @@
+package proxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_cappedBuffer(t *testing.T) {
+	unlimited := newCappedBuffer(0)
+	_, _ = unlimited.Write([]byte("hello "))
+	_, _ = unlimited.Write([]byte("world"))
+	require.Equal(t, "hello world", string(unlimited.Bytes()))
+	require.False(t, unlimited.truncated)
+
+	capped := newCappedBuffer(8)
+	n, err := capped.Write([]byte("hello "))
+	require.NoError(t, err)
+	require.Equal(t, 6, n)
+	n, err = capped.Write([]byte("world"))
+	require.NoError(t, err)
+	require.Equal(t, 5, n, "writes should report the full length, so streams are not interrupted")
+	_, _ = capped.Write([]byte("!"))
+	require.Equal(t, "hello wo", string(capped.Bytes()))
+	require.True(t, capped.truncated)
+	require.Equal(t, int64(12), capped.written)
+}
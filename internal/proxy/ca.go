@@ -0,0 +1,186 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const caCertFileName = "ca.crt"
+const caKeyFileName = "ca.key"
+
+// CertificateAuthority issues certificates for the hosts the proxy
+// terminates TLS for. Clients must trust the CA certificate in order
+// to connect to the proxy without certificate errors.
+type CertificateAuthority struct {
+	CertFile string
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	mutex    sync.Mutex
+	leaves   map[string]*tls.Certificate
+}
+
+// LoadOrCreateCA loads the CA certificate and key from the given dir,
+// generating and persisting a new CA if none exists.
+func LoadOrCreateCA(dir string) (*CertificateAuthority, error) {
+	certFile := filepath.Join(dir, caCertFileName)
+	keyFile := filepath.Join(dir, caKeyFileName)
+
+	if _, err := os.Stat(certFile); os.IsNotExist(err) {
+		if err := generateCA(dir, certFile, keyFile); err != nil {
+			return nil, err
+		}
+		logger.Infof("generated proxy CA certificate: %s", certFile)
+	}
+
+	keyPair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load proxy CA from %s: %v", dir, err)
+	}
+	cert, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse proxy CA certificate: %s: %v", certFile, err)
+	}
+	key, ok := keyPair.PrivateKey.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported proxy CA key type in: %s", keyFile)
+	}
+	return &CertificateAuthority{
+		CertFile: certFile,
+		cert:     cert,
+		key:      key,
+		leaves:   make(map[string]*tls.Certificate),
+	}, nil
+}
+
+func generateCA(dir string, certFile string, keyFile string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create proxy CA dir: %s: %v", dir, err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate proxy CA key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          newSerialNumber(),
+		Subject:               pkix.Name{CommonName: "Imposter Proxy CA", Organization: []string{"Imposter"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return fmt.Errorf("failed to create proxy CA certificate: %v", err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("failed to marshal proxy CA key: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		return fmt.Errorf("failed to write proxy CA key: %s: %v", keyFile, err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		return fmt.Errorf("failed to write proxy CA certificate: %s: %v", certFile, err)
+	}
+	return nil
+}
+
+// CertPool returns a pool containing the CA certificate.
+func (ca *CertificateAuthority) CertPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return pool
+}
+
+// CertificateFor returns a certificate for the given host, signed by
+// the CA. Certificates are cached for the lifetime of the CA.
+func (ca *CertificateAuthority) CertificateFor(host string) (*tls.Certificate, error) {
+	ca.mutex.Lock()
+	defer ca.mutex.Unlock()
+	if leaf, ok := ca.leaves[host]; ok {
+		return leaf, nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key for host %s: %v", host, err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: newSerialNumber(),
+		Subject:      pkix.Name{CommonName: host, Organization: []string{"Imposter"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(1, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else {
+		template.DNSNames = []string{host}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate for host %s: %v", host, err)
+	}
+	leaf := &tls.Certificate{
+		Certificate: [][]byte{der, ca.cert.Raw},
+		PrivateKey:  key,
+	}
+	ca.leaves[host] = leaf
+	logger.Debugf("issued proxy certificate for host %s", host)
+	return leaf, nil
+}
+
+// TLSConfig returns a server configuration that presents a certificate
+// for the host requested by the client via SNI, falling back to the
+// given default host if the client does not send SNI. Both HTTP/2, as
+// required by gRPC, and HTTP/1.1 are offered.
+func (ca *CertificateAuthority) TLSConfig(defaultHost string) *tls.Config {
+	return &tls.Config{
+		NextProtos: []string{"h2", "http/1.1"},
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			host := hello.ServerName
+			if host == "" {
+				host = defaultHost
+			}
+			return ca.CertificateFor(host)
+		},
+	}
+}
+
+func newSerialNumber() *big.Int {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		panic(fmt.Errorf("failed to generate certificate serial number: %v", err))
+	}
+	return serial
+}
@@ -0,0 +1,36 @@
+package proxy
+
+import (
+	"crypto/x509"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadOrCreateCA(t *testing.T) {
+	caDir := t.TempDir()
+
+	ca, err := LoadOrCreateCA(caDir)
+	require.NoError(t, err)
+	require.FileExists(t, ca.CertFile)
+
+	reloaded, err := LoadOrCreateCA(caDir)
+	require.NoError(t, err)
+	require.Equal(t, ca.cert.Raw, reloaded.cert.Raw, "existing CA should be reused")
+
+	for _, host := range []string{"example.com", "127.0.0.1"} {
+		t.Run(host, func(t *testing.T) {
+			leaf, err := reloaded.CertificateFor(host)
+			require.NoError(t, err)
+
+			parsed, err := x509.ParseCertificate(leaf.Certificate[0])
+			require.NoError(t, err)
+			_, err = parsed.Verify(x509.VerifyOptions{DNSName: host, Roots: ca.CertPool()})
+			require.NoError(t, err, "certificate should be trusted by the original CA")
+
+			cached, err := reloaded.CertificateFor(host)
+			require.NoError(t, err)
+			require.Same(t, leaf, cached)
+		})
+	}
+}
@@ -0,0 +1,235 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imposter-project/imposter-cli/internal/fileutil"
+	"sigs.k8s.io/yaml"
+)
+
+// ChaosConfig is the format of the chaos rules file.
+type ChaosConfig struct {
+	Rules []ChaosRule `json:"rules"`
+}
+
+// ChaosRule injects a fault or latency into a percentage of the requests
+// matching its method and path. An empty method or path matches any
+// request. Paths may contain '*' wildcards, which match within a single
+// segment, or end with '/**' to match any path beneath a prefix.
+type ChaosRule struct {
+	Method string `json:"method,omitempty"`
+	Path   string `json:"path,omitempty"`
+
+	// Percentage of matching requests to which the rule applies. If
+	// not set, the rule applies to all matching requests.
+	Percentage *float64 `json:"percentage,omitempty"`
+
+	// Latency, such as '500ms', delays the request before it is sent
+	// upstream. If MaxLatency is also set, the delay is chosen at random
+	// between the two.
+	Latency    string `json:"latency,omitempty"`
+	MaxLatency string `json:"maxLatency,omitempty"`
+
+	// Drop closes the client connection without a response.
+	Drop bool `json:"drop,omitempty"`
+
+	// Status, if set, is returned to the client, with the optional
+	// Body, instead of forwarding the request upstream.
+	Status int    `json:"status,omitempty"`
+	Body   string `json:"body,omitempty"`
+
+	latency    time.Duration
+	maxLatency time.Duration
+}
+
+// Chaos injects faults and latency into proxied requests, according
+// to rules loaded from a YAML file.
+type Chaos struct {
+	file   string
+	mutex  sync.RWMutex
+	rules  []ChaosRule
+	random func() float64
+}
+
+// LoadChaos loads the chaos rules from the given YAML file.
+func LoadChaos(file string) (*Chaos, error) {
+	c := &Chaos{file: file, random: rand.Float64}
+	if err := c.Reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Reload replaces the rules with the current content of the file.
+// If the file is invalid, the existing rules are retained.
+func (c *Chaos) Reload() error {
+	content, err := os.ReadFile(c.file)
+	if err != nil {
+		return fmt.Errorf("failed to read chaos rules file %s: %v", c.file, err)
+	}
+	rules, err := parseChaosRules(content)
+	if err != nil {
+		return fmt.Errorf("invalid chaos rules file %s: %v", c.file, err)
+	}
+	c.mutex.Lock()
+	c.rules = rules
+	c.mutex.Unlock()
+	logger.Infof("loaded %d chaos rule(s) from %s", len(rules), c.file)
+	return nil
+}
+
+// WatchForChanges reloads the rules whenever the file changes, so they
+// can be adjusted without restarting the proxy.
+func (c *Chaos) WatchForChanges() {
+	updatedC := fileutil.WatchFile(c.file)
+	go func() {
+		for {
+			<-updatedC
+			if err := c.Reload(); err != nil {
+				logger.Warnf("%v - keeping previous rules", err)
+			}
+		}
+	}()
+}
+
+func parseChaosRules(content []byte) ([]ChaosRule, error) {
+	var config ChaosConfig
+	if err := yaml.UnmarshalStrict(content, &config); err != nil {
+		return nil, err
+	}
+	for i := range config.Rules {
+		rule := &config.Rules[i]
+		if rule.Percentage != nil && (*rule.Percentage < 0 || *rule.Percentage > 100) {
+			return nil, fmt.Errorf("rule %d: percentage must be between 0 and 100", i+1)
+		}
+		if rule.Drop && rule.Status != 0 {
+			return nil, fmt.Errorf("rule %d: drop and status cannot both be set", i+1)
+		}
+		if rule.Status != 0 && (rule.Status < 100 || rule.Status > 599) {
+			return nil, fmt.Errorf("rule %d: invalid status %d", i+1, rule.Status)
+		}
+		if rule.Path != "" {
+			if _, err := path.Match(rule.Path, "/"); err != nil {
+				return nil, fmt.Errorf("rule %d: invalid path pattern %q: %v", i+1, rule.Path, err)
+			}
+		}
+		var err error
+		if rule.Latency != "" {
+			if rule.latency, err = time.ParseDuration(rule.Latency); err != nil {
+				return nil, fmt.Errorf("rule %d: invalid latency: %v", i+1, err)
+			}
+		}
+		if rule.MaxLatency != "" {
+			if rule.maxLatency, err = time.ParseDuration(rule.MaxLatency); err != nil {
+				return nil, fmt.Errorf("rule %d: invalid maxLatency: %v", i+1, err)
+			}
+			if rule.maxLatency < rule.latency {
+				return nil, fmt.Errorf("rule %d: maxLatency must not be less than latency", i+1)
+			}
+		}
+		if rule.latency == 0 && rule.maxLatency == 0 && !rule.Drop && rule.Status == 0 {
+			return nil, fmt.Errorf("rule %d: one of latency, drop or status must be set", i+1)
+		}
+	}
+	return config.Rules, nil
+}
+
+func (r *ChaosRule) matches(req *http.Request) bool {
+	if r.Method != "" && !strings.EqualFold(r.Method, req.Method) {
+		return false
+	}
+	if r.Path == "" {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(r.Path, "/**"); ok {
+		return req.URL.Path == prefix || strings.HasPrefix(req.URL.Path, prefix+"/")
+	}
+	matched, _ := path.Match(r.Path, req.URL.Path)
+	return matched
+}
+
+// Inject applies the matching rules to the request. Latency from each
+// applicable rule is added together, then the first fault, if any, is
+// sent to the client. It returns true if a fault was injected, in which
+// case the request must not be forwarded.
+func (c *Chaos) Inject(w http.ResponseWriter, req *http.Request) bool {
+	c.mutex.RLock()
+	rules := c.rules
+	c.mutex.RUnlock()
+
+	var delay time.Duration
+	var fault *ChaosRule
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.matches(req) {
+			continue
+		}
+		if rule.Percentage != nil && c.random()*100 >= *rule.Percentage {
+			continue
+		}
+		delay += rule.latency
+		if rule.maxLatency > rule.latency {
+			delay += time.Duration(c.random() * float64(rule.maxLatency-rule.latency))
+		}
+		if fault == nil && (rule.Drop || rule.Status != 0) {
+			fault = rule
+		}
+	}
+
+	if delay > 0 {
+		logger.Debugf("chaos: delaying %s %v by %v", req.Method, req.URL, delay)
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return true
+		}
+	}
+	if fault == nil {
+		return false
+	}
+	if fault.Drop {
+		logger.Infof("chaos: dropping connection for %s %v", req.Method, req.URL)
+		dropConnection(w)
+	} else {
+		logger.Infof("chaos: responding to %s %v with status %d", req.Method, req.URL, fault.Status)
+		w.WriteHeader(fault.Status)
+		_, _ = w.Write([]byte(fault.Body))
+	}
+	return true
+}
+
+// dropConnection closes the client connection without sending a response.
+// Connections that cannot be hijacked, such as HTTP/2 streams, are reset
+// by aborting the handler.
+func dropConnection(w http.ResponseWriter) {
+	if hijacker, ok := w.(http.Hijacker); ok {
+		if conn, _, err := hijacker.Hijack(); err == nil {
+			_ = conn.Close()
+			return
+		}
+	}
+	panic(http.ErrAbortHandler)
+}
@@ -0,0 +1,135 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseChaosRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{name: "empty", content: "rules: []"},
+		{name: "latency", content: "rules:\n- path: /orders/*\n  latency: 500ms\n  maxLatency: 2s\n  percentage: 25"},
+		{name: "status", content: "rules:\n- method: POST\n  status: 503\n  body: unavailable"},
+		{name: "drop", content: "rules:\n- drop: true\n  percentage: 5"},
+		{name: "no effect", content: "rules:\n- path: /orders", wantErr: true},
+		{name: "drop and status", content: "rules:\n- drop: true\n  status: 500", wantErr: true},
+		{name: "invalid status", content: "rules:\n- status: 42", wantErr: true},
+		{name: "invalid percentage", content: "rules:\n- status: 500\n  percentage: 101", wantErr: true},
+		{name: "invalid latency", content: "rules:\n- latency: soon", wantErr: true},
+		{name: "max below min latency", content: "rules:\n- latency: 2s\n  maxLatency: 1s", wantErr: true},
+		{name: "invalid path", content: "rules:\n- path: /[\n  status: 500", wantErr: true},
+		{name: "unknown field", content: "rules:\n- statusCode: 500", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseChaosRules([]byte(tt.content))
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestChaosRule_matches(t *testing.T) {
+	tests := []struct {
+		rule   ChaosRule
+		method string
+		path   string
+		want   bool
+	}{
+		{rule: ChaosRule{}, method: "GET", path: "/anything", want: true},
+		{rule: ChaosRule{Method: "post"}, method: "POST", path: "/", want: true},
+		{rule: ChaosRule{Method: "POST"}, method: "GET", path: "/", want: false},
+		{rule: ChaosRule{Path: "/orders/*"}, method: "GET", path: "/orders/1", want: true},
+		{rule: ChaosRule{Path: "/orders/*"}, method: "GET", path: "/orders/1/items", want: false},
+		{rule: ChaosRule{Path: "/orders/**"}, method: "GET", path: "/orders/1/items", want: true},
+		{rule: ChaosRule{Path: "/orders/**"}, method: "GET", path: "/orders", want: true},
+		{rule: ChaosRule{Path: "/orders/**"}, method: "GET", path: "/ordersx", want: false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		require.Equal(t, tt.want, tt.rule.matches(req), "rule %+v for %s %s", tt.rule, tt.method, tt.path)
+	}
+}
+
+func newTestChaos(t *testing.T, content string, random float64) *Chaos {
+	file := filepath.Join(t.TempDir(), "chaos.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+	chaos, err := LoadChaos(file)
+	require.NoError(t, err)
+	chaos.random = func() float64 { return random }
+	return chaos
+}
+
+func TestChaos_Inject(t *testing.T) {
+	t.Run("status for matching request", func(t *testing.T) {
+		chaos := newTestChaos(t, "rules:\n- method: GET\n  path: /orders\n  percentage: 50\n  status: 503\n  body: unavailable", 0.2)
+		rr := httptest.NewRecorder()
+		require.True(t, chaos.Inject(rr, httptest.NewRequest("GET", "/orders", nil)))
+		require.Equal(t, 503, rr.Code)
+		require.Equal(t, "unavailable", rr.Body.String())
+
+		require.False(t, chaos.Inject(httptest.NewRecorder(), httptest.NewRequest("GET", "/other", nil)))
+	})
+
+	t.Run("outside percentage", func(t *testing.T) {
+		chaos := newTestChaos(t, "rules:\n- percentage: 50\n  status: 503", 0.7)
+		require.False(t, chaos.Inject(httptest.NewRecorder(), httptest.NewRequest("GET", "/orders", nil)))
+	})
+
+	t.Run("random latency", func(t *testing.T) {
+		chaos := newTestChaos(t, "rules:\n- latency: 20ms\n  maxLatency: 60ms", 0.5)
+		start := time.Now()
+		require.False(t, chaos.Inject(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil)))
+		require.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+	})
+
+	t.Run("drop connection", func(t *testing.T) {
+		chaos := newTestChaos(t, "rules:\n- drop: true", 0)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			chaos.Inject(w, r)
+		}))
+		defer server.Close()
+		_, err := http.Get(server.URL)
+		require.Error(t, err, "connection should be closed without a response")
+	})
+}
+
+func TestChaos_Reload(t *testing.T) {
+	chaos := newTestChaos(t, "rules:\n- status: 500", 0)
+	require.Len(t, chaos.rules, 1)
+
+	require.NoError(t, os.WriteFile(chaos.file, []byte("rules:\n- status: 502\n- latency: 1ms"), 0644))
+	require.NoError(t, chaos.Reload())
+	require.Len(t, chaos.rules, 2)
+
+	require.NoError(t, os.WriteFile(chaos.file, []byte("rules:\n- status: nope"), 0644))
+	require.Error(t, chaos.Reload())
+	require.Len(t, chaos.rules, 2, "previous rules should be kept if the file is invalid")
+}
+
+func TestHandleChaos(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request should not be forwarded upstream")
+	}))
+	defer upstream.Close()
+
+	chaos := newTestChaos(t, "rules:\n- status: 418", 0)
+	rr := httptest.NewRecorder()
+	Handle(upstream.URL, rr, httptest.NewRequest("GET", "/", nil), HandleOptions{Chaos: chaos}, func(exchange HttpExchange) {
+		t.Errorf("injected faults should not be recorded")
+	})
+	require.Equal(t, 418, rr.Code)
+}
@@ -19,6 +19,7 @@ package proxy
 import (
 	"fmt"
 	"github.com/google/uuid"
+	"github.com/imposter-project/imposter-cli/internal/fileutil"
 	"github.com/imposter-project/imposter-cli/internal/stringutil"
 	"mime"
 	"net/http"
@@ -106,20 +107,26 @@ func generateRespFileName(
 	} else {
 		suffix = ""
 	}
-	respFile = path.Join(parentDir, respFileName+suffix)
+	return uniqueFileName(parentDir, respFileName, suffix, req.URL.String()), nil
+}
 
-	if _, err = os.Stat(respFile); err == nil {
-		// already exists - add url hash
-		suffix = "_" + stringutil.Sha1hashString(req.URL.String()) + suffix
-		respFile = path.Join(parentDir, respFileName+suffix)
+// uniqueFileName returns the path of the file with the given name and
+// extension in the parentDir. If a file already exists with that name, a
+// hash of the key is added to the name, followed by a UUID if that also
+// exists, so that earlier recordings are not overwritten.
+func uniqueFileName(parentDir string, name string, extension string, key string) string {
+	file := path.Join(parentDir, name+extension)
+	if _, err := os.Stat(file); err == nil {
+		// already exists - add key hash
+		extension = "_" + stringutil.Sha1hashString(key) + extension
+		file = path.Join(parentDir, name+extension)
 	}
-	if _, err = os.Stat(respFile); err == nil {
+	if _, err := os.Stat(file); err == nil {
 		// already exists - add uuid
-		suffix = "_" + uuid.New().String() + suffix
-		respFile = path.Join(parentDir, respFileName+suffix)
+		extension = "_" + uuid.New().String() + extension
+		file = path.Join(parentDir, name+extension)
 	}
-
-	return respFile, nil
+	return file
 }
 
 func getFileExtension(respHeaders *http.Header) string {
@@ -135,8 +142,8 @@ func getFileExtension(respHeaders *http.Header) string {
 	}
 
 	if contentType := respHeaders.Get("Content-Type"); contentType != "" {
-		if extensions, err := mime.ExtensionsByType(contentType); err == nil && len(extensions) > 0 {
-			return extensions[0]
+		if extension := fileutil.ExtensionForMediaType(contentType); extension != "" {
+			return extension
 		}
 	}
 	return ".txt"
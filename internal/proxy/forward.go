@@ -0,0 +1,158 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bufio"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// UpstreamHandler proxies the request to the given upstream base URL.
+type UpstreamHandler func(upstream string, w http.ResponseWriter, req *http.Request)
+
+// ForwardProxyUpstream returns the upstream base URL for a request sent
+// to the proxy in absolute form, as HTTP clients do when configured with
+// HTTP_PROXY, e.g. 'GET http://example.com/foo HTTP/1.1'.
+func ForwardProxyUpstream(req *http.Request) string {
+	return req.URL.Scheme + "://" + req.URL.Host
+}
+
+// ServeConnect handles an HTTP CONNECT request by terminating TLS for the
+// requested host, using a certificate issued by the CA, then passing each
+// request received through the tunnel to the handler, with an HTTPS
+// upstream for the host. This allows clients configured with HTTPS_PROXY
+// to be recorded.
+func ServeConnect(w http.ResponseWriter, req *http.Request, ca *CertificateAuthority, handler UpstreamHandler) {
+	host := req.Host
+	hostname, port, err := net.SplitHostPort(host)
+	if err != nil {
+		hostname, port = host, "443"
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		logger.Errorf("cannot hijack connection for CONNECT to %s", host)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		logger.Errorf("failed to hijack connection for CONNECT to %s: %v", host, err)
+		return
+	}
+	// the client may send the TLS handshake without waiting for the response
+	if rw.Reader.Buffered() > 0 {
+		conn = &bufferedConn{Conn: conn, reader: rw.Reader}
+	}
+	if _, err := conn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n")); err != nil {
+		logger.Errorf("failed to establish tunnel to %s: %v", host, err)
+		_ = conn.Close()
+		return
+	}
+
+	tlsConn := tls.Server(conn, ca.TLSConfig(hostname))
+	if err := tlsConn.Handshake(); err != nil {
+		logger.Warnf("TLS handshake failed for tunnel to %s - check the client trusts the proxy CA: %v", host, err)
+		_ = tlsConn.Close()
+		return
+	}
+	logger.Debugf("established tunnel to %s for client %v", host, req.RemoteAddr)
+
+	upstream := "https://" + hostname
+	if port != "443" {
+		upstream += ":" + port
+	}
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler(upstream, w, r)
+		}),
+	}
+
+	// TLS has already been terminated, so if the client negotiated HTTP/2
+	// the server sees it as unencrypted HTTP/2 with prior knowledge
+	server.Protocols = new(http.Protocols)
+	server.Protocols.SetHTTP1(true)
+	server.Protocols.SetUnencryptedHTTP2(true)
+	_ = server.Serve(newSingleConnListener(tlsConn))
+}
+
+// bufferedConn is a net.Conn that reads from the reader, which holds
+// the bytes read from the connection before it was hijacked.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+// singleConnListener is a net.Listener that yields a single connection,
+// then blocks until that connection is closed.
+type singleConnListener struct {
+	conn   net.Conn
+	once   sync.Once
+	closed chan struct{}
+}
+
+func newSingleConnListener(conn net.Conn) *singleConnListener {
+	l := &singleConnListener{closed: make(chan struct{})}
+	l.conn = &notifyingConn{Conn: conn, onClose: func() { close(l.closed) }}
+	return l
+}
+
+func (l *singleConnListener) Accept() (net.Conn, error) {
+	var conn net.Conn
+	l.once.Do(func() {
+		conn = l.conn
+	})
+	if conn != nil {
+		return conn, nil
+	}
+	<-l.closed
+	return nil, net.ErrClosed
+}
+
+func (l *singleConnListener) Close() error {
+	return nil
+}
+
+func (l *singleConnListener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
+
+type notifyingConn struct {
+	net.Conn
+	closeOnce sync.Once
+	onClose   func()
+}
+
+func (c *notifyingConn) Close() error {
+	err := c.Conn.Close()
+	c.closeOnce.Do(c.onClose)
+	return err
+}
+
+// IsForwardProxyRequest returns true if the request was sent to the proxy
+// as a forward proxy, rather than as a reverse proxy for a single upstream.
+func IsForwardProxyRequest(req *http.Request) bool {
+	return req.Method == http.MethodConnect || (req.URL.IsAbs() && strings.HasPrefix(req.URL.Scheme, "http"))
+}
@@ -0,0 +1,145 @@
+package proxy
+
+import (
+	"bufio"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeConnect(t *testing.T) {
+	upstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("hello from " + r.URL.Path))
+	}))
+	defer upstream.Close()
+
+	ca, err := LoadOrCreateCA(t.TempDir())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		http2     bool
+		wantProto int
+	}{
+		{name: "HTTP/1.1 client", http2: false, wantProto: 1},
+		{name: "HTTP/2 client", http2: true, wantProto: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var recordedUpstream string
+			var recordedBody string
+			handler := func(upstream string, w http.ResponseWriter, req *http.Request) {
+				recordedUpstream = upstream
+				Handle(upstream, w, req, HandleOptions{Insecure: true}, func(exchange HttpExchange) {
+					recordedBody = string(*exchange.ResponseBody)
+				})
+			}
+			proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				require.True(t, IsForwardProxyRequest(req))
+				ServeConnect(w, req, ca, handler)
+			}))
+			defer proxyServer.Close()
+
+			proxyUrl, _ := url.Parse(proxyServer.URL)
+			client := &http.Client{Transport: &http.Transport{
+				Proxy:             http.ProxyURL(proxyUrl),
+				TLSClientConfig:   &tls.Config{RootCAs: ca.CertPool()},
+				ForceAttemptHTTP2: tt.http2,
+			}}
+			resp, err := client.Get(upstream.URL + "/greeting")
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, tt.wantProto, resp.ProtoMajor)
+			require.Equal(t, "hello from /greeting", string(body))
+			require.Equal(t, upstream.URL, recordedUpstream)
+			require.Equal(t, "hello from /greeting", recordedBody)
+		})
+	}
+}
+
+// earlyDataConn sends the CONNECT request in the same write as the first
+// bytes of the TLS handshake, and skips the response to the CONNECT request.
+type earlyDataConn struct {
+	net.Conn
+	connectReq []byte
+	reader     *bufio.Reader
+}
+
+func (c *earlyDataConn) Write(p []byte) (int, error) {
+	if c.connectReq != nil {
+		data := append(c.connectReq, p...)
+		c.connectReq = nil
+		if _, err := c.Conn.Write(data); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	return c.Conn.Write(p)
+}
+
+func (c *earlyDataConn) Read(p []byte) (int, error) {
+	if c.reader == nil {
+		c.reader = bufio.NewReader(c.Conn)
+		resp, err := http.ReadResponse(c.reader, nil)
+		if err != nil {
+			return 0, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return 0, io.ErrUnexpectedEOF
+		}
+	}
+	return c.reader.Read(p)
+}
+
+func TestServeConnect_earlyHandshake(t *testing.T) {
+	ca, err := LoadOrCreateCA(t.TempDir())
+	require.NoError(t, err)
+	handler := func(upstream string, w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte("tunnelled to " + upstream))
+	}
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ServeConnect(w, req, ca, handler)
+	}))
+	defer proxyServer.Close()
+
+	conn, err := net.Dial("tcp", proxyServer.Listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(10*time.Second)))
+	tunnel := &earlyDataConn{
+		Conn:       conn,
+		connectReq: []byte("CONNECT example.com:443 HTTP/1.1\r\nHost: example.com:443\r\n\r\n"),
+	}
+	tlsConn := tls.Client(tunnel, &tls.Config{ServerName: "example.com", RootCAs: ca.CertPool()})
+	require.NoError(t, tlsConn.Handshake(), "handshake sent with the CONNECT request should not be lost")
+
+	_, err = tlsConn.Write([]byte("GET / HTTP/1.1\r\nHost: example.com\r\nConnection: close\r\n\r\n"))
+	require.NoError(t, err)
+	resp, err := http.ReadResponse(bufio.NewReader(tlsConn), nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "tunnelled to https://example.com", string(body))
+}
+
+func TestForwardProxyUpstream(t *testing.T) {
+	req := httptest.NewRequest("GET", "http://example.com:8080/foo?bar=baz", nil)
+	require.True(t, IsForwardProxyRequest(req))
+	require.Equal(t, "http://example.com:8080", ForwardProxyUpstream(req))
+
+	relative := httptest.NewRequest("GET", "/foo", nil)
+	require.False(t, IsForwardProxyRequest(relative))
+}
@@ -0,0 +1,99 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// grpcFrameHeaderSize is the size of the prefix of each gRPC message:
+// a one byte compressed flag, followed by a four byte big-endian length.
+const grpcFrameHeaderSize = 5
+
+// IsGrpcRequest returns true if the request is a gRPC call. gRPC-Web
+// requests are not included, as they do not require HTTP/2.
+func IsGrpcRequest(req *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/grpc" || strings.HasPrefix(mediaType, "application/grpc+")
+}
+
+// grpcStatus returns the gRPC status code of the exchange. This is sent
+// in the trailers, or in the headers for a 'trailers-only' response.
+func grpcStatus(exchange HttpExchange) string {
+	if exchange.ResponseTrailers != nil {
+		if status := exchange.ResponseTrailers.Get("Grpc-Status"); status != "" {
+			return status
+		}
+	}
+	if exchange.ResponseHeaders != nil {
+		return exchange.ResponseHeaders.Get("Grpc-Status")
+	}
+	return ""
+}
+
+// parseGrpcMessages splits a gRPC request or response body into its
+// length-prefixed messages, decompressing any compressed messages
+// using the given message encoding.
+func parseGrpcMessages(body []byte, encoding string) ([][]byte, error) {
+	var messages [][]byte
+	for len(body) > 0 {
+		if len(body) < grpcFrameHeaderSize {
+			return nil, fmt.Errorf("incomplete gRPC message header [%d bytes]", len(body))
+		}
+		compressed := body[0] == 1
+		length := binary.BigEndian.Uint32(body[1:grpcFrameHeaderSize])
+		body = body[grpcFrameHeaderSize:]
+		if uint64(len(body)) < uint64(length) {
+			return nil, fmt.Errorf("incomplete gRPC message: expected %d bytes, got %d", length, len(body))
+		}
+		message := body[:length]
+		body = body[length:]
+
+		if compressed {
+			var err error
+			if message, err = decompressGrpcMessage(message, encoding); err != nil {
+				return nil, err
+			}
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+func decompressGrpcMessage(message []byte, encoding string) ([]byte, error) {
+	switch encoding {
+	case "gzip":
+		reader, err := gzip.NewReader(bytes.NewReader(message))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress gRPC message: %v", err)
+		}
+		defer reader.Close()
+		return io.ReadAll(reader)
+	default:
+		return nil, fmt.Errorf("unsupported gRPC message encoding: %s", encoding)
+	}
+}
@@ -0,0 +1,336 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/imposter-project/imposter-cli/internal/fileutil"
+	impostermodel2 "github.com/imposter-project/imposter-cli/internal/impostermodel"
+	"github.com/imposter-project/imposter-cli/internal/protobuf"
+	"github.com/imposter-project/imposter-cli/internal/stringutil"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+type grpcRecorder struct {
+	upstreamHost   string
+	dir            string
+	configFile     string
+	options        RecorderOptions
+	files          []protoreflect.FileDescriptor
+	genOptions     impostermodel2.ConfigGenerationOptions
+	resources      []impostermodel2.Resource
+	requestHashes  []string
+	responseHashes map[string]string
+}
+
+// StartGrpcRecorder starts a recorder for gRPC calls to the given upstream,
+// writing exchanges received on the returned channel to the given dir as a
+// grpc plugin configuration. Messages are decoded using the proto files,
+// which are copied to the dir, so they can be referenced by the config.
+func StartGrpcRecorder(upstream string, dir string, protoFiles []string, options RecorderOptions) (chan HttpExchange, error) {
+	r, err := newGrpcRecorder(upstream, dir, protoFiles, options)
+	if err != nil {
+		return nil, err
+	}
+
+	recordC := make(chan HttpExchange)
+	go func() {
+		for {
+			exchange := <-recordC
+			r.record(exchange)
+		}
+	}()
+
+	return recordC, nil
+}
+
+func newGrpcRecorder(upstream string, dir string, protoFiles []string, options RecorderOptions) (*grpcRecorder, error) {
+	if len(protoFiles) == 0 {
+		return nil, fmt.Errorf("at least one proto file is required to record gRPC calls")
+	}
+	upstreamHost, err := formatUpstreamName(upstream)
+	if err != nil {
+		return nil, err
+	}
+	configFile := path.Join(dir, upstreamHost+"-grpc-config.yaml")
+	var resources []impostermodel2.Resource
+	if _, err := os.Stat(configFile); err == nil {
+		if !options.AppendToExisting {
+			return nil, fmt.Errorf("config file %s already exists", configFile)
+		}
+		existing, err := loadRecordedConfig(configFile)
+		if err != nil {
+			return nil, err
+		}
+		resources = existing.Resources
+		logger.Infof("appending to %d existing resource(s) in %s", len(resources), configFile)
+	}
+
+	files, err := protobuf.Parse(protoFiles)
+	if err != nil {
+		return nil, err
+	}
+	var destFiles []string
+	for _, protoFile := range protoFiles {
+		destFile, err := copyToDir(protoFile, dir)
+		if err != nil {
+			return nil, err
+		}
+		destFiles = append(destFiles, destFile)
+	}
+
+	var requestHashes []string
+	responseHashes := make(map[string]string)
+	for _, resource := range resources {
+		requestHashes = append(requestHashes, getGrpcRequestHash(resource.Path, resource.RequestBody))
+		if resource.Response != nil && resource.Response.File != "" {
+			respFile := path.Join(dir, filepath.ToSlash(resource.Response.File))
+			if respJson, err := os.ReadFile(respFile); err == nil {
+				responseHashes[stringutil.Sha1hash(respJson)] = respFile
+			}
+		}
+	}
+
+	return &grpcRecorder{
+		upstreamHost: upstreamHost,
+		dir:          dir,
+		configFile:   configFile,
+		options:      options,
+		files:        files,
+		resources:    resources,
+		genOptions: impostermodel2.ConfigGenerationOptions{
+			PluginName:     "grpc",
+			ProtoFilePaths: destFiles,
+		},
+		requestHashes:  requestHashes,
+		responseHashes: responseHashes,
+	}, nil
+}
+
+// copyToDir copies the file into the dir, unless it is already there.
+func copyToDir(file string, dir string) (string, error) {
+	destFile := filepath.Join(dir, filepath.Base(file))
+	srcAbs, err := filepath.Abs(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve file path: %s: %v", file, err)
+	}
+	destAbs, err := filepath.Abs(destFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve file path: %s: %v", destFile, err)
+	}
+	if srcAbs != destAbs {
+		if err := fileutil.CopyFile(srcAbs, destAbs); err != nil {
+			return "", fmt.Errorf("failed to copy file %s to %s: %v", file, dir, err)
+		}
+		logger.Debugf("copied file %s to %s", file, dir)
+	}
+	return destFile, nil
+}
+
+// record decodes the messages of a gRPC call and writes them as a resource
+// with a JSON response file, then updates the config file. Only successful
+// calls are recorded.
+func (r *grpcRecorder) record(exchange HttpExchange) {
+	req := exchange.Request
+	rpcPath := req.URL.Path
+	method := protobuf.FindMethod(r.files, rpcPath)
+	if method == nil {
+		logger.Warnf("no method found in proto files for gRPC call %s - skipping", rpcPath)
+		return
+	}
+	if status := grpcStatus(exchange); status != "0" {
+		logger.Warnf("gRPC call %s returned status %s - skipping", rpcPath, status)
+		return
+	}
+	if exchange.RequestBodyTruncated || exchange.ResponseBodyTruncated {
+		logger.Warnf("messages for gRPC call %s exceeded maximum size - skipping", rpcPath)
+		return
+	}
+
+	requestBody, err := r.buildRequestBody(exchange, method)
+	if err != nil {
+		logger.Warnf("failed to record gRPC call %s: %v", rpcPath, err)
+		return
+	}
+
+	var responseFilePrefix string
+	requestHash := getGrpcRequestHash(rpcPath, requestBody)
+	if stringutil.Contains(r.requestHashes, requestHash) {
+		if r.options.IgnoreDuplicateRequests {
+			logger.Debugf("skipping recording of duplicate gRPC call %s", rpcPath)
+			return
+		}
+		responseFilePrefix = uuid.New().String() + "-"
+	}
+	r.requestHashes = append(r.requestHashes, requestHash)
+
+	resource, err := r.buildResource(exchange, method, requestBody, requestHash, responseFilePrefix)
+	if err != nil {
+		logger.Warnf("failed to record gRPC call %s: %v", rpcPath, err)
+		return
+	}
+	r.resources = append(r.resources, *resource)
+
+	if err := updateConfigFile(exchange, r.genOptions, r.resources, r.configFile); err != nil {
+		logger.Warn(err)
+	}
+}
+
+// getGrpcRequestHash generates the hash of a gRPC call from its path and
+// request body matcher, so that it can also be generated for a recorded
+// resource. Calls without a matcher are replayed for any request, so
+// all calls to the method are treated as duplicates.
+func getGrpcRequestHash(rpcPath string, requestBody *impostermodel2.RequestBody) string {
+	if requestBody == nil {
+		return stringutil.Sha1hashString(rpcPath)
+	}
+	return stringutil.Sha1hashString(rpcPath + "\n" + requestBody.Value)
+}
+
+// buildRequestBody returns the matcher for the request message, or nil if
+// request bodies are not captured, the call streams requests, or the
+// message was redacted, as a placeholder would never match on replay.
+func (r *grpcRecorder) buildRequestBody(exchange HttpExchange, method protoreflect.MethodDescriptor) (*impostermodel2.RequestBody, error) {
+	if !r.options.CaptureRequestBody || method.IsStreamingClient() {
+		return nil, nil
+	}
+	reqJson, err := decodeGrpcMessagesToJson(*exchange.RequestBody, exchange.Request.Header.Get("Grpc-Encoding"), method.Input(), false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode request: %v", err)
+	}
+	if !bytes.Equal(reqJson, r.options.Redaction.RedactBody(reqJson, "application/json")) {
+		logger.Debugf("request for gRPC call %s was redacted - skipping request body capture", method.FullName())
+		return nil, nil
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, reqJson); err != nil {
+		return nil, err
+	}
+	return &impostermodel2.RequestBody{
+		Value:    compact.String(),
+		Operator: "EqualTo",
+	}, nil
+}
+
+func (r *grpcRecorder) buildResource(
+	exchange HttpExchange,
+	method protoreflect.MethodDescriptor,
+	requestBody *impostermodel2.RequestBody,
+	requestHash string,
+	prefix string,
+) (*impostermodel2.Resource, error) {
+	req := exchange.Request
+	respJson, err := decodeGrpcMessagesToJson(*exchange.ResponseBody, exchange.ResponseHeaders.Get("Grpc-Encoding"), method.Output(), method.IsStreamingServer())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+	respJson = r.options.Redaction.RedactBody(respJson, "application/json")
+	respFile, err := r.getResponseFile(method, respJson, requestHash, prefix)
+	if err != nil {
+		return nil, err
+	}
+	relResponseFile, err := filepath.Rel(r.dir, respFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get relative path for response file: %s: %v", respFile, err)
+	}
+
+	return &impostermodel2.Resource{
+		Path:        req.URL.Path,
+		Method:      req.Method,
+		RequestBody: requestBody,
+		Response: &impostermodel2.ResponseConfig{
+			File: relResponseFile,
+		},
+	}, nil
+}
+
+// getResponseFile writes the response JSON to a file named for the method,
+// unless an identical response has already been written. If a file with
+// that name exists, such as from an earlier recording, a suffix is added.
+func (r *grpcRecorder) getResponseFile(method protoreflect.MethodDescriptor, respJson []byte, requestHash string, prefix string) (string, error) {
+	bodyHash := stringutil.Sha1hash(respJson)
+	if existing := r.responseHashes[bodyHash]; existing != "" {
+		logger.Debugf("reusing identical response file %s for gRPC call %s", existing, method.FullName())
+		return existing, nil
+	}
+
+	serviceName := string(method.Parent().FullName())
+	var respFile string
+	if r.options.FlatResponseFileStructure {
+		respFile = uniqueFileName(r.dir, r.upstreamHost+"-"+serviceName+"_"+prefix+string(method.Name()), ".json", requestHash)
+	} else {
+		parentDir := path.Join(r.dir, serviceName)
+		if err := ensureDirExists(parentDir); err != nil {
+			return "", err
+		}
+		respFile = uniqueFileName(parentDir, prefix+string(method.Name()), ".json", requestHash)
+	}
+	if err := os.WriteFile(respFile, respJson, 0644); err != nil {
+		return "", fmt.Errorf("failed to write response file %s for gRPC call %s: %v", respFile, method.FullName(), err)
+	}
+	logger.Debugf("wrote response file %s for gRPC call %s [%d bytes]", respFile, method.FullName(), len(respJson))
+	r.responseHashes[bodyHash] = respFile
+	return respFile, nil
+}
+
+// decodeGrpcMessagesToJson decodes the messages in a gRPC body as the
+// given message type. A stream of messages is returned as a JSON array,
+// otherwise the single message is returned as a JSON object.
+func decodeGrpcMessagesToJson(body []byte, encoding string, messageType protoreflect.MessageDescriptor, stream bool) ([]byte, error) {
+	frames, err := parseGrpcMessages(body, encoding)
+	if err != nil {
+		return nil, err
+	}
+	if !stream && len(frames) != 1 {
+		return nil, fmt.Errorf("expected a single %s message, got %d", messageType.FullName(), len(frames))
+	}
+
+	var messages []json.RawMessage
+	for _, frame := range frames {
+		message := dynamicpb.NewMessage(messageType)
+		if err := proto.Unmarshal(frame, message); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s message: %v", messageType.FullName(), err)
+		}
+		messageJson, err := protojson.Marshal(message)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s message as JSON: %v", messageType.FullName(), err)
+		}
+		messages = append(messages, messageJson)
+	}
+
+	var result any = messages
+	if !stream {
+		result = messages[0]
+	} else if messages == nil {
+		result = []json.RawMessage{}
+	}
+	formatted, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(formatted, '\n'), nil
+}
@@ -0,0 +1,125 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/imposter-project/imposter-cli/internal/impostermodel"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func TestGrpcRecorder_record(t *testing.T) {
+	getPet := findPetStoreMethod(t, "GetPet")
+	watchPets := findPetStoreMethod(t, "WatchPets")
+
+	newExchange := func(rpcPath string, reqBody []byte, respBody []byte, status string) HttpExchange {
+		req := httptest.NewRequest("POST", rpcPath, nil)
+		req.Header.Set("Content-Type", "application/grpc")
+		respHeaders := http.Header{"Content-Type": {"application/grpc"}}
+		trailers := http.Header{"Grpc-Status": {status}}
+		return HttpExchange{
+			Request:          req,
+			RequestBody:      &reqBody,
+			StatusCode:       http.StatusOK,
+			ResponseBody:     &respBody,
+			ResponseHeaders:  &respHeaders,
+			ResponseTrailers: &trailers,
+		}
+	}
+
+	dir := t.TempDir()
+	r, err := newGrpcRecorder("http://localhost:50051", dir, []string{"testdata/pet_store.proto"}, RecorderOptions{
+		CaptureRequestBody:      true,
+		IgnoreDuplicateRequests: true,
+	})
+	require.NoError(t, err)
+
+	r.record(newExchange("/store.PetStore/GetPet",
+		grpcFrame(marshalPetMessage(t, getPet, true, "pet_id", "1"), false),
+		grpcFrame(marshalPetMessage(t, getPet, false, "name", "Fluffy"), false),
+		"0",
+	))
+	r.record(newExchange("/store.PetStore/WatchPets",
+		grpcFrame(nil, false),
+		append(
+			grpcFrame(marshalPetMessage(t, watchPets, false, "name", "Fluffy"), false),
+			grpcFrame(marshalPetMessage(t, watchPets, false, "name", "Rex"), false)...,
+		),
+		"0",
+	))
+	r.record(newExchange("/store.PetStore/GetPet",
+		grpcFrame(marshalPetMessage(t, getPet, true, "pet_id", "2"), false),
+		nil,
+		"5",
+	))
+	r.record(newExchange("/store.Unknown/Method", nil, nil, "0"))
+
+	require.FileExists(t, filepath.Join(dir, "pet_store.proto"), "proto file should be copied")
+
+	configContent, err := os.ReadFile(filepath.Join(dir, "localhost-50051-grpc-config.yaml"))
+	require.NoError(t, err)
+	var config impostermodel.PluginConfig
+	require.NoError(t, yaml.Unmarshal(configContent, &config))
+
+	require.Equal(t, "grpc", config.Plugin)
+	require.Equal(t, []string{"pet_store.proto"}, config.Config.ProtoFiles)
+	require.Len(t, config.Resources, 2, "failed and unknown calls should not be recorded")
+
+	require.Equal(t, "/store.PetStore/GetPet", config.Resources[0].Path)
+	require.Equal(t, `{"petId":"1"}`, config.Resources[0].RequestBody.Value)
+	require.Equal(t, filepath.Join("store.PetStore", "GetPet.json"), config.Resources[0].Response.File)
+	getPetJson, err := os.ReadFile(filepath.Join(dir, config.Resources[0].Response.File))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":"Fluffy"}`, string(getPetJson))
+
+	require.Equal(t, "/store.PetStore/WatchPets", config.Resources[1].Path)
+	watchPetsJson, err := os.ReadFile(filepath.Join(dir, config.Resources[1].Response.File))
+	require.NoError(t, err)
+	require.JSONEq(t, `[{"name":"Fluffy"},{"name":"Rex"}]`, string(watchPetsJson))
+
+	// appending seeds the request and response hashes from the recorded resources
+	r, err = newGrpcRecorder("http://localhost:50051", dir, []string{"testdata/pet_store.proto"}, RecorderOptions{
+		AppendToExisting:        true,
+		CaptureRequestBody:      true,
+		IgnoreDuplicateRequests: true,
+	})
+	require.NoError(t, err)
+
+	r.record(newExchange("/store.PetStore/GetPet",
+		grpcFrame(marshalPetMessage(t, getPet, true, "pet_id", "1"), false),
+		grpcFrame(marshalPetMessage(t, getPet, false, "name", "Changed"), false),
+		"0",
+	))
+	r.record(newExchange("/store.PetStore/GetPet",
+		grpcFrame(marshalPetMessage(t, getPet, true, "pet_id", "3"), false),
+		grpcFrame(marshalPetMessage(t, getPet, false, "name", "Rex"), false),
+		"0",
+	))
+	r.record(newExchange("/store.PetStore/GetPet",
+		grpcFrame(marshalPetMessage(t, getPet, true, "pet_id", "4"), false),
+		grpcFrame(marshalPetMessage(t, getPet, false, "name", "Fluffy"), false),
+		"0",
+	))
+
+	configContent, err = os.ReadFile(filepath.Join(dir, "localhost-50051-grpc-config.yaml"))
+	require.NoError(t, err)
+	config = impostermodel.PluginConfig{}
+	require.NoError(t, yaml.Unmarshal(configContent, &config))
+	require.Len(t, config.Resources, 4, "duplicate calls should not be recorded")
+
+	getPetJson, err = os.ReadFile(filepath.Join(dir, config.Resources[0].Response.File))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":"Fluffy"}`, string(getPetJson), "existing response file should not be overwritten")
+
+	require.Equal(t, `{"petId":"3"}`, config.Resources[2].RequestBody.Value)
+	require.NotEqual(t, config.Resources[0].Response.File, config.Resources[2].Response.File)
+	rexJson, err := os.ReadFile(filepath.Join(dir, config.Resources[2].Response.File))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":"Rex"}`, string(rexJson))
+
+	require.Equal(t, config.Resources[0].Response.File, config.Resources[3].Response.File, "identical response should reuse the existing file")
+}
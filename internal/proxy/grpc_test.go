@@ -0,0 +1,188 @@
+package proxy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/imposter-project/imposter-cli/internal/protobuf"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// grpcFrame prefixes the message with the gRPC message header.
+func grpcFrame(message []byte, compressed bool) []byte {
+	frame := make([]byte, grpcFrameHeaderSize, grpcFrameHeaderSize+len(message))
+	if compressed {
+		frame[0] = 1
+	}
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+	return append(frame, message...)
+}
+
+// marshalPetMessage encodes a message of the given type from the pet store
+// test proto, with the string field set to the value.
+func marshalPetMessage(t *testing.T, method protoreflect.MethodDescriptor, input bool, field string, value string) []byte {
+	messageType := method.Output()
+	if input {
+		messageType = method.Input()
+	}
+	message := dynamicpb.NewMessage(messageType)
+	message.Set(messageType.Fields().ByName(protoreflect.Name(field)), protoreflect.ValueOfString(value))
+	encoded, err := proto.Marshal(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encoded
+}
+
+func findPetStoreMethod(t *testing.T, name string) protoreflect.MethodDescriptor {
+	files, err := protobuf.Parse([]string{"testdata/pet_store.proto"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	method := protobuf.FindMethod(files, "/store.PetStore/"+name)
+	if method == nil {
+		t.Fatalf("method %s not found", name)
+	}
+	return method
+}
+
+func Test_parseGrpcMessages(t *testing.T) {
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	_, _ = gz.Write([]byte("compressed"))
+	_ = gz.Close()
+
+	tests := []struct {
+		name     string
+		body     []byte
+		encoding string
+		want     [][]byte
+		wantErr  bool
+	}{
+		{name: "empty body", body: nil, want: nil},
+		{name: "single message", body: grpcFrame([]byte("one"), false), want: [][]byte{[]byte("one")}},
+		{
+			name: "multiple messages",
+			body: append(grpcFrame([]byte("one"), false), grpcFrame([]byte("two"), false)...),
+			want: [][]byte{[]byte("one"), []byte("two")},
+		},
+		{name: "gzip message", body: grpcFrame(gzipped.Bytes(), true), encoding: "gzip", want: [][]byte{[]byte("compressed")}},
+		{name: "unsupported encoding", body: grpcFrame([]byte("x"), true), encoding: "snappy", wantErr: true},
+		{name: "incomplete header", body: []byte{0, 0}, wantErr: true},
+		{name: "incomplete message", body: grpcFrame([]byte("one"), false)[:6], wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGrpcMessages(tt.body, tt.encoding)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseGrpcMessages() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseGrpcMessages() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsGrpcRequest(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        bool
+	}{
+		{contentType: "application/grpc", want: true},
+		{contentType: "application/grpc+proto", want: true},
+		{contentType: "application/grpc-web", want: false},
+		{contentType: "application/json", want: false},
+		{contentType: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/store.PetStore/GetPet", nil)
+			req.Header.Set("Content-Type", tt.contentType)
+			if got := IsGrpcRequest(req); got != tt.want {
+				t.Errorf("IsGrpcRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// newH2cServer starts a test server that accepts HTTP/2 without TLS.
+func newH2cServer(handler http.Handler) *httptest.Server {
+	server := httptest.NewUnstartedServer(handler)
+	server.Config.Protocols = new(http.Protocols)
+	server.Config.Protocols.SetHTTP1(true)
+	server.Config.Protocols.SetUnencryptedHTTP2(true)
+	server.Start()
+	return server
+}
+
+func TestHandleGrpc(t *testing.T) {
+	method := findPetStoreMethod(t, "GetPet")
+	respMessage := grpcFrame(marshalPetMessage(t, method, false, "name", "Fluffy"), false)
+
+	upstream := newH2cServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 2 {
+			t.Errorf("expected HTTP/2 request to upstream, got %s", r.Proto)
+		}
+		if r.Header.Get("TE") != "trailers" {
+			t.Errorf("expected TE: trailers header, got %q", r.Header.Get("TE"))
+		}
+		_, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/grpc")
+		_, _ = w.Write(respMessage)
+		// gRPC servers send trailers without announcing them
+		w.Header().Set(http.TrailerPrefix+"Grpc-Status", "0")
+	}))
+	defer upstream.Close()
+
+	exchanges := make(chan HttpExchange, 1)
+	proxyServer := newH2cServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Handle(upstream.URL, w, r, HandleOptions{}, func(exchange HttpExchange) {
+			exchanges <- exchange
+		})
+	}))
+	defer proxyServer.Close()
+
+	clientTransport := &http.Transport{Protocols: new(http.Protocols)}
+	clientTransport.Protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: clientTransport}
+
+	reqMessage := grpcFrame(marshalPetMessage(t, method, true, "pet_id", "1"), false)
+	req, err := http.NewRequest("POST", proxyServer.URL+"/store.PetStore/GetPet", bytes.NewReader(reqMessage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(body, respMessage) {
+		t.Errorf("unexpected response body: %q", body)
+	}
+	if resp.Trailer.Get("Grpc-Status") != "0" {
+		t.Errorf("expected grpc-status trailer to be proxied, got trailers: %v", resp.Trailer)
+	}
+
+	exchange := <-exchanges
+	if grpcStatus(exchange) != "0" {
+		t.Errorf("expected grpc-status in exchange trailers, got %v", exchange.ResponseTrailers)
+	}
+	if !bytes.Equal(*exchange.RequestBody, reqMessage) {
+		t.Errorf("unexpected request body in exchange: %q", *exchange.RequestBody)
+	}
+}
@@ -0,0 +1,313 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/imposter-project/imposter-cli/internal/config"
+	"github.com/imposter-project/imposter-cli/internal/har"
+)
+
+// UpstreamExchanges holds the exchanges made with a single upstream,
+// in the order in which they occurred.
+type UpstreamExchanges struct {
+	Upstream  string
+	Exchanges []HttpExchange
+}
+
+// ExchangesFromHar converts the entries in the HAR to exchanges, grouped
+// by upstream. Entries that are not HTTP(S) or WebSocket, or that did
+// not receive a response, are skipped.
+func ExchangesFromHar(h *har.Har) ([]UpstreamExchanges, error) {
+	var groups []UpstreamExchanges
+	indices := make(map[string]int)
+
+	for _, entry := range h.Log.Entries {
+		reqUrl, err := url.Parse(entry.Request.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse HAR request URL: %s: %v", entry.Request.URL, err)
+		}
+		switch reqUrl.Scheme {
+		case "http", "https":
+		case "ws":
+			reqUrl.Scheme = "http"
+		case "wss":
+			reqUrl.Scheme = "https"
+		default:
+			logger.Debugf("skipping HAR entry with unsupported scheme: %s", entry.Request.URL)
+			continue
+		}
+		if entry.Response.Status == 0 {
+			logger.Debugf("skipping HAR entry without response: %s %s", entry.Request.Method, entry.Request.URL)
+			continue
+		}
+		exchange, err := exchangeFromHarEntry(entry, reqUrl)
+		if err != nil {
+			return nil, err
+		}
+
+		upstream := reqUrl.Scheme + "://" + reqUrl.Host
+		idx, ok := indices[upstream]
+		if !ok {
+			idx = len(groups)
+			indices[upstream] = idx
+			groups = append(groups, UpstreamExchanges{Upstream: upstream})
+		}
+		groups[idx].Exchanges = append(groups[idx].Exchanges, *exchange)
+	}
+	return groups, nil
+}
+
+func exchangeFromHarEntry(entry har.Entry, reqUrl *url.URL) (*HttpExchange, error) {
+	var reqBody []byte
+	if entry.Request.PostData != nil {
+		reqBody = []byte(entry.Request.PostData.Text)
+	}
+	req, err := http.NewRequest(entry.Request.Method, reqUrl.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request for HAR entry %s %s: %v", entry.Request.Method, entry.Request.URL, err)
+	}
+	req.Header = headersFromHar(entry.Request.Headers)
+
+	var respBody []byte
+	content := entry.Response.Content
+	if content.Encoding == "base64" {
+		respBody, err = base64.StdEncoding.DecodeString(content.Text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response body for HAR entry %s %s: %v", entry.Request.Method, entry.Request.URL, err)
+		}
+	} else {
+		respBody = []byte(content.Text)
+	}
+	respHeaders := headersFromHar(entry.Response.Headers)
+
+	// the HAR content is already decoded, so the original encoding no longer applies
+	respHeaders.Del("Content-Encoding")
+	if respHeaders.Get("Content-Type") == "" && content.MimeType != "" {
+		respHeaders.Set("Content-Type", content.MimeType)
+	}
+
+	exchange := &HttpExchange{
+		Upstream:        reqUrl.Scheme + "://" + reqUrl.Host,
+		Request:         req,
+		RequestBody:     &reqBody,
+		StatusCode:      entry.Response.Status,
+		ResponseBody:    &respBody,
+		ResponseHeaders: &respHeaders,
+	}
+	if len(entry.WebSocketMessages) > 0 {
+		messages, err := webSocketMessagesFromHar(entry)
+		if err != nil {
+			return nil, err
+		}
+		exchange.WebSocketMessages = &messages
+	}
+	return exchange, nil
+}
+
+// webSocketMessagesFromHar converts the WebSocket messages of the entry,
+// skipping control frames. Offsets are relative to the first message, as
+// the HAR does not record when the connection was established.
+func webSocketMessagesFromHar(entry har.Entry) ([]WebSocketMessage, error) {
+	messages := []WebSocketMessage{}
+	var start float64
+	for i, harMessage := range entry.WebSocketMessages {
+		if i == 0 {
+			start = harMessage.Time
+		}
+		message := WebSocketMessage{
+			Direction: WebSocketDirection(harMessage.Type),
+			Offset:    time.Duration((harMessage.Time - start) * float64(time.Second)),
+		}
+		switch harMessage.Opcode {
+		case wsOpText:
+			message.Data = []byte(harMessage.Data)
+		case wsOpBinary:
+			data, err := base64.StdEncoding.DecodeString(harMessage.Data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode WebSocket message for HAR entry %s: %v", entry.Request.URL, err)
+			}
+			message.Binary = true
+			message.Data = data
+		default:
+			continue
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// headersFromHar converts HAR headers to an http.Header, skipping
+// HTTP/2 pseudo-headers such as ':authority'.
+func headersFromHar(harHeaders []har.NameValue) http.Header {
+	headers := http.Header{}
+	for _, h := range harHeaders {
+		if strings.HasPrefix(h.Name, ":") {
+			continue
+		}
+		headers.Add(h.Name, h.Value)
+	}
+	return headers
+}
+
+// StartHarRecorder starts a recorder that writes exchanges received on the
+// returned channel to a HAR archive at the given path. Each entry is
+// appended to the archive as it is received, so it is always valid.
+// Redaction rules, if non-nil, are applied before each exchange is written.
+func StartHarRecorder(harFile string, redaction *RedactionRules) (chan HttpExchange, error) {
+	if _, err := os.Stat(harFile); err == nil {
+		return nil, fmt.Errorf("HAR file %s already exists", harFile)
+	}
+	writer, err := har.NewWriter(harFile, "1.2", har.Creator{Name: "imposter-cli", Version: config.Config.Version})
+	if err != nil {
+		return nil, err
+	}
+
+	harC := make(chan HttpExchange)
+	go func() {
+		for {
+			exchange := <-harC
+			exchange = redaction.Redact(exchange)
+			if err := writer.Append(harEntryFromExchange(exchange)); err != nil {
+				logger.Warn(err)
+				continue
+			}
+			logger.Debugf("wrote HAR entry for %s %v to %s", exchange.Request.Method, exchange.Request.URL, harFile)
+		}
+	}()
+	return harC, nil
+}
+
+func harEntryFromExchange(exchange HttpExchange) har.Entry {
+	req := exchange.Request
+	elapsedMs := float64(exchange.Elapsed.Microseconds()) / 1000
+
+	// requests received as a reverse proxy have a relative URL,
+	// whereas HAR requires the absolute URL of the upstream resource
+	reqUrl := req.URL.String()
+	if exchange.Upstream != "" {
+		if upstreamUrl, err := buildUpstreamUrl(exchange.Upstream, req); err != nil {
+			logger.Warn(err)
+		} else {
+			reqUrl = upstreamUrl
+		}
+	}
+
+	harReq := har.Request{
+		Method:      req.Method,
+		URL:         reqUrl,
+		HTTPVersion: req.Proto,
+		Cookies:     []har.NameValue{},
+		Headers:     toHarNameValues(req.Header),
+		QueryString: toHarNameValues(req.URL.Query()),
+		HeadersSize: -1,
+		BodySize:    0,
+	}
+	if exchange.RequestBody != nil && len(*exchange.RequestBody) > 0 {
+		harReq.PostData = &har.PostData{
+			MimeType: req.Header.Get("Content-Type"),
+			Text:     string(*exchange.RequestBody),
+		}
+		harReq.BodySize = len(*exchange.RequestBody)
+	}
+
+	var respBody []byte
+	if exchange.ResponseBody != nil {
+		respBody = *exchange.ResponseBody
+	}
+	var respHeaders http.Header
+	if exchange.ResponseHeaders != nil {
+		respHeaders = *exchange.ResponseHeaders
+	}
+	content := har.Content{
+		Size:     len(respBody),
+		MimeType: respHeaders.Get("Content-Type"),
+	}
+	if len(respBody) > 0 {
+		if content.MimeType != "" && isTextContentType(content.MimeType) {
+			content.Text = string(respBody)
+		} else {
+			content.Text = base64.StdEncoding.EncodeToString(respBody)
+			content.Encoding = "base64"
+		}
+	}
+
+	entry := har.Entry{
+		StartedDateTime: exchange.StartTime.Format(time.RFC3339Nano),
+		Time:            elapsedMs,
+		Request:         harReq,
+		Response: har.Response{
+			Status:      exchange.StatusCode,
+			StatusText:  http.StatusText(exchange.StatusCode),
+			HTTPVersion: req.Proto,
+			Cookies:     []har.NameValue{},
+			Headers:     toHarNameValues(respHeaders),
+			Content:     content,
+			HeadersSize: -1,
+			BodySize:    len(respBody),
+		},
+		Timings: har.Timings{Send: 0, Wait: elapsedMs, Receive: 0},
+	}
+	if exchange.WebSocketMessages != nil {
+		entry.WebSocketMessages = harWebSocketMessages(exchange)
+	}
+	return entry
+}
+
+func harWebSocketMessages(exchange HttpExchange) []har.WebSocketMessage {
+	harMessages := []har.WebSocketMessage{}
+	for _, message := range *exchange.WebSocketMessages {
+		sentAt := exchange.StartTime.Add(message.Offset)
+		harMessage := har.WebSocketMessage{
+			Type:   string(message.Direction),
+			Time:   float64(sentAt.UnixMicro()) / 1e6,
+			Opcode: wsOpText,
+			Data:   string(message.Data),
+		}
+		if message.Binary {
+			harMessage.Opcode = wsOpBinary
+			harMessage.Data = base64.StdEncoding.EncodeToString(message.Data)
+		}
+		harMessages = append(harMessages, harMessage)
+	}
+	return harMessages
+}
+
+// toHarNameValues converts headers or query parameters to HAR name/value
+// pairs, sorted by name so that the output is stable.
+func toHarNameValues(values map[string][]string) []har.NameValue {
+	nameValues := []har.NameValue{}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range values[name] {
+			nameValues = append(nameValues, har.NameValue{Name: name, Value: value})
+		}
+	}
+	return nameValues
+}
@@ -0,0 +1,127 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/imposter-project/imposter-cli/internal/har"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExchangesFromHar(t *testing.T) {
+	archive := &har.Har{Log: har.Log{Entries: []har.Entry{
+		{
+			Request: har.Request{
+				Method:   "POST",
+				URL:      "https://api.example.com/orders?draft=true",
+				Headers:  []har.NameValue{{Name: ":method", Value: "POST"}, {Name: "Content-Type", Value: "application/json"}},
+				PostData: &har.PostData{MimeType: "application/json", Text: `{"item":"widget"}`},
+			},
+			Response: har.Response{
+				Status:  201,
+				Headers: []har.NameValue{{Name: "Content-Encoding", Value: "br"}},
+				Content: har.Content{MimeType: "application/json", Text: `{"id":1}`},
+			},
+		},
+		{
+			Request:  har.Request{Method: "GET", URL: "http://localhost:8080/logo.png"},
+			Response: har.Response{Status: 200, Content: har.Content{MimeType: "image/png", Text: "iVBORw==", Encoding: "base64"}},
+		},
+		{
+			Request:  har.Request{Method: "GET", URL: "https://api.example.com/orders/1"},
+			Response: har.Response{Status: 200, Content: har.Content{Text: "ok"}},
+		},
+		{
+			Request:  har.Request{Method: "GET", URL: "data:image/png;base64,AAAA"},
+			Response: har.Response{Status: 200},
+		},
+		{
+			Request:  har.Request{Method: "GET", URL: "https://api.example.com/blocked"},
+			Response: har.Response{Status: 0},
+		},
+	}}}
+
+	groups, err := ExchangesFromHar(archive)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+
+	require.Equal(t, "https://api.example.com", groups[0].Upstream)
+	require.Len(t, groups[0].Exchanges, 2, "unsupported and unanswered entries should be skipped")
+
+	created := groups[0].Exchanges[0]
+	require.Equal(t, "POST", created.Request.Method)
+	require.Equal(t, "/orders", created.Request.URL.Path)
+	require.Equal(t, "draft=true", created.Request.URL.RawQuery)
+	require.Equal(t, "application/json", created.Request.Header.Get("Content-Type"))
+	require.Empty(t, created.Request.Header.Get(":method"), "pseudo-headers should be skipped")
+	require.Equal(t, `{"item":"widget"}`, string(*created.RequestBody))
+	require.Equal(t, 201, created.StatusCode)
+	require.Equal(t, `{"id":1}`, string(*created.ResponseBody))
+	require.Empty(t, created.ResponseHeaders.Get("Content-Encoding"), "content encoding should be removed from decoded body")
+	require.Equal(t, "application/json", created.ResponseHeaders.Get("Content-Type"))
+
+	require.Equal(t, "http://localhost:8080", groups[1].Upstream)
+	require.Equal(t, []byte{0x89, 'P', 'N', 'G'}, *groups[1].Exchanges[0].ResponseBody)
+}
+
+func Test_harEntryFromExchange(t *testing.T) {
+	req := httptest.NewRequest("POST", "/upload?b=2&a=1", nil)
+	req.Header.Set("Content-Type", "text/plain")
+	reqBody := []byte("hello")
+	respBody := []byte{0x00, 0x01, 0x02}
+	respHeaders := http.Header{"Content-Type": []string{"application/octet-stream"}}
+	startTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	entry := harEntryFromExchange(HttpExchange{
+		Upstream:        "https://example.com",
+		Request:         req,
+		RequestBody:     &reqBody,
+		StatusCode:      201,
+		ResponseBody:    &respBody,
+		ResponseHeaders: &respHeaders,
+		StartTime:       startTime,
+		Elapsed:         1500 * time.Microsecond,
+	})
+
+	require.Equal(t, "2026-01-02T03:04:05Z", entry.StartedDateTime)
+	require.Equal(t, 1.5, entry.Time)
+	require.Equal(t, 1.5, entry.Timings.Wait)
+	require.Equal(t, "https://example.com/upload?b=2&a=1", entry.Request.URL, "URL should be that of the upstream resource")
+	require.Equal(t, []har.NameValue{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}}, entry.Request.QueryString)
+	require.Equal(t, "hello", entry.Request.PostData.Text)
+	require.Equal(t, 201, entry.Response.Status)
+	require.Equal(t, "Created", entry.Response.StatusText)
+	require.Equal(t, "base64", entry.Response.Content.Encoding, "binary bodies should be base64 encoded")
+	require.Equal(t, "AAEC", entry.Response.Content.Text)
+	require.NotNil(t, entry.Response.Cookies)
+}
+
+func TestHarWebSocketMessages_roundTrip(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://example.com/chat", nil)
+	startTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	messages := []WebSocketMessage{
+		{Direction: WebSocketSend, Offset: 0, Data: []byte("hello")},
+		{Direction: WebSocketReceive, Offset: 250 * time.Millisecond, Binary: true, Data: []byte{0x00, 0xff}},
+	}
+	respHeaders := http.Header{}
+	entry := harEntryFromExchange(HttpExchange{
+		Request:           req,
+		StatusCode:        http.StatusSwitchingProtocols,
+		ResponseHeaders:   &respHeaders,
+		StartTime:         startTime,
+		WebSocketMessages: &messages,
+	})
+	require.Equal(t, []har.WebSocketMessage{
+		{Type: "send", Time: float64(startTime.Unix()), Opcode: 1, Data: "hello"},
+		{Type: "receive", Time: float64(startTime.Unix()) + 0.25, Opcode: 2, Data: "AP8="},
+	}, entry.WebSocketMessages)
+
+	entry.Request.URL = "wss://example.com/chat"
+	groups, err := ExchangesFromHar(&har.Har{Log: har.Log{Entries: []har.Entry{entry}}})
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	require.Equal(t, "https://example.com", groups[0].Upstream)
+	require.Equal(t, messages, *groups[0].Exchanges[0].WebSocketMessages)
+}
@@ -0,0 +1,210 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	impostermodel2 "github.com/imposter-project/imposter-cli/internal/impostermodel"
+	"github.com/imposter-project/imposter-cli/internal/openapi"
+	"github.com/imposter-project/imposter-cli/internal/stringutil"
+)
+
+var nonAlphanumeric = regexp.MustCompile(`[^a-z0-9]+`)
+
+type openapiRecorder struct {
+	dir        string
+	specFile   string
+	configFile string
+	options    RecorderOptions
+	doc        *openapi.Document
+	genOptions impostermodel2.ConfigGenerationOptions
+	resources  []impostermodel2.Resource
+
+	// requestHashes holds the hashes of the requests recorded so far.
+	requestHashes []string
+
+	// exampleNames holds the name of the example recorded for
+	// each response body, keyed by operation, response and body hash.
+	exampleNames map[string]string
+}
+
+// StartOpenApiRecorder starts a recorder for the given upstream, writing
+// exchanges received on the returned channel to the given dir as an
+// openapi plugin configuration. The spec is copied to the dir, and each
+// response body is added to the copy as a named example of the matching
+// operation, which is referenced by the resource for the exchange.
+func StartOpenApiRecorder(upstream string, dir string, specFile string, options RecorderOptions) (chan HttpExchange, error) {
+	r, err := newOpenApiRecorder(upstream, dir, specFile, options)
+	if err != nil {
+		return nil, err
+	}
+
+	recordC := make(chan HttpExchange)
+	go func() {
+		for {
+			exchange := <-recordC
+			r.record(exchange)
+		}
+	}()
+
+	return recordC, nil
+}
+
+func newOpenApiRecorder(upstream string, dir string, specFile string, options RecorderOptions) (*openapiRecorder, error) {
+	upstreamHost, err := formatUpstreamName(upstream)
+	if err != nil {
+		return nil, err
+	}
+	// check the spec before anything is written, so an unsupported spec is rejected up front
+	if _, err := openapi.LoadDocument(specFile); err != nil {
+		return nil, err
+	}
+	// --spec cannot be combined with --playback, so an existing config is never appended to
+	configFile := path.Join(dir, upstreamHost+"-openapi-config.yaml")
+	if _, err := os.Stat(configFile); err == nil {
+		return nil, fmt.Errorf("config file %s already exists", configFile)
+	}
+	destSpec, err := copyToDir(specFile, dir)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := openapi.LoadDocument(destSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &openapiRecorder{
+		dir:        dir,
+		specFile:   destSpec,
+		configFile: configFile,
+		options:    options,
+		doc:        doc,
+		genOptions: impostermodel2.ConfigGenerationOptions{
+			PluginName:   "openapi",
+			SpecFilePath: destSpec,
+		},
+		exampleNames: make(map[string]string),
+	}, nil
+}
+
+// record adds the response body of the exchange as an example of the
+// operation it matches in the spec, then adds a resource selecting that
+// example and updates the config file. Exchanges that do not match a
+// documented operation and response are not recorded.
+func (r *openapiRecorder) record(exchange HttpExchange) {
+	exchange = r.options.Redaction.Redact(exchange)
+	req := exchange.Request
+	if exchange.WebSocketMessages != nil {
+		logger.Warnf("WebSocket connections are not recorded to OpenAPI specs - skipping %v", req.URL)
+		return
+	}
+	op := r.doc.FindOperation(req.Method, req.URL.Path)
+	if op == nil {
+		logger.Warnf("no operation in spec %s matches %s %v - skipping", r.specFile, req.Method, req.URL)
+		return
+	}
+	responseKey := r.doc.FindResponse(op, exchange.StatusCode)
+	if responseKey == "" {
+		logger.Warnf("status %d is not a documented response of %s %s - skipping %v", exchange.StatusCode, req.Method, op.PathTemplate, req.URL)
+		return
+	}
+	if exchange.ResponseBodyTruncated {
+		logger.Warnf("response body for %s %v exceeded maximum size - skipping", req.Method, req.URL)
+		return
+	}
+	requestHash := getRequestHash(req)
+	if stringutil.Contains(r.requestHashes, requestHash) && r.options.IgnoreDuplicateRequests {
+		logger.Debugf("skipping recording of duplicate request %s %v", req.Method, req.URL)
+		return
+	}
+	r.requestHashes = append(r.requestHashes, requestHash)
+
+	var exampleName string
+	if respBody := *exchange.ResponseBody; len(respBody) > 0 {
+		var err error
+		if exampleName, err = r.addExample(exchange, op, responseKey); err != nil {
+			logger.Warn(err)
+			return
+		}
+	}
+
+	resource, err := buildResource(r.dir, r.options, exchange, "")
+	if err != nil {
+		logger.Warn(err)
+		return
+	}
+	resource.Path = r.doc.BasePath() + op.PathTemplate
+	if len(op.PathParams) > 0 {
+		resource.PathParams = &op.PathParams
+	}
+	resource.Response.ExampleName = exampleName
+	r.resources = append(r.resources, resource)
+
+	if err := updateConfigFile(exchange, r.genOptions, r.resources, r.configFile); err != nil {
+		logger.Warn(err)
+	}
+}
+
+// addExample adds the response body to the spec as an example, unless an
+// identical body has already been recorded for the response, and returns
+// the name of the example.
+func (r *openapiRecorder) addExample(exchange HttpExchange, op *openapi.OperationMatch, responseKey string) (string, error) {
+	req := exchange.Request
+	respBody := *exchange.ResponseBody
+	contentType := exchange.ResponseHeaders.Get("Content-Type")
+	mediaType := r.doc.FindMediaType(op, responseKey, contentType)
+	if mediaType == "" {
+		return "", fmt.Errorf("content type '%s' is not documented for response %s of %s %s - skipping %v", contentType, responseKey, req.Method, op.PathTemplate, req.URL)
+	}
+
+	key := strings.Join([]string{op.Method, op.PathTemplate, responseKey, mediaType, stringutil.Sha1hash(respBody)}, " ")
+	if existing := r.exampleNames[key]; existing != "" {
+		logger.Debugf("reusing identical example %s for %s %v", existing, req.Method, req.URL)
+		return existing, nil
+	}
+
+	exampleName, err := r.doc.AddExample(op, responseKey, mediaType, generateExampleName(exchange), respBody)
+	if err != nil {
+		return "", err
+	}
+	if err := r.doc.Save(r.specFile); err != nil {
+		return "", err
+	}
+	logger.Debugf("added example %s to spec %s for %s %v", exampleName, r.specFile, req.Method, req.URL)
+	r.exampleNames[key] = exampleName
+	return exampleName, nil
+}
+
+// generateExampleName derives a name for an example from the request
+// path and query, such as pets-1 for /pets/1.
+func generateExampleName(exchange HttpExchange) string {
+	reqUrl := exchange.Request.URL
+	name := reqUrl.Path
+	if reqUrl.RawQuery != "" {
+		name += "-" + reqUrl.RawQuery
+	}
+	name = strings.Trim(nonAlphanumeric.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if name == "" {
+		return "root"
+	}
+	return name
+}
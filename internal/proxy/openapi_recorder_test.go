@@ -0,0 +1,129 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/imposter-project/imposter-cli/internal/impostermodel"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func TestOpenApiRecorder_record(t *testing.T) {
+	newExchange := func(method string, target string, status int, respBody string) HttpExchange {
+		body := []byte(respBody)
+		respHeaders := http.Header{"Content-Type": {"application/json"}}
+		return HttpExchange{
+			Request:         httptest.NewRequest(method, target, nil),
+			StatusCode:      status,
+			ResponseBody:    &body,
+			ResponseHeaders: &respHeaders,
+		}
+	}
+
+	dir := t.TempDir()
+	r, err := newOpenApiRecorder("http://localhost:8081", dir, "testdata/pet_store.yaml", RecorderOptions{
+		RecordOnlyResponseHeaders: []string{"Content-Type"},
+	})
+	require.NoError(t, err)
+
+	r.record(newExchange("GET", "/v1/pets/1", 200, `{"id":1,"name":"Fluffy"}`))
+	r.record(newExchange("GET", "/v1/pets/2", 200, `{"id":2,"name":"Rex"}`))
+	r.record(newExchange("GET", "/v1/pets?page=2", 200, `[]`))
+	r.record(newExchange("GET", "/v1/pets/3", 404, ``))
+	r.record(newExchange("GET", "/v1/pets/3", 500, `{"error":"oops"}`))
+	r.record(newExchange("GET", "/v1/orders", 200, `[]`))
+
+	configContent, err := os.ReadFile(filepath.Join(dir, "localhost-8081-openapi-config.yaml"))
+	require.NoError(t, err)
+	var config impostermodel.PluginConfig
+	require.NoError(t, yaml.Unmarshal(configContent, &config))
+
+	require.Equal(t, "openapi", config.Plugin)
+	require.Equal(t, "pet_store.yaml", config.SpecFile)
+	require.Len(t, config.Resources, 4, "undocumented operations and statuses should not be recorded")
+
+	require.Equal(t, "/v1/pets/{petId}", config.Resources[0].Path)
+	require.Equal(t, map[string]string{"petId": "1"}, *config.Resources[0].PathParams)
+	require.Equal(t, 200, config.Resources[0].Response.StatusCode)
+	require.Equal(t, "v1-pets-1", config.Resources[0].Response.ExampleName)
+	require.Empty(t, config.Resources[0].Response.File)
+
+	require.Equal(t, "v1-pets-2", config.Resources[1].Response.ExampleName)
+
+	require.Equal(t, "/v1/pets", config.Resources[2].Path)
+	require.Equal(t, map[string]string{"page": "2"}, *config.Resources[2].QueryParams)
+	require.Equal(t, "v1-pets-page-2", config.Resources[2].Response.ExampleName)
+
+	require.Equal(t, 404, config.Resources[3].Response.StatusCode)
+	require.Empty(t, config.Resources[3].Response.ExampleName, "empty bodies should not be recorded as examples")
+
+	specContent, err := os.ReadFile(filepath.Join(dir, "pet_store.yaml"))
+	require.NoError(t, err)
+	var spec map[string]any
+	require.NoError(t, yaml.Unmarshal(specContent, &spec))
+	examples := spec["paths"].(map[string]any)["/pets/{petId}"].(map[string]any)["get"].(map[string]any)["responses"].(map[string]any)["200"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["examples"].(map[string]any)
+	require.Equal(t, map[string]any{"value": map[string]any{"id": float64(1), "name": "Fluffy"}}, examples["v1-pets-1"])
+	require.Equal(t, map[string]any{"value": map[string]any{"id": float64(2), "name": "Rex"}}, examples["v1-pets-2"])
+
+	original, err := os.ReadFile("testdata/pet_store.yaml")
+	require.NoError(t, err)
+	require.NotContains(t, string(original), "examples", "original spec should not be modified")
+}
+
+func TestOpenApiRecorder_swagger2(t *testing.T) {
+	dir := t.TempDir()
+	specFile := filepath.Join(t.TempDir(), "swagger.yaml")
+	require.NoError(t, os.WriteFile(specFile, []byte("swagger: \"2.0\"\npaths: {}\n"), 0644))
+
+	_, err := newOpenApiRecorder("http://localhost:8081", dir, specFile, RecorderOptions{})
+	require.ErrorContains(t, err, "Swagger 2")
+	require.NoFileExists(t, filepath.Join(dir, "swagger.yaml"), "spec should not be copied")
+}
+
+func TestOpenApiRecorder_existingConfig(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "localhost-8081-openapi-config.yaml"), []byte("plugin: openapi\n"), 0644))
+
+	_, err := newOpenApiRecorder("http://localhost:8081", dir, "testdata/pet_store.yaml", RecorderOptions{AppendToExisting: true})
+	require.ErrorContains(t, err, "already exists")
+}
+
+func TestOpenApiRecorder_identicalBodies(t *testing.T) {
+	dir := t.TempDir()
+	r, err := newOpenApiRecorder("http://localhost:8081", dir, "testdata/pet_store.yaml", RecorderOptions{})
+	require.NoError(t, err)
+
+	for _, target := range []string{"/v1/pets/1", "/v1/pets/2"} {
+		body := []byte(`{"name":"Fluffy"}`)
+		r.record(HttpExchange{
+			Request:         httptest.NewRequest("GET", target, nil),
+			StatusCode:      200,
+			ResponseBody:    &body,
+			ResponseHeaders: &http.Header{"Content-Type": {"application/json"}},
+		})
+	}
+	require.Len(t, r.resources, 2)
+	require.Equal(t, "v1-pets-1", r.resources[0].Response.ExampleName)
+	require.Equal(t, "v1-pets-1", r.resources[1].Response.ExampleName, "identical bodies should share an example")
+}
+
+func TestOpenApiRecorder_duplicateRequests(t *testing.T) {
+	dir := t.TempDir()
+	r, err := newOpenApiRecorder("http://localhost:8081", dir, "testdata/pet_store.yaml", RecorderOptions{IgnoreDuplicateRequests: true})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		body := []byte(`{"name":"Fluffy"}`)
+		r.record(HttpExchange{
+			Request:         httptest.NewRequest("GET", "/v1/pets/1", nil),
+			StatusCode:      200,
+			ResponseBody:    &body,
+			ResponseHeaders: &http.Header{"Content-Type": {"application/json"}},
+		})
+	}
+	require.Len(t, r.resources, 1, "duplicate requests should not be recorded")
+}
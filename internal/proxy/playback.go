@@ -0,0 +1,232 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	impostermodel2 "github.com/imposter-project/imposter-cli/internal/impostermodel"
+)
+
+// Playback serves requests from the resources already recorded for an
+// upstream. The config file is reloaded whenever it changes, so
+// exchanges recorded while the proxy is running are played back too.
+type Playback struct {
+	dir        string
+	configFile string
+	mutex      sync.Mutex
+	modTime    time.Time
+	resources  []impostermodel2.Resource
+}
+
+// NewPlayback returns a Playback for the recordings of the upstream in dir.
+func NewPlayback(upstream string, dir string) (*Playback, error) {
+	upstreamHost, err := formatUpstreamName(upstream)
+	if err != nil {
+		return nil, err
+	}
+	return &Playback{
+		dir:        dir,
+		configFile: getRecordingConfigFile(upstreamHost, dir),
+	}, nil
+}
+
+// Serve writes the recorded response if the request matches a recorded
+// resource, returning true. Otherwise it returns false, and the request
+// should be forwarded to the upstream. If several resources match, the
+// one with the most matchers is used. WebSocket handshakes are only
+// matched by resources recorded from a WebSocket session, which is
+// replayed over the connection.
+func (p *Playback) Serve(w http.ResponseWriter, req *http.Request) bool {
+	resources := p.getResources()
+	if len(resources) == 0 {
+		return false
+	}
+
+	var reqBody *string
+	for _, resource := range resources {
+		if resource.RequestBody != nil {
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				logger.Warnf("failed to read request body for playback of %s %v: %v", req.Method, req.URL, err)
+				return false
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			bodyStr := string(body)
+			reqBody = &bodyStr
+			break
+		}
+	}
+
+	webSocket := IsWebSocketRequest(req)
+	var best *impostermodel2.Resource
+	bestScore := -1
+	for i := range resources {
+		// resources without a response, such as recorded sequences, are replayed by script
+		if resources[i].Response == nil {
+			continue
+		}
+		if (resources[i].Response.StatusCode == http.StatusSwitchingProtocols) != webSocket {
+			continue
+		}
+		if score, ok := matchResource(&resources[i], req, reqBody); ok && score > bestScore {
+			best, bestScore = &resources[i], score
+		}
+	}
+	if best == nil {
+		return false
+	}
+	if webSocket {
+		if err := p.replayWebSocket(w, req, best); err != nil {
+			logger.Warnf("failed to play back WebSocket session for %v - forwarding to upstream: %v", req.URL, err)
+			return false
+		}
+		logger.Infof("played back recorded WebSocket session for %v", req.URL)
+		return true
+	}
+	if err := p.writeResponse(w, best); err != nil {
+		logger.Warnf("failed to play back response for %s %v - forwarding to upstream: %v", req.Method, req.URL, err)
+		return false
+	}
+	logger.Infof("played back recorded response for %s %v", req.Method, req.URL)
+	return true
+}
+
+// getResources returns the recorded resources, reloading the config
+// file if it has changed. If the file cannot be parsed, the previously
+// loaded resources are returned.
+func (p *Playback) getResources() []impostermodel2.Resource {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	info, err := os.Stat(p.configFile)
+	if err != nil {
+		return p.resources
+	}
+	if info.ModTime().Equal(p.modTime) {
+		return p.resources
+	}
+	config, err := loadRecordedConfig(p.configFile)
+	if err != nil {
+		logger.Warn(err)
+		return p.resources
+	}
+	p.modTime = info.ModTime()
+	p.resources = config.Resources
+	logger.Debugf("loaded %d recorded resource(s) from %s", len(p.resources), p.configFile)
+	return p.resources
+}
+
+func (p *Playback) writeResponse(w http.ResponseWriter, resource *impostermodel2.Resource) error {
+	var body []byte
+	response := resource.Response
+	if response.File != "" {
+		var err error
+		if body, err = os.ReadFile(filepath.Join(p.dir, response.File)); err != nil {
+			return err
+		}
+	} else {
+		body = []byte(response.Content)
+	}
+
+	if response.Headers != nil {
+		for name, value := range *response.Headers {
+			w.Header().Set(name, value)
+		}
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	statusCode := response.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	w.WriteHeader(statusCode)
+	_, err := w.Write(body)
+	return err
+}
+
+// matchResource returns true if the request satisfies every matcher of the
+// resource, along with the number of matchers, so that more specific
+// resources can be preferred.
+func matchResource(resource *impostermodel2.Resource, req *http.Request, reqBody *string) (int, bool) {
+	if resource.Method != "" && !strings.EqualFold(resource.Method, req.Method) {
+		return 0, false
+	}
+	score := 0
+	pathParams, ok := matchPathTemplate(resource.Path, req.URL.Path)
+	if !ok {
+		return 0, false
+	}
+	if resource.PathParams != nil {
+		for name, value := range *resource.PathParams {
+			if pathParams[name] != value {
+				return 0, false
+			}
+			score++
+		}
+	}
+	if resource.QueryParams != nil {
+		query := req.URL.Query()
+		for name, value := range *resource.QueryParams {
+			if query.Get(name) != value {
+				return 0, false
+			}
+			score++
+		}
+	}
+	if resource.RequestHeaders != nil {
+		for name, value := range *resource.RequestHeaders {
+			if req.Header.Get(name) != value {
+				return 0, false
+			}
+			score++
+		}
+	}
+	if resource.RequestBody != nil {
+		if reqBody == nil || resource.RequestBody.Operator != "EqualTo" || *reqBody != resource.RequestBody.Value {
+			return 0, false
+		}
+		score++
+	}
+	return score, true
+}
+
+// matchPathTemplate matches a path against a resource path, which may
+// contain '{name}' placeholders, returning the placeholder values.
+func matchPathTemplate(template string, reqPath string) (map[string]string, bool) {
+	templateSegments := strings.Split(strings.Trim(template, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(reqPath, "/"), "/")
+	if len(templateSegments) != len(pathSegments) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, segment := range templateSegments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params[segment[1:len(segment)-1]] = pathSegments[i]
+		} else if segment != pathSegments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
@@ -0,0 +1,189 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/imposter-project/imposter-cli/internal/impostermodel"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func Test_matchPathTemplate(t *testing.T) {
+	tests := []struct {
+		template   string
+		path       string
+		wantParams map[string]string
+		wantOk     bool
+	}{
+		{template: "/users", path: "/users", wantParams: map[string]string{}, wantOk: true},
+		{template: "/users", path: "/users/1", wantOk: false},
+		{template: "/users/{id}", path: "/users/1", wantParams: map[string]string{"id": "1"}, wantOk: true},
+		{template: "/users/{id}/orders/{id2}", path: "/users/1/orders/2", wantParams: map[string]string{"id": "1", "id2": "2"}, wantOk: true},
+		{template: "/users/{id}", path: "/orders/1", wantOk: false},
+		{template: "/", path: "/", wantParams: map[string]string{}, wantOk: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template+" "+tt.path, func(t *testing.T) {
+			params, ok := matchPathTemplate(tt.template, tt.path)
+			require.Equal(t, tt.wantOk, ok)
+			if tt.wantOk {
+				require.Equal(t, tt.wantParams, params)
+			}
+		})
+	}
+}
+
+func Test_matchResource(t *testing.T) {
+	body := `{"a":1}`
+	other := `{"a":2}`
+	tests := []struct {
+		name      string
+		resource  impostermodel.Resource
+		method    string
+		url       string
+		header    http.Header
+		body      *string
+		wantScore int
+		wantOk    bool
+	}{
+		{name: "method and path", resource: impostermodel.Resource{Method: "GET", Path: "/users"}, method: "GET", url: "/users", wantOk: true},
+		{name: "wrong method", resource: impostermodel.Resource{Method: "POST", Path: "/users"}, method: "GET", url: "/users", wantOk: false},
+		{
+			name:     "query params",
+			resource: impostermodel.Resource{Method: "GET", Path: "/users", QueryParams: &map[string]string{"page": "2"}},
+			method:   "GET", url: "/users?page=2&size=10", wantScore: 1, wantOk: true,
+		},
+		{
+			name:     "query param mismatch",
+			resource: impostermodel.Resource{Method: "GET", Path: "/users", QueryParams: &map[string]string{"page": "2"}},
+			method:   "GET", url: "/users?page=3", wantOk: false,
+		},
+		{
+			name:     "path params",
+			resource: impostermodel.Resource{Method: "GET", Path: "/users/{id}", PathParams: &map[string]string{"id": "2"}},
+			method:   "GET", url: "/users/2", wantScore: 1, wantOk: true,
+		},
+		{
+			name:     "path param mismatch",
+			resource: impostermodel.Resource{Method: "GET", Path: "/users/{id}", PathParams: &map[string]string{"id": "2"}},
+			method:   "GET", url: "/users/3", wantOk: false,
+		},
+		{
+			name:     "request headers",
+			resource: impostermodel.Resource{Method: "POST", Path: "/soap", RequestHeaders: &map[string]string{"SOAPAction": "getPet"}},
+			method:   "POST", url: "/soap", header: http.Header{"Soapaction": {"getPet"}}, wantScore: 1, wantOk: true,
+		},
+		{
+			name:     "request body",
+			resource: impostermodel.Resource{Method: "POST", Path: "/users", RequestBody: &impostermodel.RequestBody{Operator: "EqualTo", Value: body}},
+			method:   "POST", url: "/users", body: &body, wantScore: 1, wantOk: true,
+		},
+		{
+			name:     "request body mismatch",
+			resource: impostermodel.Resource{Method: "POST", Path: "/users", RequestBody: &impostermodel.RequestBody{Operator: "EqualTo", Value: body}},
+			method:   "POST", url: "/users", body: &other, wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			for name, values := range tt.header {
+				req.Header[name] = values
+			}
+			score, ok := matchResource(&tt.resource, req, tt.body)
+			require.Equal(t, tt.wantOk, ok)
+			require.Equal(t, tt.wantScore, score)
+		})
+	}
+}
+
+func TestPlayback_Serve(t *testing.T) {
+	dir := t.TempDir()
+	exchange := func(rawUrl string, body string) HttpExchange {
+		reqUrl, _ := url.Parse(rawUrl)
+		respBody := []byte(body)
+		return HttpExchange{
+			Request:         &http.Request{Method: "GET", URL: reqUrl, Header: http.Header{}},
+			StatusCode:      200,
+			ResponseBody:    &respBody,
+			ResponseHeaders: &http.Header{"Content-Type": []string{"application/json"}},
+		}
+	}
+	options := RecorderOptions{AppendToExisting: true}
+	require.NoError(t, RecordExchanges("http://example.com", dir, options, []HttpExchange{
+		exchange("http://example.com/users", `[]`),
+		exchange("http://example.com/users?page=2", `["page 2"]`),
+	}))
+
+	playback, err := NewPlayback("http://example.com", dir)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	require.True(t, playback.Serve(rr, httptest.NewRequest("GET", "/users?page=2", nil)))
+	require.Equal(t, 200, rr.Code)
+	require.Equal(t, `["page 2"]`, rr.Body.String(), "most specific resource should be played back")
+	require.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	rr = httptest.NewRecorder()
+	require.True(t, playback.Serve(rr, httptest.NewRequest("GET", "/users", nil)))
+	require.Equal(t, `[]`, rr.Body.String())
+
+	require.False(t, playback.Serve(httptest.NewRecorder(), httptest.NewRequest("GET", "/orders", nil)))
+
+	// exchanges recorded later are appended, and played back without restarting
+	require.NoError(t, RecordExchanges("http://example.com", dir, options, []HttpExchange{
+		exchange("http://example.com/orders", `["order"]`),
+	}))
+	rr = httptest.NewRecorder()
+	require.True(t, playback.Serve(rr, httptest.NewRequest("GET", "/orders", nil)))
+	require.Equal(t, `["order"]`, rr.Body.String())
+
+	raw, err := os.ReadFile(path.Join(dir, "example.com-config.yaml"))
+	require.NoError(t, err)
+	var config impostermodel.PluginConfig
+	require.NoError(t, yaml.Unmarshal(raw, &config))
+	require.Len(t, config.Resources, 3, "existing resources should be kept")
+
+	// requests recorded in an earlier run are not appended again
+	options.IgnoreDuplicateRequests = true
+	require.NoError(t, RecordExchanges("http://example.com", dir, options, []HttpExchange{
+		exchange("http://example.com/users?page=2", `["changed"]`),
+		exchange("http://example.com/orders", `["changed"]`),
+	}))
+	raw, err = os.ReadFile(path.Join(dir, "example.com-config.yaml"))
+	require.NoError(t, err)
+	config = impostermodel.PluginConfig{}
+	require.NoError(t, yaml.Unmarshal(raw, &config))
+	require.Len(t, config.Resources, 3, "duplicate requests should not be appended")
+}
+
+func TestPlayback_Serve_requestBody(t *testing.T) {
+	dir := t.TempDir()
+	config := impostermodel.GenerateConfig(impostermodel.ConfigGenerationOptions{PluginName: "rest"}, []impostermodel.Resource{{
+		Method:      "POST",
+		Path:        "/search",
+		RequestBody: &impostermodel.RequestBody{Operator: "EqualTo", Value: "query"},
+		Response:    &impostermodel.ResponseConfig{StatusCode: 201, Content: "found"},
+	}})
+	require.NoError(t, os.WriteFile(path.Join(dir, "example.com-config.yaml"), config, 0644))
+
+	playback, err := NewPlayback("http://example.com", dir)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	require.True(t, playback.Serve(rr, httptest.NewRequest("POST", "/search", strings.NewReader("query"))))
+	require.Equal(t, 201, rr.Code)
+	require.Equal(t, "found", rr.Body.String())
+
+	req := httptest.NewRequest("POST", "/search", strings.NewReader("other"))
+	require.False(t, playback.Serve(httptest.NewRecorder(), req))
+	forwarded := make([]byte, 5)
+	_, _ = req.Body.Read(forwarded)
+	require.Equal(t, "other", string(forwarded), "request body should still be available to forward")
+}
@@ -17,25 +17,47 @@ limitations under the License.
 package proxy
 
 import (
-	"bytes"
 	"crypto/tls"
 	"fmt"
 	"github.com/imposter-project/imposter-cli/internal/logging"
 	"github.com/imposter-project/imposter-cli/internal/stringutil"
 	"github.com/spf13/viper"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
+	"strconv"
 	"sync"
 	"time"
 )
 
 type HttpExchange struct {
+	// Upstream is the base URL of the upstream to which the request
+	// was forwarded, such as https://example.com.
+	Upstream string
+
 	Request         *http.Request
 	RequestBody     *[]byte
 	StatusCode      int
 	ResponseBody    *[]byte
 	ResponseHeaders *http.Header
+
+	// ResponseTrailers holds trailers sent by the upstream after the
+	// response body, such as the status of a gRPC call.
+	ResponseTrailers *http.Header
+
+	// WebSocketMessages holds the messages exchanged after a WebSocket
+	// handshake, in the order they were received by the proxy. It is
+	// nil for other exchanges.
+	WebSocketMessages *[]WebSocketMessage
+
+	StartTime time.Time
+	Elapsed   time.Duration
+
+	// RequestBodyTruncated and ResponseBodyTruncated are set if the
+	// body exceeded the maximum size retained for recording.
+	RequestBodyTruncated  bool
+	ResponseBodyTruncated bool
 }
 
 var skipProxyHeaders = []string{
@@ -47,6 +69,7 @@ var skipProxyHeaders = []string{
 	"Keep-Alive",
 	"Proxy-Authenticate",
 	"Proxy-Authorization",
+	"Proxy-Connection",
 	"TE",
 	"Trailers",
 	"Transfer-Encoding",
@@ -100,109 +123,253 @@ func getTransport(insecure bool) *http.Transport {
 	return defaultTransport()
 }
 
+// grpcTransport and insecureGrpcTransport return the shared transports
+// used for gRPC requests, which require HTTP/2.
+var grpcTransport = sync.OnceValue(func() *http.Transport {
+	return newGrpcTransport(false)
+})
+
+var insecureGrpcTransport = sync.OnceValue(func() *http.Transport {
+	return newGrpcTransport(true)
+})
+
+// newGrpcTransport returns a transport that only speaks HTTP/2, using
+// TLS for https upstreams and prior knowledge (h2c) for http upstreams.
+func newGrpcTransport(insecure bool) *http.Transport {
+	t := newTransport(insecure)
+	t.Protocols = new(http.Protocols)
+	t.Protocols.SetHTTP2(true)
+	t.Protocols.SetUnencryptedHTTP2(true)
+	return t
+}
+
+func getGrpcTransport(insecure bool) *http.Transport {
+	if insecure {
+		return insecureGrpcTransport()
+	}
+	return grpcTransport()
+}
+
+// HandleOptions controls how a request is proxied to the upstream.
+type HandleOptions struct {
+	// Insecure skips TLS certificate verification for the upstream.
+	Insecure bool
+
+	// MaxBodySize is the maximum number of bytes of each request and
+	// response body retained for the listener. Bodies are always streamed
+	// in full between the client and the upstream. Zero means no limit.
+	MaxBodySize int64
+
+	// Rewrite, if set, is passed the complete body of text responses
+	// before they are sent to the client. Such responses are buffered,
+	// rather than streamed, so they can be rewritten.
+	Rewrite func(respHeaders *http.Header, respBody *[]byte) *[]byte
+
+	// Chaos, if set, injects faults and latency into matching requests.
+	// Injected faults are not passed to the listener.
+	Chaos *Chaos
+}
+
+// Handle proxies the request to the upstream, streaming the request and
+// response bodies, then passes the completed exchange to the listener.
+// WebSocket connections are relayed until closed, then passed to the
+// listener with the messages exchanged.
 func Handle(
 	upstream string,
 	w http.ResponseWriter,
 	req *http.Request,
-	insecure bool,
-	listener func(reqBody *[]byte, statusCode int, respBody *[]byte, respHeaders *http.Header) (*[]byte, *http.Header),
+	options HandleOptions,
+	listener func(exchange HttpExchange),
 ) {
+	if options.Chaos != nil && options.Chaos.Inject(w, req) {
+		return
+	}
+	if IsWebSocketRequest(req) {
+		handleWebSocket(upstream, w, req, options, listener)
+		return
+	}
 	startTime := time.Now()
 
 	client := req.RemoteAddr
 	logger.Debugf("received request %v %v from client %v", req.Method, req.URL, client)
 
-	path, queryString, clientReqHeaders, requestBody, err := parseRequest(req)
-	if err != nil {
-		logger.Error(err)
-		w.WriteHeader(http.StatusBadRequest)
-		return
-	}
-
-	statusCode, responseBody, respHeaders, err := forward(upstream, req.Method, path, queryString, clientReqHeaders, requestBody, insecure)
+	reqBody := newCappedBuffer(options.MaxBodySize)
+	resp, err := forward(upstream, req, reqBody, options.Insecure)
 	if err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusBadGateway)
 		return
 	}
+	defer resp.Body.Close()
 
-	responseBody, respHeaders = listener(requestBody, statusCode, responseBody, respHeaders)
-
-	err = sendResponse(w, respHeaders, statusCode, responseBody, client)
+	respBody := newCappedBuffer(options.MaxBodySize)
+	respHeaders := resp.Header
+	if options.Rewrite != nil && isRewritable(resp.Header) {
+		err = sendRewrittenResponse(w, resp, respBody, options.Rewrite, client)
+	} else {
+		err = streamResponse(w, resp, respBody, client)
+	}
 	if err != nil {
+		// the status has already been sent, so the client sees a truncated response
 		logger.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
+	// trailers that were not announced, as gRPC servers send them,
+	// are only populated once the body has been read
+	respTrailers := resp.Trailer
 
 	elapsed := time.Since(startTime)
-	logger.Infof("proxied %s %v to upstream [status: %v, body %v bytes] for client %v in %v", req.Method, req.URL, statusCode, len(*responseBody), client, elapsed)
+	requestBody := reqBody.Bytes()
+	responseBody := respBody.Bytes()
+	listener(HttpExchange{
+		Upstream:              upstream,
+		Request:               req,
+		RequestBody:           &requestBody,
+		RequestBodyTruncated:  reqBody.truncated,
+		StatusCode:            resp.StatusCode,
+		ResponseBody:          &responseBody,
+		ResponseBodyTruncated: respBody.truncated,
+		ResponseHeaders:       &respHeaders,
+		ResponseTrailers:      &respTrailers,
+		StartTime:             startTime,
+		Elapsed:               elapsed,
+	})
+
+	logger.Infof("proxied %s %v to upstream [status: %v, body %v bytes] for client %v in %v", req.Method, req.URL, resp.StatusCode, respBody.written, client, elapsed)
 }
 
-func parseRequest(req *http.Request) (path string, queryString string, headers *http.Header, body *[]byte, err error) {
-	defer req.Body.Close()
-	requestBody, err := io.ReadAll(req.Body)
+// forward sends the request to the upstream, streaming the request body
+// and copying it to reqBody as it is sent. The caller must close the
+// body of the response.
+func forward(upstream string, clientReq *http.Request, reqBody io.Writer, insecure bool) (*http.Response, error) {
+	logger.Debugf("invoking upstream %s with %s %s [content length: %v]", upstream, clientReq.Method, clientReq.URL.Path, clientReq.ContentLength)
+	upstreamUrl, err := buildUpstreamUrl(upstream, clientReq)
 	if err != nil {
-		return "", "", nil, nil, fmt.Errorf("error parsing request body: %v", err)
+		return nil, err
 	}
-	return req.URL.Path, req.URL.RawQuery, &req.Header, &requestBody, nil
-}
 
-func forward(
-	upstream string,
-	httpMethod string,
-	path string,
-	queryString string,
-	clientRequestHeaders *http.Header,
-	requestBody *[]byte,
-	insecure bool,
-) (statusCode int, responseBody *[]byte, upstreamRespHeaders *http.Header, err error) {
-	logger.Debugf("invoking upstream %s with %s %s [body: %v bytes]", upstream, httpMethod, path, len(*requestBody))
-
-	upstreamUrl, err := url.JoinPath(upstream, path)
-	if queryString != "" {
-		upstreamUrl += "?" + queryString
+	var body io.Reader = http.NoBody
+	if clientReq.Body != nil && clientReq.Body != http.NoBody && clientReq.ContentLength != 0 {
+		body = io.TeeReader(clientReq.Body, reqBody)
 	}
+	req, err := http.NewRequest(clientReq.Method, upstreamUrl, body)
 	if err != nil {
-		return 0, nil, nil, fmt.Errorf("failed to build upstream URL: %v", err)
+		return nil, fmt.Errorf("failed to build upstream request: %v", err)
 	}
-	logger.Tracef("upstream url: %s", upstreamUrl)
-
-	req, err := http.NewRequest(httpMethod, upstreamUrl, bytes.NewReader(*requestBody))
+	req.ContentLength = clientReq.ContentLength
 	upstreamReqHeaders := req.Header
-	copyHeaders(clientRequestHeaders, &upstreamReqHeaders)
+	copyHeaders(&clientReq.Header, &upstreamReqHeaders)
 
-	client := &http.Client{Transport: getTransport(insecure)}
+	transport := getTransport(insecure)
+	if IsGrpcRequest(clientReq) {
+		// TE is a hop-by-hop header, but gRPC servers require it
+		req.Header.Set("TE", "trailers")
+		transport = getGrpcTransport(insecure)
+	}
+	client := &http.Client{Transport: transport}
 	resp, err := client.Do(req)
 	if err != nil {
-		return 0, nil, nil, err
+		return nil, err
 	}
-	defer resp.Body.Close()
+	logger.Debugf("upstream responded to %s %s with status %d [content length: %v]", clientReq.Method, upstreamUrl, resp.StatusCode, resp.ContentLength)
+	return resp, nil
+}
 
-	var respBody []byte
-	if resp.Body != nil {
-		respBody, err = io.ReadAll(resp.Body)
-		if err != nil {
-			return 0, nil, nil, fmt.Errorf("error parsing request body: %v", err)
-		}
+// buildUpstreamUrl returns the URL of the upstream resource for the request.
+func buildUpstreamUrl(upstream string, clientReq *http.Request) (string, error) {
+	upstreamUrl, err := url.JoinPath(upstream, clientReq.URL.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to build upstream URL: %v", err)
 	}
-	logger.Debugf("upstream responded to %s %s with status %d [body %v bytes]", httpMethod, upstreamUrl, resp.StatusCode, len(respBody))
-	return resp.StatusCode, &respBody, &resp.Header, nil
+	if queryString := clientReq.URL.RawQuery; queryString != "" {
+		upstreamUrl += "?" + queryString
+	}
+	logger.Tracef("upstream url: %s", upstreamUrl)
+	return upstreamUrl, nil
 }
 
-func sendResponse(w http.ResponseWriter, headers *http.Header, statusCode int, body *[]byte, client string) (err error) {
+// streamResponse copies the upstream response to the client as it is
+// received, flushing after each read so that streamed responses, such
+// as server-sent events, reach the client promptly. Any trailers are
+// sent after the body.
+func streamResponse(w http.ResponseWriter, resp *http.Response, respBody *cappedBuffer, client string) error {
 	clientRespHeaders := w.Header()
-	copyHeaders(headers, &clientRespHeaders)
-	w.WriteHeader(statusCode)
-	_, err = w.Write(*body)
+	copyHeaders(&resp.Header, &clientRespHeaders)
+	w.WriteHeader(resp.StatusCode)
+
+	flusher, _ := w.(http.Flusher)
+	buf := make([]byte, 32*1024)
+	for {
+		n, readErr := resp.Body.Read(buf)
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return fmt.Errorf("error writing response to client %v: %v", client, err)
+			}
+			_, _ = respBody.Write(buf[:n])
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if readErr == io.EOF {
+			break
+		} else if readErr != nil {
+			return fmt.Errorf("error reading upstream response body: %v", readErr)
+		}
+	}
+
+	// trailers are only available once the body has been read
+	for trailerName, trailerValues := range resp.Trailer {
+		for _, trailerValue := range trailerValues {
+			clientRespHeaders.Add(http.TrailerPrefix+trailerName, trailerValue)
+		}
+	}
+
+	logger.Debugf("streamed response [status: %v, body %v bytes] to client %v", resp.StatusCode, respBody.written, client)
+	return nil
+}
+
+// sendRewrittenResponse reads the complete upstream response, passes it
+// to the rewrite function, then sends the result to the client.
+func sendRewrittenResponse(
+	w http.ResponseWriter,
+	resp *http.Response,
+	respBody *cappedBuffer,
+	rewrite func(respHeaders *http.Header, respBody *[]byte) *[]byte,
+	client string,
+) error {
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("error writing response: %v", err)
+		w.WriteHeader(http.StatusBadGateway)
+		return fmt.Errorf("error reading upstream response body: %v", err)
+	}
+	rewritten := rewrite(&resp.Header, &body)
+
+	clientRespHeaders := w.Header()
+	copyHeaders(&resp.Header, &clientRespHeaders)
+	clientRespHeaders.Set("Content-Length", strconv.Itoa(len(*rewritten)))
+	w.WriteHeader(resp.StatusCode)
+	if _, err = w.Write(*rewritten); err != nil {
+		return fmt.Errorf("error writing response to client %v: %v", client, err)
 	}
+	_, _ = respBody.Write(*rewritten)
 
-	logger.Debugf("wrote response [status: %v, body %v bytes] to client %v", statusCode, len(*body), client)
+	logger.Debugf("wrote rewritten response [status: %v, body %v bytes] to client %v", resp.StatusCode, len(*rewritten), client)
 	return nil
 }
 
+// isRewritable returns true if the response can be buffered for rewriting.
+// Event streams are never buffered, as they may not end.
+func isRewritable(respHeaders http.Header) bool {
+	contentType := respHeaders.Get("Content-Type")
+	if contentType == "" {
+		return false
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType == "text/event-stream" {
+		return false
+	}
+	return isTextContentType(contentType)
+}
+
 // copyHeaders copies all headers from source to destination, unless the name
 // of the header is a hop-by-hop header.
 func copyHeaders(source *http.Header, destination *http.Header) {
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -163,16 +164,15 @@ func TestHandle(t *testing.T) {
 			var capturedRespBody *[]byte
 			var capturedRespHeaders *http.Header
 
-			// Set up a listener function that captures the response and passes it through unchanged
-			listenerFn := func(reqBody *[]byte, statusCode int, respBody *[]byte, respHeaders *http.Header) (*[]byte, *http.Header) {
-				capturedStatusCode = statusCode
-				capturedRespBody = respBody
-				capturedRespHeaders = respHeaders
-				return respBody, respHeaders
+			// Set up a listener function that captures the exchange
+			listenerFn := func(exchange HttpExchange) {
+				capturedStatusCode = exchange.StatusCode
+				capturedRespBody = exchange.ResponseBody
+				capturedRespHeaders = exchange.ResponseHeaders
 			}
 
 			// Call the Handle function with our test server as upstream
-			Handle(server.URL, rr, req, false, listenerFn)
+			Handle(server.URL, rr, req, HandleOptions{}, listenerFn)
 
 			// Verify status code
 			if capturedStatusCode != tc.statusCode {
@@ -249,12 +249,11 @@ func TestHandleInsecureTLS(t *testing.T) {
 
 			rr := httptest.NewRecorder()
 			var listenerCalled bool
-			listener := func(reqBody *[]byte, statusCode int, respBody *[]byte, respHeaders *http.Header) (*[]byte, *http.Header) {
+			listener := func(exchange HttpExchange) {
 				listenerCalled = true
-				return respBody, respHeaders
 			}
 
-			Handle(upstream.URL, rr, req, tc.insecure, listener)
+			Handle(upstream.URL, rr, req, HandleOptions{Insecure: tc.insecure}, listener)
 
 			if tc.expectUpstream {
 				if !listenerCalled {
@@ -329,10 +328,7 @@ func TestHandleEndToEnd(t *testing.T) {
 		w.Write([]byte(`{"status":"OK"}`))
 	})
 	proxyMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		Handle(upstreamURL, w, r, false, func(reqBody *[]byte, statusCode int, respBody *[]byte, respHeaders *http.Header) (*[]byte, *http.Header) {
-			// Pass through unchanged
-			return respBody, respHeaders
-		})
+		Handle(upstreamURL, w, r, HandleOptions{}, func(exchange HttpExchange) {})
 	})
 	proxyPort := enginetests.GetFreePort()
 	proxyServer := &http.Server{Addr: fmt.Sprintf(":%d", proxyPort), Handler: proxyMux}
@@ -425,3 +421,113 @@ func TestHandleEndToEnd(t *testing.T) {
 		})
 	}
 }
+
+// TestHandleStreaming verifies that the response is streamed to the client
+// before the upstream has finished sending it, as for server-sent events.
+func TestHandleStreaming(t *testing.T) {
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("data: first\n\n"))
+		w.(http.Flusher).Flush()
+		<-release
+		_, _ = w.Write([]byte("data: second\n\n"))
+	}))
+	defer upstream.Close()
+
+	exchanges := make(chan HttpExchange, 1)
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Handle(upstream.URL, w, r, HandleOptions{
+			Rewrite: func(respHeaders *http.Header, respBody *[]byte) *[]byte {
+				t.Errorf("event streams should not be buffered for rewriting")
+				return respBody
+			},
+		}, func(exchange HttpExchange) {
+			exchanges <- exchange
+		})
+	}))
+	defer proxyServer.Close()
+
+	resp, err := http.Get(proxyServer.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	first := make([]byte, len("data: first\n\n"))
+	if _, err := io.ReadFull(resp.Body, first); err != nil {
+		t.Fatalf("failed to read first event before upstream completed: %v", err)
+	}
+	close(release)
+	rest, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(rest) != "data: second\n\n" {
+		t.Errorf("unexpected remainder of stream: %q", rest)
+	}
+
+	exchange := <-exchanges
+	if string(*exchange.ResponseBody) != "data: first\n\ndata: second\n\n" {
+		t.Errorf("expected full stream to be passed to listener, got %q", *exchange.ResponseBody)
+	}
+}
+
+func TestHandleMaxBodySize(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqBody, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("echo: " + string(reqBody)))
+	}))
+	defer upstream.Close()
+
+	req := httptest.NewRequest("POST", "/echo", strings.NewReader("0123456789"))
+	rr := httptest.NewRecorder()
+	var captured HttpExchange
+	Handle(upstream.URL, rr, req, HandleOptions{MaxBodySize: 4}, func(exchange HttpExchange) {
+		captured = exchange
+	})
+
+	if rr.Body.String() != "echo: 0123456789" {
+		t.Errorf("expected full body to be proxied, got %q", rr.Body.String())
+	}
+	if string(*captured.RequestBody) != "0123" || !captured.RequestBodyTruncated {
+		t.Errorf("expected truncated request body, got %q (truncated: %v)", *captured.RequestBody, captured.RequestBodyTruncated)
+	}
+	if string(*captured.ResponseBody) != "echo" || !captured.ResponseBodyTruncated {
+		t.Errorf("expected truncated response body, got %q (truncated: %v)", *captured.ResponseBody, captured.ResponseBodyTruncated)
+	}
+}
+
+func TestHandleRewrite(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", "20")
+		_, _ = w.Write([]byte(`{"next":"/upstream"}`))
+	}))
+	defer upstream.Close()
+
+	req := httptest.NewRequest("GET", "/", nil)
+	rr := httptest.NewRecorder()
+	var captured HttpExchange
+	Handle(upstream.URL, rr, req, HandleOptions{
+		Rewrite: func(respHeaders *http.Header, respBody *[]byte) *[]byte {
+			rewritten := []byte(strings.ReplaceAll(string(*respBody), "/upstream", "/rewritten-path"))
+			return &rewritten
+		},
+	}, func(exchange HttpExchange) {
+		captured = exchange
+	})
+
+	want := `{"next":"/rewritten-path"}`
+	if rr.Body.String() != want {
+		t.Errorf("expected rewritten body %q, got %q", want, rr.Body.String())
+	}
+	if rr.Header().Get("Content-Length") != fmt.Sprint(len(want)) {
+		t.Errorf("expected content length of rewritten body, got %s", rr.Header().Get("Content-Length"))
+	}
+	if string(*captured.ResponseBody) != want {
+		t.Errorf("expected rewritten body to be recorded, got %q", *captured.ResponseBody)
+	}
+}
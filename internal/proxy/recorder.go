@@ -29,6 +29,7 @@ import (
 	"github.com/google/uuid"
 	impostermodel2 "github.com/imposter-project/imposter-cli/internal/impostermodel"
 	"github.com/imposter-project/imposter-cli/internal/stringutil"
+	"sigs.k8s.io/yaml"
 )
 
 type RecorderOptions struct {
@@ -37,56 +38,254 @@ type RecorderOptions struct {
 	IgnoreDuplicateRequests   bool
 	RecordOnlyResponseHeaders []string
 	FlatResponseFileStructure bool
+
+	// SkipTruncatedBodies omits response bodies that exceeded the maximum
+	// recorded size, instead of writing a truncated response file.
+	SkipTruncatedBodies bool
+
+	// TemplatePaths collapses identifier-like path segments, such as
+	// numeric IDs or UUIDs, into path parameters.
+	TemplatePaths bool
+
+	// Redaction replaces secrets in each exchange before it is recorded.
+	Redaction *RedactionRules
+
+	// AppendToExisting adds resources to an existing config file for the
+	// upstream, instead of failing if one exists.
+	AppendToExisting bool
+
+	// RecordSequences records the responses to repeated requests with the
+	// same method and URL as a sequence, which is replayed in order,
+	// instead of ignoring or duplicating them.
+	RecordSequences bool
+}
+
+type recorder struct {
+	upstreamHost   string
+	dir            string
+	configFile     string
+	options        RecorderOptions
+	genOptions     impostermodel2.ConfigGenerationOptions
+	resources      []impostermodel2.Resource
+	requestHashes  []string
+	responseHashes map[string]string
+
+	// templateResponses holds the response for the first exchange
+	// recorded for each templated path, keyed by method and path.
+	templateResponses map[string]string
+
+	// sequences holds the responses recorded for each request, keyed by
+	// request hash, if sequences are recorded.
+	sequences map[string]*sequence
 }
 
+// StartRecorder starts a recorder for the given upstream, writing
+// exchanges received on the returned channel to the given dir.
 func StartRecorder(upstream string, dir string, options RecorderOptions) (chan HttpExchange, error) {
-	upstreamHost, err := formatUpstreamHostPort(upstream)
+	r, err := newRecorder(upstream, dir, options)
 	if err != nil {
 		return nil, err
 	}
-	configFile := path.Join(dir, upstreamHost+"-config.yaml")
-	if _, err := os.Stat(configFile); err == nil {
-		return nil, fmt.Errorf("config file %s already exists", configFile)
-	}
-
-	var resources []impostermodel2.Resource
-	genOptions := impostermodel2.ConfigGenerationOptions{PluginName: "rest"}
-
-	var requestHashes []string
-	responseHashes := make(map[string]string)
 
 	recordC := make(chan HttpExchange)
 	go func() {
 		for {
 			exchange := <-recordC
+			r.record(exchange)
+		}
+	}()
 
-			var responseFilePrefix string
-			requestHash := getRequestHash(exchange.Request)
-			if stringutil.Contains(requestHashes, requestHash) {
-				if options.IgnoreDuplicateRequests {
-					logger.Debugf("skipping recording of duplicate request %s %v", exchange.Request.Method, exchange.Request.URL)
-					continue
-				}
-				responseFilePrefix = uuid.New().String() + "-"
-			} else {
-				responseFilePrefix = ""
-			}
-			requestHashes = append(requestHashes, requestHash)
+	return recordC, nil
+}
 
-			resource, err := record(upstreamHost, dir, &responseHashes, responseFilePrefix, exchange, options)
-			if err != nil {
-				logger.Warn(err)
-				continue
-			}
-			resources = append(resources, *resource)
+// RecordExchanges records the given exchanges for the upstream, in order,
+// returning once all exchanges have been written to the given dir.
+func RecordExchanges(upstream string, dir string, options RecorderOptions, exchanges []HttpExchange) error {
+	r, err := newRecorder(upstream, dir, options)
+	if err != nil {
+		return err
+	}
+	for _, exchange := range exchanges {
+		r.record(exchange)
+	}
+	return nil
+}
 
-			if err := updateConfigFile(exchange, genOptions, resources, configFile); err != nil {
-				logger.Warn(err)
-			}
+func newRecorder(upstream string, dir string, options RecorderOptions) (*recorder, error) {
+	upstreamHost, err := formatUpstreamName(upstream)
+	if err != nil {
+		return nil, err
+	}
+	configFile := getRecordingConfigFile(upstreamHost, dir)
+	var resources []impostermodel2.Resource
+	if _, err := os.Stat(configFile); err == nil {
+		if !options.AppendToExisting {
+			return nil, fmt.Errorf("config file %s already exists", configFile)
 		}
-	}()
+		existing, err := loadRecordedConfig(configFile)
+		if err != nil {
+			return nil, err
+		}
+		resources = existing.Resources
+		logger.Infof("appending to %d existing resource(s) in %s", len(resources), configFile)
+	}
+	var requestHashes []string
+	for _, resource := range resources {
+		requestHashes = append(requestHashes, getResourceHash(resource))
+	}
+	return &recorder{
+		upstreamHost:      upstreamHost,
+		dir:               dir,
+		configFile:        configFile,
+		options:           options,
+		genOptions:        impostermodel2.ConfigGenerationOptions{PluginName: "rest"},
+		resources:         resources,
+		requestHashes:     requestHashes,
+		responseHashes:    make(map[string]string),
+		templateResponses: make(map[string]string),
+		sequences:         make(map[string]*sequence),
+	}, nil
+}
 
-	return recordC, nil
+// getRecordingConfigFile returns the path of the config file
+// to which exchanges with the upstream host are recorded.
+func getRecordingConfigFile(upstreamHost string, dir string) string {
+	return path.Join(dir, upstreamHost+"-config.yaml")
+}
+
+func loadRecordedConfig(configFile string) (*impostermodel2.PluginConfig, error) {
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %v", configFile, err)
+	}
+	var config impostermodel2.PluginConfig
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %v", configFile, err)
+	}
+	return &config, nil
+}
+
+// record writes the response file and resource for the exchange, then
+// updates the config file. WebSocket exchanges are written to a session
+// file, which the resource references instead. Redaction rules are applied first, so secrets are never
+// written to disk, and request matchers for redacted values are omitted.
+// Failures are logged, so that a single bad exchange does not prevent
+// subsequent exchanges being recorded.
+func (r *recorder) record(original HttpExchange) {
+	exchange := r.options.Redaction.Redact(original)
+	if exchange.WebSocketMessages != nil {
+		if err := r.recordWebSocket(original, exchange); err != nil {
+			logger.Warn(err)
+		}
+		return
+	}
+
+	var responseFilePrefix string
+	requestHash := getRequestHash(exchange.Request)
+	if stringutil.Contains(r.requestHashes, requestHash) {
+		if seq := r.sequences[requestHash]; seq != nil {
+			r.recordSequenceResponse(exchange, seq)
+			return
+		}
+		if r.options.IgnoreDuplicateRequests {
+			logger.Debugf("skipping recording of duplicate request %s %v", exchange.Request.Method, exchange.Request.URL)
+			return
+		}
+		responseFilePrefix = uuid.New().String() + "-"
+	} else {
+		responseFilePrefix = ""
+	}
+	r.requestHashes = append(r.requestHashes, requestHash)
+
+	resource, err := record(r.upstreamHost, r.dir, &r.responseHashes, responseFilePrefix, exchange, r.options)
+	if err != nil {
+		logger.Warn(err)
+		return
+	}
+	omitRedactedMatchers(resource, original, exchange)
+	if r.options.TemplatePaths && !r.applyPathTemplate(exchange, resource) {
+		return
+	}
+	r.resources = append(r.resources, *resource)
+	if r.options.RecordSequences {
+		r.sequences[requestHash] = &sequence{
+			key:           exchange.Request.Method + " " + exchange.Request.URL.String(),
+			resourceIndex: len(r.resources) - 1,
+			responses:     []sequenceResponse{newSequenceResponse(resource.Response)},
+		}
+	}
+
+	if err := updateConfigFile(exchange, r.genOptions, r.resources, r.configFile); err != nil {
+		logger.Warn(err)
+	}
+}
+
+// recordSequenceResponse adds the response for a repeated request to its
+// sequence. Once the sequence contains different responses, the resource
+// for the request replays them in order, so replay follows the same
+// progression as the upstream, such as when polling the status of a job.
+func (r *recorder) recordSequenceResponse(exchange HttpExchange, seq *sequence) {
+	prefix := fmt.Sprintf("%d-", len(seq.responses)+1)
+	resource, err := record(r.upstreamHost, r.dir, &r.responseHashes, prefix, exchange, r.options)
+	if err != nil {
+		logger.Warn(err)
+		return
+	}
+	seq.responses = append(seq.responses, newSequenceResponse(resource.Response))
+	if err := seq.applyTo(&r.resources[seq.resourceIndex]); err != nil {
+		logger.Warn(err)
+		return
+	}
+	logger.Debugf("recorded response %d in sequence for %s", len(seq.responses), seq.key)
+
+	if err := updateConfigFile(exchange, r.genOptions, r.resources, r.configFile); err != nil {
+		logger.Warn(err)
+	}
+}
+
+// applyPathTemplate replaces the path of the resource with its templated
+// form. The first exchange for a templated path is recorded without path
+// parameters, so it matches any value. Later exchanges with a different
+// response are recorded with a path parameter matcher for their value.
+// Returns false if the resource is redundant and should not be recorded.
+func (r *recorder) applyPathTemplate(exchange HttpExchange, resource *impostermodel2.Resource) bool {
+	templatedPath, params := templatePath(exchange.Request.URL.Path)
+	if len(params) == 0 {
+		return true
+	}
+	resource.Path = templatedPath
+
+	// response files are deduplicated by content, so the file identifies the body
+	key := exchange.Request.Method + " " + templatedPath
+	response := fmt.Sprintf("%d:%s", resource.Response.StatusCode, resource.Response.File)
+
+	first, seen := r.templateResponses[key]
+	if !seen {
+		r.templateResponses[key] = response
+		return true
+	}
+	if first == response {
+		logger.Debugf("response for %s %v matches templated resource %s - skipping", exchange.Request.Method, exchange.Request.URL, key)
+		return false
+	}
+	resource.PathParams = &params
+	return true
+}
+
+// formatUpstreamName returns the name of the files recorded for the
+// upstream, made up of its host, port and any base path, such as
+// example.com-8080-api-v1 for http://example.com:8080/api/v1, so that
+// upstreams on the same host with different base paths do not collide.
+func formatUpstreamName(upstream string) (string, error) {
+	name, err := formatUpstreamHostPort(upstream)
+	if err != nil {
+		return "", err
+	}
+	upstreamUrl, _ := url.Parse(upstream)
+	if basePath := strings.Trim(nonAlphanumeric.ReplaceAllString(strings.ToLower(upstreamUrl.Path), "-"), "-"); basePath != "" {
+		name += "-" + basePath
+	}
+	return name, nil
 }
 
 func formatUpstreamHostPort(upstream string) (string, error) {
@@ -145,6 +344,13 @@ func getResponseFile(
 		logger.Debugf("empty response body for %s %v", req.Method, req.URL)
 		return "", nil
 	}
+	if exchange.ResponseBodyTruncated {
+		if options.SkipTruncatedBodies {
+			logger.Warnf("response body for %s %v exceeded maximum size - skipping response file", req.Method, req.URL)
+			return "", nil
+		}
+		logger.Warnf("response body for %s %v exceeded maximum size - response file will be truncated to %d bytes", req.Method, req.URL, len(respBody))
+	}
 	bodyHash := stringutil.Sha1hash(respBody)
 
 	if existing := (*fileHashes)[bodyHash]; existing != "" {
@@ -216,7 +422,9 @@ func buildResource(
 	}
 	if options.CaptureRequestBody && exchange.RequestBody != nil {
 		contentType := req.Header.Get("Content-Type")
-		if !isTextContentType(contentType) {
+		if exchange.RequestBodyTruncated {
+			logger.Debugf("request body exceeded maximum size - skipping request body capture")
+		} else if !isTextContentType(contentType) {
 			logger.Debugf("unsupported content type '%s' for capture - skipping request body capture", contentType)
 		} else {
 			reqBody := *exchange.RequestBody
@@ -246,21 +454,55 @@ func buildResource(
 }
 
 // getRequestHash generates a hash for a request based on the HTTP method,
-// URL, and SOAP action (if present). This ensures that SOAP operations
-// sharing the same endpoint URL are treated as distinct requests.
+// path, query parameters, SOAP action and Upgrade header (if present).
+// This ensures that SOAP operations sharing the same endpoint URL, and
+// WebSocket handshakes, are treated as distinct requests. Query
+// parameters are sorted, so the hash does not depend on their order.
 func getRequestHash(req *http.Request) string {
-	key := req.Method + req.URL.String()
+	key := req.Method + req.URL.Path + "?" + req.URL.Query().Encode()
 	if soapAction := extractSoapAction(req); soapAction != "" {
 		key += soapAction
 	}
+	if upgrade := req.Header.Get("Upgrade"); upgrade != "" {
+		key += " upgrade=" + strings.ToLower(upgrade)
+	}
 	return stringutil.Sha1hashString(key)
 }
 
+// getResourceHash generates the hash of the request from which a
+// resource was recorded, so that requests recorded in an earlier
+// run are treated as duplicates when appending to the config file.
+func getResourceHash(resource impostermodel2.Resource) string {
+	req := &http.Request{
+		Method: resource.Method,
+		URL:    &url.URL{Path: resource.Path},
+		Header: http.Header{},
+	}
+	if resource.QueryParams != nil {
+		query := url.Values{}
+		for name, value := range *resource.QueryParams {
+			query.Set(name, value)
+		}
+		req.URL.RawQuery = query.Encode()
+	}
+	if resource.RequestHeaders != nil {
+		for name, value := range *resource.RequestHeaders {
+			req.Header.Set(name, value)
+		}
+	}
+	return getRequestHash(req)
+}
+
 func updateConfigFile(exchange HttpExchange, options impostermodel2.ConfigGenerationOptions, resources []impostermodel2.Resource, configFile string) error {
 	req := exchange.Request
 	config := impostermodel2.GenerateConfig(options, resources)
-	err := os.WriteFile(configFile, config, 0644)
-	if err != nil {
+
+	// write then rename, so the config file is never seen partially written
+	tmpFile := configFile + ".tmp"
+	if err := os.WriteFile(tmpFile, config, 0644); err != nil {
+		return fmt.Errorf("failed to write config file %s for %s %v: %v", configFile, req.Method, req.URL, err)
+	}
+	if err := os.Rename(tmpFile, configFile); err != nil {
 		return fmt.Errorf("failed to write config file %s for %s %v: %v", configFile, req.Method, req.URL, err)
 	}
 	logger.Debugf("wrote config file %s for %s %v", configFile, req.Method, req.URL)
@@ -8,7 +8,10 @@ import (
 	"os"
 	"path"
 	"reflect"
+	"strings"
 	"testing"
+
+	"sigs.k8s.io/yaml"
 )
 
 func Test_getResponseFile(t *testing.T) {
@@ -82,6 +85,23 @@ func Test_getResponseFile(t *testing.T) {
 			want:    path.Join(outputDir, "GET-index.txt"),
 			wantErr: false,
 		},
+		{
+			name: "truncated response body skipped",
+			args: args{
+				upstreamHost: "example.com",
+				dir:          outputDir,
+				options:      RecorderOptions{SkipTruncatedBodies: true},
+				exchange: HttpExchange{
+					Request:               &http.Request{Method: "GET", URL: rootUrl},
+					ResponseBody:          &responseBody,
+					ResponseBodyTruncated: true,
+					ResponseHeaders:       &http.Header{},
+				},
+				fileHashes: buildMap(outputDir, []string{}),
+			},
+			want:    "",
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -300,3 +320,179 @@ func buildMap(dir string, hashes []string) *map[string]string {
 	}
 	return &m
 }
+
+func TestRecordExchanges_templatePaths(t *testing.T) {
+	outputDir := t.TempDir()
+	exchange := func(rawUrl string, body string) HttpExchange {
+		reqUrl, _ := url.Parse(rawUrl)
+		respBody := []byte(body)
+		return HttpExchange{
+			Request:         &http.Request{Method: "GET", URL: reqUrl, Header: http.Header{}},
+			StatusCode:      200,
+			ResponseBody:    &respBody,
+			ResponseHeaders: &http.Header{"Content-Type": []string{"application/json"}},
+		}
+	}
+	err := RecordExchanges("http://example.com", outputDir, RecorderOptions{TemplatePaths: true}, []HttpExchange{
+		exchange("http://example.com/users/1", `{"name":"Alice"}`),
+		exchange("http://example.com/users/2", `{"name":"Bob"}`),
+		exchange("http://example.com/users/3", `{"name":"Alice"}`),
+		exchange("http://example.com/users", `[]`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(path.Join(outputDir, "example.com-config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config impostermodel.PluginConfig
+	if err := yaml.Unmarshal(raw, &config); err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Resources) != 3 {
+		t.Fatalf("expected 3 resources, got %d: %+v", len(config.Resources), config.Resources)
+	}
+
+	generic := config.Resources[0]
+	if generic.Path != "/users/{id}" || generic.PathParams != nil {
+		t.Errorf("expected generic templated resource, got path %s with params %v", generic.Path, generic.PathParams)
+	}
+	specific := config.Resources[1]
+	if specific.Path != "/users/{id}" || specific.PathParams == nil || (*specific.PathParams)["id"] != "2" {
+		t.Errorf("expected resource matching id 2, got path %s with params %v", specific.Path, specific.PathParams)
+	}
+	if specific.Response.File == generic.Response.File {
+		t.Errorf("expected distinct response file for id 2")
+	}
+	if config.Resources[2].Path != "/users" {
+		t.Errorf("expected literal path to be unchanged, got %s", config.Resources[2].Path)
+	}
+}
+
+func TestRecordExchanges_redaction(t *testing.T) {
+	outputDir := t.TempDir()
+	rules, err := NewRedactionRules(DefaultRedactedHeaders, []string{"$.token"}, nil, []string{"api_key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reqUrl, _ := url.Parse("http://example.com/session?api_key=secret-value&page=1")
+	respBody := []byte(`{"token":"secret-value"}`)
+	err = RecordExchanges("http://example.com", outputDir, RecorderOptions{CaptureRequestHeaders: true, Redaction: rules}, []HttpExchange{{
+		Request:      &http.Request{Method: "GET", URL: reqUrl, Header: http.Header{"Authorization": {"Bearer secret-value"}, "X-Tenant": {"acme"}}},
+		StatusCode:   200,
+		ResponseBody: &respBody,
+		ResponseHeaders: &http.Header{
+			"Content-Type": {"application/json"},
+			"Set-Cookie":   {"session=secret-value"},
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(path.Join(outputDir, "example.com-config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config impostermodel.PluginConfig
+	if err := yaml.Unmarshal(raw, &config); err != nil {
+		t.Fatal(err)
+	}
+	resource := config.Resources[0]
+	if _, found := (*resource.RequestHeaders)["Authorization"]; found {
+		t.Errorf("expected redacted Authorization header to be omitted from matchers, got %v", *resource.RequestHeaders)
+	}
+	if (*resource.RequestHeaders)["X-Tenant"] != "acme" {
+		t.Errorf("expected X-Tenant header to be matched, got %v", *resource.RequestHeaders)
+	}
+	if _, found := (*resource.QueryParams)["api_key"]; found {
+		t.Errorf("expected redacted api_key query parameter to be omitted from matchers, got %v", *resource.QueryParams)
+	}
+	if (*resource.QueryParams)["page"] != "1" {
+		t.Errorf("expected page query parameter to be matched, got %v", *resource.QueryParams)
+	}
+	if (*resource.Response.Headers)["Set-Cookie"] != RedactedPlaceholder {
+		t.Errorf("expected Set-Cookie header to be redacted, got %v", *resource.Response.Headers)
+	}
+	recorded, err := os.ReadFile(path.Join(outputDir, resource.Response.File))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(recorded) != `{"token":"REDACTED"}` {
+		t.Errorf("expected response body to be redacted, got %s", recorded)
+	}
+}
+
+func TestRecordExchanges_sequences(t *testing.T) {
+	newExchange := func(rawUrl string, body string) HttpExchange {
+		reqUrl, _ := url.Parse(rawUrl)
+		respBody := []byte(body)
+		return HttpExchange{
+			Request:         &http.Request{Method: "GET", URL: reqUrl, Header: http.Header{}},
+			StatusCode:      200,
+			ResponseBody:    &respBody,
+			ResponseHeaders: &http.Header{"Content-Type": {"application/json"}},
+		}
+	}
+	outputDir := t.TempDir()
+	err := RecordExchanges("http://example.com", outputDir, RecorderOptions{IgnoreDuplicateRequests: true, RecordSequences: true}, []HttpExchange{
+		newExchange("http://example.com/jobs/1", `{"status":"pending"}`),
+		newExchange("http://example.com/jobs/1", `{"status":"pending"}`),
+		newExchange("http://example.com/jobs/1", `{"status":"done"}`),
+		newExchange("http://example.com/jobs/2", `{"status":"done"}`),
+		newExchange("http://example.com/jobs/2", `{"status":"done"}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(path.Join(outputDir, "example.com-config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config impostermodel.PluginConfig
+	if err := yaml.Unmarshal(raw, &config); err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Resources) != 2 {
+		t.Fatalf("expected one resource per request, got %d", len(config.Resources))
+	}
+
+	jobOne := config.Resources[0]
+	if jobOne.Response != nil {
+		t.Errorf("expected sequence to replace response, got %+v", *jobOne.Response)
+	}
+	if jobOne.Steps == nil || len(*jobOne.Steps) != 1 {
+		t.Fatalf("expected a script step for the sequence, got %v", jobOne.Steps)
+	}
+	step := (*jobOne.Steps)[0]
+	if step.Type != impostermodel.StepTypeScript || step.Language != "javascript" {
+		t.Errorf("expected javascript step, got %+v", step)
+	}
+	for _, expected := range []string{
+		`"file": "jobs/GET-1.json"`,
+		`"file": "jobs/GET-3-1.json"`,
+		`store.load("GET http://example.com/jobs/1")`,
+	} {
+		if !strings.Contains(step.Code, expected) {
+			t.Errorf("expected script to contain %s, got:\n%s", expected, step.Code)
+		}
+	}
+	if strings.Count(step.Code, `"file": "jobs/GET-1.json"`) != 2 {
+		t.Errorf("expected repeated response to be replayed twice, got:\n%s", step.Code)
+	}
+	done, err := os.ReadFile(path.Join(outputDir, "jobs", "GET-3-1.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(done) != `{"status":"done"}` {
+		t.Errorf("unexpected final response %s", done)
+	}
+
+	jobTwo := config.Resources[1]
+	if jobTwo.Steps != nil || jobTwo.Response == nil || jobTwo.Response.File != "jobs/GET-3-1.json" {
+		t.Errorf("expected identical responses to be recorded once, got %+v", jobTwo)
+	}
+}
@@ -0,0 +1,436 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/imposter-project/imposter-cli/internal/impostermodel"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RedactedPlaceholder replaces redacted values in recordings.
+const RedactedPlaceholder = "REDACTED"
+
+// DefaultRedactedHeaders are redacted unless the defaults are disabled.
+var DefaultRedactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// RedactionRules replace secrets in exchanges with RedactedPlaceholder
+// before they are written to disk. A nil *RedactionRules redacts nothing.
+type RedactionRules struct {
+	headers     []string
+	jsonPaths   [][]jsonPathSegment
+	patterns    []*regexp.Regexp
+	queryParams []string
+}
+
+// NewRedactionRules builds rules that redact:
+//   - the values of the named request and response headers
+//   - the values at the given JSON paths in JSON bodies, such as
+//     '$.user.password', '$.items[*].token' or '$..secret'
+//   - the matches of the regular expressions in text bodies and query
+//     parameter values; if a pattern has a capture group, only the text
+//     matched by the first group is redacted
+//   - the values of the named query parameters
+func NewRedactionRules(headers []string, jsonPaths []string, patterns []string, queryParams []string) (*RedactionRules, error) {
+	rules := &RedactionRules{queryParams: queryParams}
+	for _, header := range headers {
+		rules.headers = append(rules.headers, http.CanonicalHeaderKey(header))
+	}
+	for _, jsonPath := range jsonPaths {
+		segments, err := parseJsonPath(jsonPath)
+		if err != nil {
+			return nil, err
+		}
+		rules.jsonPaths = append(rules.jsonPaths, segments)
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %v", pattern, err)
+		}
+		rules.patterns = append(rules.patterns, re)
+	}
+	return rules, nil
+}
+
+// Redact returns a copy of the exchange with the rules applied to the
+// request URL, headers and bodies, and any WebSocket messages. The
+// original exchange, which may still be in use, is not modified.
+func (r *RedactionRules) Redact(exchange HttpExchange) HttpExchange {
+	if r == nil {
+		return exchange
+	}
+	req := exchange.Request.Clone(exchange.Request.Context())
+	req.Header = r.redactHeaders(req.Header)
+	req.URL.RawQuery = r.redactQuery(req.URL.Query(), req.URL.RawQuery)
+	exchange.Request = req
+
+	if exchange.RequestBody != nil {
+		reqBody := r.RedactBody(*exchange.RequestBody, req.Header.Get("Content-Type"))
+		exchange.RequestBody = &reqBody
+	}
+	if exchange.ResponseHeaders != nil {
+		respHeaders := r.redactHeaders(*exchange.ResponseHeaders)
+		exchange.ResponseHeaders = &respHeaders
+		if exchange.ResponseBody != nil {
+			respBody := r.RedactBody(*exchange.ResponseBody, respHeaders.Get("Content-Type"))
+			exchange.ResponseBody = &respBody
+		}
+	}
+	if exchange.WebSocketMessages != nil {
+		messages := make([]WebSocketMessage, len(*exchange.WebSocketMessages))
+		for i, message := range *exchange.WebSocketMessages {
+			if !message.Binary {
+				message.Data = r.RedactBody(message.Data, "text/plain")
+			}
+			messages[i] = message
+		}
+		exchange.WebSocketMessages = &messages
+	}
+	return exchange
+}
+
+// omitRedactedMatchers removes the request matchers of the resource whose
+// values were changed by redaction. A placeholder would never match a real
+// request on replay, so the resource matches any value instead.
+func omitRedactedMatchers(resource *impostermodel.Resource, original HttpExchange, redacted HttpExchange) {
+	if resource.RequestHeaders != nil {
+		for name := range *resource.RequestHeaders {
+			if original.Request.Header.Get(name) != redacted.Request.Header.Get(name) {
+				delete(*resource.RequestHeaders, name)
+			}
+		}
+		if len(*resource.RequestHeaders) == 0 {
+			resource.RequestHeaders = nil
+		}
+	}
+	if resource.QueryParams != nil {
+		originalQuery, redactedQuery := original.Request.URL.Query(), redacted.Request.URL.Query()
+		for name := range *resource.QueryParams {
+			if originalQuery.Get(name) != redactedQuery.Get(name) {
+				delete(*resource.QueryParams, name)
+			}
+		}
+		if len(*resource.QueryParams) == 0 {
+			resource.QueryParams = nil
+		}
+	}
+	if resource.RequestBody != nil && original.RequestBody != nil && redacted.RequestBody != nil &&
+		!bytes.Equal(*original.RequestBody, *redacted.RequestBody) {
+		resource.RequestBody = nil
+	}
+}
+
+func (r *RedactionRules) redactHeaders(headers http.Header) http.Header {
+	redacted := headers.Clone()
+	for _, name := range r.headers {
+		if values := redacted.Values(name); len(values) > 0 {
+			for i := range values {
+				values[i] = RedactedPlaceholder
+			}
+		}
+	}
+	return redacted
+}
+
+// redactQuery returns the redacted query string, or the original if
+// nothing was redacted, so that parameter order is preserved.
+func (r *RedactionRules) redactQuery(query url.Values, rawQuery string) string {
+	changed := false
+	for name, values := range query {
+		for i, value := range values {
+			redacted := value
+			for _, param := range r.queryParams {
+				if param == name {
+					redacted = RedactedPlaceholder
+				}
+			}
+			redacted = r.redactPatterns(redacted)
+			if redacted != value {
+				values[i] = redacted
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return rawQuery
+	}
+	return query.Encode()
+}
+
+// RedactBody applies the JSON path rules to JSON bodies, and the
+// pattern rules to text bodies. Other bodies are returned unchanged.
+func (r *RedactionRules) RedactBody(body []byte, contentType string) []byte {
+	if r == nil || len(body) == 0 {
+		return body
+	}
+	if len(r.jsonPaths) > 0 && isJsonContentType(contentType) {
+		body = r.redactJson(body)
+	}
+	if len(r.patterns) > 0 && (contentType == "" || isTextContentType(contentType)) {
+		body = []byte(r.redactPatterns(string(body)))
+	}
+	return body
+}
+
+func (r *RedactionRules) redactPatterns(text string) string {
+	for _, re := range r.patterns {
+		text = redactPattern(re, text)
+	}
+	return text
+}
+
+// redactPattern replaces each match of the pattern, or the first
+// capture group of each match if the pattern has one.
+func redactPattern(re *regexp.Regexp, text string) string {
+	group := 0
+	if re.NumSubexp() > 0 {
+		group = 1
+	}
+	var result strings.Builder
+	last := 0
+	for _, match := range re.FindAllStringSubmatchIndex(text, -1) {
+		start, end := match[2*group], match[2*group+1]
+		if start < 0 {
+			continue
+		}
+		result.WriteString(text[last:start])
+		result.WriteString(RedactedPlaceholder)
+		last = end
+	}
+	result.WriteString(text[last:])
+	return result.String()
+}
+
+// redactJson replaces the values at the JSON paths. The body is only
+// re-encoded if a value was replaced; object key order is preserved.
+func (r *RedactionRules) redactJson(body []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	value, err := decodeOrderedJson(decoder)
+	if err != nil {
+		logger.Debugf("body is not valid JSON - skipping JSON path redaction: %v", err)
+		return body
+	}
+	changed := false
+	for _, segments := range r.jsonPaths {
+		var matched bool
+		value, matched = redactJsonPath(value, segments)
+		changed = changed || matched
+	}
+	if !changed {
+		return body
+	}
+	var redacted []byte
+	if bytes.Contains(body, []byte("\n")) {
+		redacted, err = json.MarshalIndent(value, "", "  ")
+	} else {
+		redacted, err = json.Marshal(value)
+	}
+	if err != nil {
+		logger.Warnf("failed to encode redacted JSON body: %v", err)
+		return body
+	}
+	return redacted
+}
+
+func isJsonContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+type jsonPathSegment struct {
+	name      string
+	index     int
+	wildcard  bool
+	recursive bool
+}
+
+// parseJsonPath parses a subset of JSONPath: dot-separated member names,
+// '[n]' array indices, '*' or '[*]' wildcards and '..' recursive descent.
+// The leading '$' is optional.
+func parseJsonPath(path string) ([]jsonPathSegment, error) {
+	rest := strings.TrimPrefix(strings.TrimSpace(path), "$")
+	var segments []jsonPathSegment
+	for len(rest) > 0 {
+		segment := jsonPathSegment{index: -1}
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			segment.recursive = true
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+		}
+
+		if strings.HasPrefix(rest, "[") {
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSON path %q: unclosed '['", path)
+			}
+			selector := strings.Trim(rest[1:end], `'"`)
+			rest = rest[end+1:]
+			if selector == "*" {
+				segment.wildcard = true
+			} else if index, err := strconv.Atoi(selector); err == nil {
+				segment.index = index
+			} else {
+				segment.name = selector
+			}
+		} else {
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			segment.name = rest[:end]
+			rest = rest[end:]
+			if segment.name == "*" {
+				segment.wildcard = true
+				segment.name = ""
+			}
+		}
+		if segment.name == "" && segment.index < 0 && !segment.wildcard {
+			return nil, fmt.Errorf("invalid JSON path %q: empty segment", path)
+		}
+		segments = append(segments, segment)
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("invalid JSON path %q: no segments", path)
+	}
+	return segments, nil
+}
+
+// redactJsonPath replaces the values matching the path segments,
+// returning the updated value and whether anything was replaced.
+func redactJsonPath(value any, segments []jsonPathSegment) (any, bool) {
+	if len(segments) == 0 {
+		return RedactedPlaceholder, true
+	}
+	segment := segments[0]
+	matched := false
+
+	switch v := value.(type) {
+	case jsonObject:
+		for i := range v {
+			if segment.wildcard || segment.name == v[i].key {
+				var m bool
+				v[i].value, m = redactJsonPath(v[i].value, segments[1:])
+				matched = matched || m
+			} else if segment.recursive {
+				var m bool
+				v[i].value, m = redactJsonPath(v[i].value, segments)
+				matched = matched || m
+			}
+		}
+	case []any:
+		for i := range v {
+			if segment.wildcard || segment.index == i {
+				var m bool
+				v[i], m = redactJsonPath(v[i], segments[1:])
+				matched = matched || m
+			} else if segment.recursive {
+				var m bool
+				v[i], m = redactJsonPath(v[i], segments)
+				matched = matched || m
+			}
+		}
+	}
+	return value, matched
+}
+
+// jsonObject is a JSON object that retains the order of its members.
+type jsonObject []jsonMember
+
+type jsonMember struct {
+	key   string
+	value any
+}
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, member := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(member.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(member.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// decodeOrderedJson decodes the next JSON value, using jsonObject for
+// objects, so that member order is preserved.
+func decodeOrderedJson(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
+	}
+	switch delim {
+	case '{':
+		object := jsonObject{}
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrderedJson(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object = append(object, jsonMember{key: keyToken.(string), value: value})
+		}
+		_, err = decoder.Token()
+		return object, err
+	case '[':
+		array := []any{}
+		for decoder.More() {
+			value, err := decodeOrderedJson(decoder)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err = decoder.Token()
+		return array, err
+	default:
+		return nil, fmt.Errorf("unexpected JSON delimiter: %v", delim)
+	}
+}
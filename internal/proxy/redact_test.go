@@ -0,0 +1,178 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseJsonPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    []jsonPathSegment
+		wantErr bool
+	}{
+		{path: "$.user.password", want: []jsonPathSegment{{name: "user", index: -1}, {name: "password", index: -1}}},
+		{path: "user.password", want: []jsonPathSegment{{name: "user", index: -1}, {name: "password", index: -1}}},
+		{path: "$.items[*].token", want: []jsonPathSegment{{name: "items", index: -1}, {index: -1, wildcard: true}, {name: "token", index: -1}}},
+		{path: "$.items[0]", want: []jsonPathSegment{{name: "items", index: -1}, {index: 0}}},
+		{path: "$['api-key']", want: []jsonPathSegment{{name: "api-key", index: -1}}},
+		{path: "$..secret", want: []jsonPathSegment{{name: "secret", index: -1, recursive: true}}},
+		{path: "$.*", want: []jsonPathSegment{{index: -1, wildcard: true}}},
+		{path: "$", wantErr: true},
+		{path: "$.items[0", wantErr: true},
+		{path: "$.a..", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := parseJsonPath(tt.path)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRedactionRules_RedactBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		jsonPaths   []string
+		patterns    []string
+		body        string
+		contentType string
+		want        string
+	}{
+		{
+			name:        "json path preserves key order",
+			jsonPaths:   []string{"$.user.password"},
+			body:        `{"user":{"name":"alice","password":"s3cret"},"id":1}`,
+			contentType: "application/json",
+			want:        `{"user":{"name":"alice","password":"REDACTED"},"id":1}`,
+		},
+		{
+			name:        "json path wildcard in array",
+			jsonPaths:   []string{"$.items[*].token"},
+			body:        `{"items":[{"token":"a"},{"token":"b","n":2.5}]}`,
+			contentType: "application/vnd.api+json",
+			want:        `{"items":[{"token":"REDACTED"},{"token":"REDACTED","n":2.5}]}`,
+		},
+		{
+			name:        "recursive descent",
+			jsonPaths:   []string{"$..secret"},
+			body:        `[{"a":{"secret":{"nested":true}}},{"secret":"x"}]`,
+			contentType: "application/json",
+			want:        `[{"a":{"secret":"REDACTED"}},{"secret":"REDACTED"}]`,
+		},
+		{
+			name:        "unmatched json unchanged",
+			jsonPaths:   []string{"$.missing"},
+			body:        `{ "b": 1,   "a": 2 }`,
+			contentType: "application/json",
+			want:        `{ "b": 1,   "a": 2 }`,
+		},
+		{
+			name:        "indented json stays indented",
+			jsonPaths:   []string{"$.token"},
+			body:        "{\n  \"token\": \"abc\"\n}",
+			contentType: "application/json",
+			want:        "{\n  \"token\": \"REDACTED\"\n}",
+		},
+		{
+			name:        "invalid json unchanged",
+			jsonPaths:   []string{"$.token"},
+			body:        `{"token":`,
+			contentType: "application/json",
+			want:        `{"token":`,
+		},
+		{
+			name:        "pattern replaces whole match",
+			patterns:    []string{`\d{3}-\d{2}-\d{4}`},
+			body:        "ssn: 123-45-6789, other: 987-65-4321",
+			contentType: "text/plain",
+			want:        "ssn: REDACTED, other: REDACTED",
+		},
+		{
+			name:        "pattern replaces first group",
+			patterns:    []string{`<token>([^<]*)</token>`},
+			body:        "<auth><token>abc</token></auth>",
+			contentType: "application/xml",
+			want:        "<auth><token>REDACTED</token></auth>",
+		},
+		{
+			name:        "pattern skips binary",
+			patterns:    []string{`abc`},
+			body:        "abc",
+			contentType: "application/octet-stream",
+			want:        "abc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := NewRedactionRules(nil, tt.jsonPaths, tt.patterns, nil)
+			require.NoError(t, err)
+			got := rules.RedactBody([]byte(tt.body), tt.contentType)
+			require.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestRedactionRules_Redact(t *testing.T) {
+	rules, err := NewRedactionRules(DefaultRedactedHeaders, []string{"$.token"}, []string{`key-[a-z0-9]+`}, []string{"access_token"})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/login?access_token=abc&page=2&q=key-123", nil)
+	req.Header.Set("Authorization", "Bearer abc")
+	req.Header.Set("Content-Type", "application/json")
+	reqBody := []byte(`{"user":"alice","token":"abc"}`)
+	respHeaders := http.Header{
+		"Set-Cookie":   {"session=1", "other=2"},
+		"Content-Type": {"application/json"},
+	}
+	respBody := []byte(`{"token":"xyz"}`)
+	messages := []WebSocketMessage{{Direction: WebSocketSend, Data: []byte("key-abc")}}
+	exchange := HttpExchange{
+		Request:           req,
+		RequestBody:       &reqBody,
+		ResponseBody:      &respBody,
+		ResponseHeaders:   &respHeaders,
+		WebSocketMessages: &messages,
+	}
+
+	redacted := rules.Redact(exchange)
+
+	require.Equal(t, "REDACTED", redacted.Request.Header.Get("Authorization"))
+	require.Equal(t, "REDACTED", redacted.Request.URL.Query().Get("access_token"))
+	require.Equal(t, "2", redacted.Request.URL.Query().Get("page"))
+	require.Equal(t, "REDACTED", redacted.Request.URL.Query().Get("q"))
+	require.Equal(t, `{"user":"alice","token":"REDACTED"}`, string(*redacted.RequestBody))
+	require.Equal(t, []string{"REDACTED", "REDACTED"}, redacted.ResponseHeaders.Values("Set-Cookie"))
+	require.Equal(t, `{"token":"REDACTED"}`, string(*redacted.ResponseBody))
+	require.Equal(t, "REDACTED", string((*redacted.WebSocketMessages)[0].Data))
+
+	// the original exchange may still be in use, e.g. by the HAR recorder
+	require.Equal(t, "Bearer abc", req.Header.Get("Authorization"))
+	require.Equal(t, "abc", req.URL.Query().Get("access_token"))
+	require.Equal(t, "session=1", respHeaders.Get("Set-Cookie"))
+	require.Equal(t, `{"token":"xyz"}`, string(respBody))
+	require.Equal(t, "key-abc", string(messages[0].Data))
+}
+
+func TestRedactionRules_nil(t *testing.T) {
+	var rules *RedactionRules
+	req := httptest.NewRequest("GET", "/", nil)
+	exchange := HttpExchange{Request: req}
+	require.Equal(t, exchange, rules.Redact(exchange))
+	require.Equal(t, "body", string(rules.RedactBody([]byte("body"), "text/plain")))
+}
+
+func TestNewRedactionRules_invalid(t *testing.T) {
+	_, err := NewRedactionRules(nil, nil, []string{"("}, nil)
+	require.Error(t, err)
+	_, err = NewRedactionRules(nil, []string{"$"}, nil, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,104 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Route sends requests matching a Host header and/or path prefix
+// to an upstream.
+type Route struct {
+	Host       string
+	PathPrefix string
+	Upstream   string
+}
+
+// ParseRoute parses a route in the form MATCH=URL, where MATCH is
+// a path prefix (e.g. '/api'), a host (e.g. 'api.example.com'), or
+// a host and path prefix (e.g. 'api.example.com/v1'). A host may
+// include a port (e.g. 'api.local:8080'), in which case the port of
+// the request must also match.
+func ParseRoute(spec string) (Route, error) {
+	match, upstream, found := strings.Cut(spec, "=")
+	if !found || match == "" || upstream == "" {
+		return Route{}, fmt.Errorf("invalid route: %s - expected MATCH=URL, e.g. /api=https://example.com", spec)
+	}
+	upstreamUrl, err := url.Parse(upstream)
+	if err != nil || upstreamUrl.Scheme == "" || upstreamUrl.Host == "" {
+		return Route{}, fmt.Errorf("invalid upstream URL in route: %s", spec)
+	}
+
+	route := Route{Upstream: upstream}
+	if strings.HasPrefix(match, "/") {
+		route.PathPrefix = match
+	} else if host, prefix, hasPath := strings.Cut(match, "/"); hasPath {
+		route.Host = host
+		route.PathPrefix = "/" + prefix
+	} else {
+		route.Host = match
+	}
+	return route, nil
+}
+
+// MatchRoute returns the upstream for the most specific route matching
+// the request. Routes with longer path prefixes are more specific, and
+// for the same prefix, routes that match the host are more specific.
+func MatchRoute(routes []Route, req *http.Request) (string, bool) {
+	var best *Route
+	for i := range routes {
+		route := &routes[i]
+		if !route.matches(req) {
+			continue
+		}
+		if best == nil ||
+			len(route.PathPrefix) > len(best.PathPrefix) ||
+			(len(route.PathPrefix) == len(best.PathPrefix) && route.Host != "" && best.Host == "") {
+			best = route
+		}
+	}
+	if best == nil {
+		return "", false
+	}
+	return best.Upstream, true
+}
+
+func (r *Route) matches(req *http.Request) bool {
+	if r.Host != "" {
+		// the port of the request is only compared if the route has one
+		host := req.Host
+		if _, _, err := net.SplitHostPort(r.Host); err != nil {
+			if h, _, err := net.SplitHostPort(host); err == nil {
+				host = h
+			}
+		}
+		if !strings.EqualFold(host, r.Host) {
+			return false
+		}
+	}
+	if r.PathPrefix != "" {
+		prefix := strings.TrimSuffix(r.PathPrefix, "/")
+		if req.URL.Path != prefix && !strings.HasPrefix(req.URL.Path, prefix+"/") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+package proxy
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRoute(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    Route
+		wantErr bool
+	}{
+		{spec: "/api=https://api.example.com", want: Route{PathPrefix: "/api", Upstream: "https://api.example.com"}},
+		{spec: "users.local=http://localhost:9000", want: Route{Host: "users.local", Upstream: "http://localhost:9000"}},
+		{spec: "users.local/v1=http://localhost:9000", want: Route{Host: "users.local", PathPrefix: "/v1", Upstream: "http://localhost:9000"}},
+		{spec: "api.local:8080/v1=http://localhost:9000", want: Route{Host: "api.local:8080", PathPrefix: "/v1", Upstream: "http://localhost:9000"}},
+		{spec: "/api", wantErr: true},
+		{spec: "/api=not-a-url", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseRoute(tt.spec)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMatchRoute(t *testing.T) {
+	routes := []Route{
+		{PathPrefix: "/api", Upstream: "https://api.example.com"},
+		{PathPrefix: "/api/orders", Upstream: "https://orders.example.com"},
+		{Host: "users.local", Upstream: "https://users.example.com"},
+		{Host: "users.local", PathPrefix: "/api", Upstream: "https://users-api.example.com"},
+		{Host: "api.local:8080", Upstream: "https://api-8080.example.com"},
+	}
+	tests := []struct {
+		name   string
+		host   string
+		path   string
+		want   string
+		wantOk bool
+	}{
+		{name: "path prefix", host: "localhost:8080", path: "/api/products", want: "https://api.example.com", wantOk: true},
+		{name: "exact path prefix", host: "localhost:8080", path: "/api", want: "https://api.example.com", wantOk: true},
+		{name: "longest path prefix", host: "localhost:8080", path: "/api/orders/1", want: "https://orders.example.com", wantOk: true},
+		{name: "prefix must end at segment", host: "localhost:8080", path: "/apis", wantOk: false},
+		{name: "host", host: "users.local:8080", path: "/people", want: "https://users.example.com", wantOk: true},
+		{name: "host and path beats path", host: "USERS.local", path: "/api/x", want: "https://users-api.example.com", wantOk: true},
+		{name: "no match", host: "localhost", path: "/other", wantOk: false},
+		{name: "host with port", host: "api.local:8080", path: "/other", want: "https://api-8080.example.com", wantOk: true},
+		{name: "host with different port", host: "api.local:9090", path: "/other", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Host = tt.host
+			got, ok := MatchRoute(routes, req)
+			require.Equal(t, tt.wantOk, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
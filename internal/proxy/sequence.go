@@ -0,0 +1,114 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	impostermodel2 "github.com/imposter-project/imposter-cli/internal/impostermodel"
+)
+
+// sequenceStoreName is the store in which the position of each
+// sequence is held when it is replayed.
+const sequenceStoreName = "sequences"
+
+// sequence holds the responses returned by the upstream for repeated
+// requests with the same method and URL, in the order they were received.
+type sequence struct {
+	key           string
+	resourceIndex int
+	responses     []sequenceResponse
+}
+
+type sequenceResponse struct {
+	StatusCode int               `json:"statusCode"`
+	File       string            `json:"file,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+}
+
+func newSequenceResponse(response *impostermodel2.ResponseConfig) sequenceResponse {
+	step := sequenceResponse{
+		StatusCode: response.StatusCode,
+		File:       response.File,
+	}
+	if response.Headers != nil {
+		step.Headers = *response.Headers
+	}
+	return step
+}
+
+// isUniform returns true if every response in the sequence is the same,
+// in which case it can be replayed as a single response.
+func (s *sequence) isUniform() bool {
+	for _, response := range s.responses[1:] {
+		if !reflect.DeepEqual(response, s.responses[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// buildScript generates a script that replays the responses in order,
+// holding the position of the sequence in a store, and repeating the
+// last response once the sequence is exhausted.
+func (s *sequence) buildScript() (string, error) {
+	responses, err := json.MarshalIndent(s.responses, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	key, err := json.Marshal(s.key)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`// replays the responses recorded for %[1]s in order
+var responses = %[2]s;
+var store = stores.open('%[3]s');
+var index = store.load(%[4]s) || 0;
+store.save(%[4]s, index + 1);
+var response = responses[Math.min(index, responses.length - 1)];
+var builder = respond().withStatusCode(response.statusCode);
+if (response.file) {
+  builder = builder.withFile(response.file);
+} else {
+  builder = builder.withEmpty();
+}
+for (var name in response.headers) {
+  builder = builder.withHeader(name, response.headers[name]);
+}
+`, s.key, responses, sequenceStoreName, key), nil
+}
+
+// applyTo replaces the response of the resource with a script step that
+// replays the sequence, unless every response in the sequence is the same.
+func (s *sequence) applyTo(resource *impostermodel2.Resource) error {
+	if s.isUniform() {
+		return nil
+	}
+	script, err := s.buildScript()
+	if err != nil {
+		return fmt.Errorf("failed to generate script for sequence %s: %v", s.key, err)
+	}
+	resource.Response = nil
+	resource.Steps = &[]impostermodel2.StepConfig{{
+		Type:     impostermodel2.StepTypeScript,
+		Language: string(impostermodel2.ScriptEngineJavaScript),
+		Code:     script,
+	}}
+	return nil
+}
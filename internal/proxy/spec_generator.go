@@ -0,0 +1,405 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/imposter-project/imposter-cli/internal/openapi"
+	"github.com/imposter-project/imposter-cli/internal/stringutil"
+	"sigs.k8s.io/yaml"
+)
+
+// maxInferredExamples is the maximum number of distinct bodies
+// recorded as examples for each request or response media type.
+const maxInferredExamples = 10
+
+// skipSpecHeaders are request headers that are not described as
+// parameters, as they are set by clients for every request, or, in
+// the case of Accept, Content-Type and Authorization, are ignored
+// by OpenAPI when declared as header parameters.
+var skipSpecHeaders = []string{
+	"Accept",
+	"Accept-Language",
+	"Authorization",
+	"Content-Type",
+	"Cookie",
+	"Host",
+	"If-Modified-Since",
+	"If-None-Match",
+	"Origin",
+	"Pragma",
+	"Referer",
+	"User-Agent",
+}
+
+type specGenerator struct {
+	upstream   string
+	specFile   string
+	redaction  *RedactionRules
+	operations map[string]*inferredOperation
+
+	// operationKeys holds the keys of the operations, in the
+	// order in which they were first recorded.
+	operationKeys []string
+}
+
+// inferredOperation aggregates the exchanges with a method and templated path.
+type inferredOperation struct {
+	method     string
+	path       string
+	count      int
+	pathParams []*inferredParam
+	query      map[string]*inferredParam
+	headers    map[string]*inferredParam
+
+	// bodyCount is the number of exchanges with a request body.
+	bodyCount   int
+	requestBody map[string]*inferredContent
+	responses   map[int]map[string]*inferredContent
+}
+
+type inferredParam struct {
+	name    string
+	count   int
+	schema  *openapi.Schema
+	example string
+}
+
+// inferredContent aggregates the bodies with a media type.
+type inferredContent struct {
+	schema   *openapi.Schema
+	examples map[string]openapi.Example
+
+	// exampleHashes holds the hashes of the bodies recorded as examples.
+	exampleHashes map[string]bool
+}
+
+// StartSpecGenerator starts a generator for the given upstream, which
+// infers an OpenAPI 3 spec from the exchanges received on the returned
+// channel. The spec is written to the given dir, and rewritten after
+// each exchange, so it always describes all the exchanges so far.
+func StartSpecGenerator(upstream string, dir string, redaction *RedactionRules) (chan HttpExchange, error) {
+	g, err := newSpecGenerator(upstream, dir, redaction)
+	if err != nil {
+		return nil, err
+	}
+
+	specC := make(chan HttpExchange)
+	go func() {
+		for {
+			exchange := <-specC
+			g.record(exchange)
+		}
+	}()
+	return specC, nil
+}
+
+func newSpecGenerator(upstream string, dir string, redaction *RedactionRules) (*specGenerator, error) {
+	upstreamHost, err := formatUpstreamName(upstream)
+	if err != nil {
+		return nil, err
+	}
+	specFile := path.Join(dir, upstreamHost+"-openapi.yaml")
+	if _, err := os.Stat(specFile); err == nil {
+		return nil, fmt.Errorf("spec file %s already exists", specFile)
+	}
+	return &specGenerator{
+		upstream:   upstream,
+		specFile:   specFile,
+		redaction:  redaction,
+		operations: make(map[string]*inferredOperation),
+	}, nil
+}
+
+// record adds the exchange to the operation for its method and path,
+// then rewrites the spec. Identifier-like path segments, such as numeric
+// IDs, are described as path parameters, so exchanges with different
+// identifiers are aggregated into the same operation.
+func (g *specGenerator) record(exchange HttpExchange) {
+	exchange = g.redaction.Redact(exchange)
+	req := exchange.Request
+	if exchange.WebSocketMessages != nil {
+		logger.Debugf("WebSocket connections are not described in inferred specs - skipping %v", req.URL)
+		return
+	}
+
+	templatedPath, pathParams := templatePath(req.URL.Path)
+	key := req.Method + " " + templatedPath
+	op, ok := g.operations[key]
+	if !ok {
+		op = &inferredOperation{
+			method:      strings.ToLower(req.Method),
+			path:        templatedPath,
+			query:       make(map[string]*inferredParam),
+			headers:     make(map[string]*inferredParam),
+			requestBody: make(map[string]*inferredContent),
+			responses:   make(map[int]map[string]*inferredContent),
+		}
+		g.operations[key] = op
+		g.operationKeys = append(g.operationKeys, key)
+
+		// parameters are named in the order of the segments
+		for _, segment := range strings.Split(templatedPath, "/") {
+			if strings.HasPrefix(segment, "{") {
+				op.pathParams = append(op.pathParams, &inferredParam{name: strings.Trim(segment, "{}")})
+			}
+		}
+	}
+	op.count++
+
+	for _, param := range op.pathParams {
+		param.add(pathParams[param.name])
+	}
+	for name, values := range req.URL.Query() {
+		if len(values) > 0 {
+			addParam(op.query, name, values[0])
+		}
+	}
+	for name, values := range req.Header {
+		if stringutil.Contains(skipProxyHeaders, name) || stringutil.Contains(skipSpecHeaders, name) || len(values) == 0 {
+			continue
+		}
+		addParam(op.headers, name, values[0])
+	}
+
+	if exchange.RequestBody != nil && len(*exchange.RequestBody) > 0 && !exchange.RequestBodyTruncated {
+		op.bodyCount++
+		addContent(op.requestBody, exchange, req.Header.Get("Content-Type"), *exchange.RequestBody)
+	}
+
+	responseContent, ok := op.responses[exchange.StatusCode]
+	if !ok {
+		responseContent = make(map[string]*inferredContent)
+		op.responses[exchange.StatusCode] = responseContent
+	}
+	if exchange.ResponseBody != nil && len(*exchange.ResponseBody) > 0 && !exchange.ResponseBodyTruncated {
+		addContent(responseContent, exchange, exchange.ResponseHeaders.Get("Content-Type"), *exchange.ResponseBody)
+	}
+
+	if err := g.writeSpec(); err != nil {
+		logger.Warn(err)
+		return
+	}
+	logger.Debugf("wrote spec file %s for %s %v", g.specFile, req.Method, req.URL)
+}
+
+func addParam(params map[string]*inferredParam, name string, value string) {
+	param, ok := params[name]
+	if !ok {
+		param = &inferredParam{name: name}
+		params[name] = param
+	}
+	param.add(value)
+}
+
+// add merges the schema of the value with those already seen. Values
+// are described as strings unless all values are numbers or booleans.
+func (p *inferredParam) add(value string) {
+	if p.count == 0 {
+		p.example = value
+	}
+	p.count++
+
+	var schema *openapi.Schema
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		schema = &openapi.Schema{Type: openapi.SchemaType{"integer"}}
+	} else if _, err := strconv.ParseFloat(value, 64); err == nil {
+		schema = &openapi.Schema{Type: openapi.SchemaType{"number"}}
+	} else if value == "true" || value == "false" {
+		schema = &openapi.Schema{Type: openapi.SchemaType{"boolean"}}
+	} else {
+		schema = openapi.InferSchema(value)
+	}
+	p.schema = openapi.MergeSchemas(p.schema, schema)
+	if len(p.schema.AnyOf) > 0 {
+		p.schema = &openapi.Schema{Type: openapi.SchemaType{"string"}}
+	}
+}
+
+// addContent merges the schema of the body into the content for its
+// media type, and adds the body as an example if it has not been seen.
+// JSON bodies are described by their structure, text bodies as strings,
+// and other bodies as binary strings, without examples.
+func addContent(content map[string]*inferredContent, exchange HttpExchange, contentType string, body []byte) {
+	mediaType := "application/octet-stream"
+	if contentType != "" {
+		if parsed, _, err := mime.ParseMediaType(contentType); err == nil {
+			mediaType = parsed
+		}
+	}
+
+	var schema *openapi.Schema
+	var example any
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		if err := decoder.Decode(&example); err != nil {
+			logger.Debugf("failed to parse JSON body for %s %v - describing as string: %v", exchange.Request.Method, exchange.Request.URL, err)
+			schema = &openapi.Schema{Type: openapi.SchemaType{"string"}}
+			example = string(body)
+		} else {
+			schema = openapi.InferSchema(example)
+		}
+	} else if contentType != "" && isTextContentType(contentType) {
+		schema = &openapi.Schema{Type: openapi.SchemaType{"string"}}
+		example = string(body)
+	} else {
+		schema = &openapi.Schema{Type: openapi.SchemaType{"string"}, Format: "binary"}
+	}
+
+	existing, ok := content[mediaType]
+	if !ok {
+		existing = &inferredContent{examples: make(map[string]openapi.Example), exampleHashes: make(map[string]bool)}
+		content[mediaType] = existing
+	}
+	existing.schema = openapi.MergeSchemas(existing.schema, schema)
+
+	hash := stringutil.Sha1hash(body)
+	if example == nil || existing.exampleHashes[hash] || len(existing.examples) >= maxInferredExamples {
+		return
+	}
+	existing.exampleHashes[hash] = true
+	name := generateExampleName(exchange)
+	for i := 2; ; i++ {
+		if _, taken := existing.examples[name]; !taken {
+			break
+		}
+		name = fmt.Sprintf("%s-%d", generateExampleName(exchange), i)
+	}
+	existing.examples[name] = openapi.Example{Value: example}
+}
+
+// writeSpec writes the spec describing the operations recorded so far.
+func (g *specGenerator) writeSpec() error {
+	upstreamHost, _ := formatUpstreamName(g.upstream)
+	spec := openapi.Spec{
+		Version: "3.0.3",
+		Info:    &openapi.Info{Title: upstreamHost, Version: "1.0.0"},
+		Servers: []openapi.Server{{Url: g.upstream}},
+		Paths:   make(openapi.PathItems),
+	}
+	for _, key := range g.operationKeys {
+		op := g.operations[key]
+		if spec.Paths[op.path] == nil {
+			spec.Paths[op.path] = make(map[string]*openapi.Operation)
+		}
+		spec.Paths[op.path][op.method] = op.build()
+	}
+
+	content, err := yaml.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal spec %s: %v", g.specFile, err)
+	}
+
+	// write then rename, so the spec file is never seen partially written
+	tmpFile := g.specFile + ".tmp"
+	if err := os.WriteFile(tmpFile, content, 0644); err != nil {
+		return fmt.Errorf("failed to write spec file %s: %v", g.specFile, err)
+	}
+	if err := os.Rename(tmpFile, g.specFile); err != nil {
+		return fmt.Errorf("failed to write spec file %s: %v", g.specFile, err)
+	}
+	return nil
+}
+
+// build returns the operation for the spec. Query and header parameters
+// are required if they were present in every exchange.
+func (op *inferredOperation) build() *openapi.Operation {
+	operation := &openapi.Operation{Responses: make(map[string]*openapi.Response)}
+	for _, param := range op.pathParams {
+		operation.Parameters = append(operation.Parameters, param.build("path", true))
+	}
+	for _, name := range sortedParamNames(op.query) {
+		param := op.query[name]
+		operation.Parameters = append(operation.Parameters, param.build("query", param.count == op.count))
+	}
+	for _, name := range sortedParamNames(op.headers) {
+		param := op.headers[name]
+		operation.Parameters = append(operation.Parameters, param.build("header", param.count == op.count))
+	}
+
+	if len(op.requestBody) > 0 {
+		operation.RequestBody = &openapi.RequestBody{
+			Required: op.bodyCount == op.count,
+			Content:  buildContent(op.requestBody),
+		}
+	}
+	for status, content := range op.responses {
+		description := http.StatusText(status)
+		if description == "" {
+			description = fmt.Sprintf("Status %d", status)
+		}
+		response := &openapi.Response{Description: description}
+		if len(content) > 0 {
+			response.Content = buildContent(content)
+		}
+		operation.Responses[strconv.Itoa(status)] = response
+	}
+	return operation
+}
+
+// build returns the parameter, with the first value seen as its
+// example, converted to the type of its schema.
+func (p *inferredParam) build(in string, required bool) openapi.Parameter {
+	var example any = p.example
+	switch p.schema.Type.Primary() {
+	case "integer":
+		example, _ = strconv.ParseInt(p.example, 10, 64)
+	case "number":
+		example, _ = strconv.ParseFloat(p.example, 64)
+	case "boolean":
+		example = p.example == "true"
+	}
+	return openapi.Parameter{
+		Name:     p.name,
+		In:       in,
+		Required: required,
+		Schema:   p.schema,
+		Example:  example,
+	}
+}
+
+func buildContent(content map[string]*inferredContent) map[string]openapi.MediaType {
+	mediaTypes := make(map[string]openapi.MediaType)
+	for key, c := range content {
+		mediaType := openapi.MediaType{Schema: c.schema}
+		if len(c.examples) > 0 {
+			mediaType.Examples = c.examples
+		}
+		mediaTypes[key] = mediaType
+	}
+	return mediaTypes
+}
+
+func sortedParamNames(params map[string]*inferredParam) []string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,87 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imposter-project/imposter-cli/internal/openapi"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpecGenerator_record(t *testing.T) {
+	newExchange := func(method string, target string, reqBody string, status int, respBody string) HttpExchange {
+		req := httptest.NewRequest(method, target, strings.NewReader(reqBody))
+		req.Header.Set("User-Agent", "test")
+		if strings.HasPrefix(target, "/pets/") {
+			req.Header.Set("X-Tenant", "acme")
+		}
+		reqBytes := []byte(reqBody)
+		if reqBody != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		respBytes := []byte(respBody)
+		respHeaders := http.Header{"Content-Type": {"application/json; charset=utf-8"}}
+		return HttpExchange{
+			Request:         req,
+			RequestBody:     &reqBytes,
+			StatusCode:      status,
+			ResponseBody:    &respBytes,
+			ResponseHeaders: &respHeaders,
+		}
+	}
+
+	dir := t.TempDir()
+	g, err := newSpecGenerator("http://localhost:8081", dir, nil)
+	require.NoError(t, err)
+
+	g.record(newExchange("GET", "/pets/1", "", 200, `{"id":1,"name":"Fluffy","tag":null}`))
+	g.record(newExchange("GET", "/pets/2?expand=true", "", 200, `{"id":2,"name":"Rex","tag":"dog","age":3}`))
+	g.record(newExchange("GET", "/pets/2?expand=true", "", 200, `{"id":2,"name":"Rex","tag":"dog","age":3}`))
+	g.record(newExchange("GET", "/pets/99", "", 404, ``))
+	g.record(newExchange("POST", "/pets", `{"name":"Tom"}`, 201, `{"id":3,"name":"Tom"}`))
+
+	specFile := filepath.Join(dir, "localhost-8081-openapi.yaml")
+	spec, err := openapi.Parse(specFile)
+	require.NoError(t, err)
+	require.Equal(t, "3.0.3", spec.Version)
+	require.Equal(t, "http://localhost:8081", spec.Servers[0].Url)
+	require.Equal(t, []string{"/pets", "/pets/{id}"}, spec.SortedPaths())
+
+	getPet := spec.Paths["/pets/{id}"]["get"]
+	require.Equal(t, []openapi.Parameter{
+		{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: openapi.SchemaType{"integer"}}, Example: json.Number("1")},
+		{Name: "expand", In: "query", Schema: &openapi.Schema{Type: openapi.SchemaType{"boolean"}}, Example: true},
+		{Name: "X-Tenant", In: "header", Required: true, Schema: &openapi.Schema{Type: openapi.SchemaType{"string"}}, Example: "acme"},
+	}, getPet.Parameters, "parameters should be described, without headers set by every client")
+	require.Nil(t, getPet.RequestBody)
+
+	ok := getPet.Responses["200"]
+	require.Equal(t, "OK", ok.Description)
+	schema := ok.Content["application/json"].Schema
+	require.Equal(t, []string{"id", "name", "tag"}, schema.Required, "schemas of all bodies should be merged")
+	require.True(t, schema.Properties["tag"].Nullable)
+	require.Equal(t, openapi.SchemaType{"integer"}, schema.Properties["age"].Type)
+	require.Len(t, ok.Content["application/json"].Examples, 2, "identical bodies should be recorded once")
+	require.Equal(t, map[string]any{"id": json.Number("1"), "name": "Fluffy", "tag": nil}, ok.Content["application/json"].Examples["pets-1"].Value)
+	require.Contains(t, ok.Content["application/json"].Examples, "pets-2-expand-true")
+
+	notFound := getPet.Responses["404"]
+	require.Equal(t, "Not Found", notFound.Description)
+	require.Empty(t, notFound.Content)
+
+	createPet := spec.Paths["/pets"]["post"]
+	require.True(t, createPet.RequestBody.Required)
+	require.Equal(t, []string{"name"}, createPet.RequestBody.Content["application/json"].Schema.Required)
+	require.Equal(t, map[string]any{"name": "Tom"}, createPet.RequestBody.Content["application/json"].Examples["pets"].Value)
+	require.Contains(t, createPet.Responses, "201")
+
+	_, err = newSpecGenerator("http://localhost:8081", dir, nil)
+	require.ErrorContains(t, err, "already exists")
+	_, err = os.Stat(specFile + ".tmp")
+	require.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,68 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// identifierPatterns match path segments that look like identifiers,
+// rather than fixed parts of the path.
+var identifierPatterns = []*regexp.Regexp{
+	// numeric, e.g. /users/123
+	regexp.MustCompile(`^\d+$`),
+	// UUID, e.g. /users/0f8fad5b-d9cb-469f-a165-70867728950e
+	regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`),
+	// hash-like, e.g. commit SHAs or object IDs
+	regexp.MustCompile(`^[0-9a-fA-F]{16,}$`),
+}
+
+// templatePath replaces identifier-like segments of the path with
+// path parameter placeholders, e.g. /users/123 becomes /users/{id}.
+// The values of the placeholders are returned, keyed by parameter name.
+// If no segments are templated, the path is returned unchanged, with
+// no parameters.
+func templatePath(urlPath string) (string, map[string]string) {
+	segments := strings.Split(urlPath, "/")
+	params := make(map[string]string)
+	for i, segment := range segments {
+		if !isIdentifier(segment) {
+			continue
+		}
+		name := "id"
+		if len(params) > 0 {
+			name = fmt.Sprintf("id%d", len(params)+1)
+		}
+		params[name] = segment
+		segments[i] = "{" + name + "}"
+	}
+	if len(params) == 0 {
+		return urlPath, nil
+	}
+	return strings.Join(segments, "/"), params
+}
+
+func isIdentifier(segment string) bool {
+	for _, pattern := range identifierPatterns {
+		if pattern.MatchString(segment) {
+			return true
+		}
+	}
+	return false
+}
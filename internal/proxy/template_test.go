@@ -0,0 +1,46 @@
+package proxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_templatePath(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		wantPath   string
+		wantParams map[string]string
+	}{
+		{name: "no identifiers", path: "/users/me", wantPath: "/users/me", wantParams: nil},
+		{name: "root", path: "/", wantPath: "/", wantParams: nil},
+		{name: "numeric", path: "/users/123", wantPath: "/users/{id}", wantParams: map[string]string{"id": "123"}},
+		{
+			name:       "uuid",
+			path:       "/orders/0f8fad5b-d9cb-469f-a165-70867728950e/items",
+			wantPath:   "/orders/{id}/items",
+			wantParams: map[string]string{"id": "0f8fad5b-d9cb-469f-a165-70867728950e"},
+		},
+		{
+			name:       "hash",
+			path:       "/commits/9fceb02d0ae598e95dc970b74767f19372d61af8",
+			wantPath:   "/commits/{id}",
+			wantParams: map[string]string{"id": "9fceb02d0ae598e95dc970b74767f19372d61af8"},
+		},
+		{
+			name:       "multiple",
+			path:       "/users/1/orders/42",
+			wantPath:   "/users/{id}/orders/{id2}",
+			wantParams: map[string]string{"id": "1", "id2": "42"},
+		},
+		{name: "short hex word", path: "/cafe/decade", wantPath: "/cafe/decade", wantParams: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotPath, gotParams := templatePath(tt.path)
+			require.Equal(t, tt.wantPath, gotPath)
+			require.Equal(t, tt.wantParams, gotParams)
+		})
+	}
+}
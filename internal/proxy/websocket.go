@@ -0,0 +1,417 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebSocket frame opcodes, from RFC 6455 section 5.2.
+const (
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+)
+
+// wsAcceptGuid is appended to the key of a WebSocket handshake to
+// generate the accept value, from RFC 6455 section 1.3.
+const wsAcceptGuid = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// wsCloseTimeout is how long to wait for the peer to complete the
+// closing handshake, once one side of the connection has closed.
+const wsCloseTimeout = 5 * time.Second
+
+// WebSocketDirection is the direction of a WebSocket message,
+// from the point of view of the client.
+type WebSocketDirection string
+
+const (
+	WebSocketSend    WebSocketDirection = "send"
+	WebSocketReceive WebSocketDirection = "receive"
+)
+
+// WebSocketMessage is a complete text or binary message sent
+// over a WebSocket connection, reassembled from its frames.
+type WebSocketMessage struct {
+	Direction WebSocketDirection
+
+	// Offset is the time since the connection was established.
+	Offset time.Duration
+
+	Binary bool
+	Data   []byte
+
+	// Truncated is set if the message exceeded the maximum size
+	// retained for recording.
+	Truncated bool
+}
+
+// IsWebSocketRequest returns true if the request is a WebSocket handshake.
+func IsWebSocketRequest(req *http.Request) bool {
+	if !strings.EqualFold(req.Header.Get("Upgrade"), "websocket") {
+		return false
+	}
+	for _, value := range req.Header.Values("Connection") {
+		for _, token := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), "upgrade") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// handleWebSocket forwards the WebSocket handshake to the upstream and, if
+// the upstream accepts it, relays frames in both directions until either
+// side closes the connection. The messages exchanged are passed to the
+// listener once the connection has closed.
+func handleWebSocket(
+	upstream string,
+	w http.ResponseWriter,
+	req *http.Request,
+	options HandleOptions,
+	listener func(exchange HttpExchange),
+) {
+	startTime := time.Now()
+	client := req.RemoteAddr
+	logger.Debugf("received WebSocket handshake %v from client %v", req.URL, client)
+
+	upstreamConn, upstreamReader, resp, err := dialWebSocket(upstream, req, options.Insecure)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	defer upstreamConn.Close()
+	respHeaders := resp.Header
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		logger.Warnf("upstream rejected WebSocket handshake for %v with status %d", req.URL, resp.StatusCode)
+		defer resp.Body.Close()
+		respBody := newCappedBuffer(options.MaxBodySize)
+		if err := streamResponse(w, resp, respBody, client); err != nil {
+			logger.Error(err)
+			return
+		}
+		responseBody := respBody.Bytes()
+		listener(HttpExchange{
+			Upstream:              upstream,
+			Request:               req,
+			RequestBody:           &[]byte{},
+			StatusCode:            resp.StatusCode,
+			ResponseBody:          &responseBody,
+			ResponseBodyTruncated: respBody.truncated,
+			ResponseHeaders:       &respHeaders,
+			StartTime:             startTime,
+			Elapsed:               time.Since(startTime),
+		})
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		logger.Errorf("cannot hijack connection for WebSocket %v from client %v", req.URL, client)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	clientConn, clientRW, err := hijacker.Hijack()
+	if err != nil {
+		logger.Errorf("failed to hijack connection for WebSocket %v from client %v: %v", req.URL, client, err)
+		return
+	}
+	defer clientConn.Close()
+
+	var handshake bytes.Buffer
+	handshake.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	_ = resp.Header.Write(&handshake)
+	handshake.WriteString("\r\n")
+	if _, err := clientConn.Write(handshake.Bytes()); err != nil {
+		logger.Errorf("failed to complete WebSocket handshake with client %v: %v", client, err)
+		return
+	}
+	logger.Infof("established WebSocket %v to upstream for client %v", req.URL, client)
+
+	session := &webSocketSession{start: time.Now(), maxMessageSize: options.MaxBodySize}
+	relayWebSocket(clientConn, clientRW.Reader, upstreamConn, upstreamReader, session)
+
+	elapsed := time.Since(startTime)
+	messages := session.messages
+	listener(HttpExchange{
+		Upstream:          upstream,
+		Request:           req,
+		RequestBody:       &[]byte{},
+		StatusCode:        resp.StatusCode,
+		ResponseBody:      &[]byte{},
+		ResponseHeaders:   &respHeaders,
+		StartTime:         startTime,
+		Elapsed:           elapsed,
+		WebSocketMessages: &messages,
+	})
+	logger.Infof("closed WebSocket %v for client %v after %v [%d messages]", req.URL, client, elapsed, len(messages))
+}
+
+// dialWebSocket opens a connection to the upstream and sends the handshake.
+// Extensions are not offered, so that frames are not compressed and can
+// be recorded.
+func dialWebSocket(upstream string, clientReq *http.Request, insecure bool) (net.Conn, *bufio.Reader, *http.Response, error) {
+	upstreamUrl, err := buildUpstreamUrl(upstream, clientReq)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	parsed, err := url.Parse(upstreamUrl)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to parse upstream URL: %v", err)
+	}
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	var conn net.Conn
+	if parsed.Scheme == "https" {
+		address := hostWithDefaultPort(parsed, "443")
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, &tls.Config{
+			ServerName:         parsed.Hostname(),
+			InsecureSkipVerify: insecure,
+			NextProtos:         []string{"http/1.1"},
+		})
+	} else {
+		conn, err = dialer.Dial("tcp", hostWithDefaultPort(parsed, "80"))
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to connect to upstream %s for WebSocket: %v", upstream, err)
+	}
+
+	req, err := http.NewRequest(clientReq.Method, upstreamUrl, nil)
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, nil, fmt.Errorf("failed to build upstream request: %v", err)
+	}
+	upstreamReqHeaders := req.Header
+	copyHeaders(&clientReq.Header, &upstreamReqHeaders)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Del("Sec-WebSocket-Extensions")
+
+	if err := req.Write(conn); err != nil {
+		_ = conn.Close()
+		return nil, nil, nil, fmt.Errorf("failed to send WebSocket handshake to upstream %s: %v", upstream, err)
+	}
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, nil, fmt.Errorf("failed to read WebSocket handshake response from upstream %s: %v", upstream, err)
+	}
+	return conn, reader, resp, nil
+}
+
+func hostWithDefaultPort(u *url.URL, defaultPort string) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	return net.JoinHostPort(u.Hostname(), defaultPort)
+}
+
+// relayWebSocket copies frames in both directions until either side
+// closes the connection, recording the messages to the session.
+func relayWebSocket(clientConn net.Conn, clientReader io.Reader, upstreamConn net.Conn, upstreamReader io.Reader, session *webSocketSession) {
+	done := make(chan error, 2)
+	go func() {
+		done <- relayWebSocketFrames(upstreamConn, clientReader, WebSocketSend, session)
+	}()
+	go func() {
+		done <- relayWebSocketFrames(clientConn, upstreamReader, WebSocketReceive, session)
+	}()
+
+	if err := <-done; err != nil {
+		// the connection was not closed cleanly, so there is no closing handshake to wait for
+		logger.Debugf("WebSocket relay ended: %v", err)
+		_ = clientConn.Close()
+		_ = upstreamConn.Close()
+	} else {
+		deadline := time.Now().Add(wsCloseTimeout)
+		_ = clientConn.SetDeadline(deadline)
+		_ = upstreamConn.SetDeadline(deadline)
+	}
+	<-done
+}
+
+// relayWebSocketFrames copies frames from src to dst as they are read,
+// reassembling text and binary messages for the session. It returns nil
+// once a close frame has been relayed.
+func relayWebSocketFrames(dst io.Writer, src io.Reader, direction WebSocketDirection, session *webSocketSession) error {
+	reader := io.TeeReader(src, dst)
+	var message *cappedBuffer
+	var messageBinary bool
+	var messageOffset time.Duration
+
+	for {
+		header, err := readWebSocketFrameHeader(reader)
+		if err != nil {
+			return err
+		}
+
+		var payload io.Writer = io.Discard
+		switch header.opcode {
+		case wsOpText, wsOpBinary:
+			message = newCappedBuffer(session.maxMessageSize)
+			messageBinary = header.opcode == wsOpBinary
+			messageOffset = time.Since(session.start)
+			payload = message
+		case wsOpContinuation:
+			if message != nil {
+				payload = message
+			}
+		}
+		if err := readWebSocketPayload(reader, header, payload); err != nil {
+			return err
+		}
+
+		if header.opcode == wsOpClose {
+			return nil
+		}
+		if header.fin && message != nil && header.opcode < wsOpClose {
+			session.add(WebSocketMessage{
+				Direction: direction,
+				Offset:    messageOffset,
+				Binary:    messageBinary,
+				Data:      message.Bytes(),
+				Truncated: message.truncated,
+			})
+			message = nil
+		}
+	}
+}
+
+// webSocketAccept returns the Sec-WebSocket-Accept value for the key
+// sent by the client in its handshake.
+func webSocketAccept(key string) string {
+	hash := sha1.Sum([]byte(key + wsAcceptGuid))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// writeWebSocketFrame writes the payload as a single unmasked frame,
+// as sent by a server.
+func writeWebSocketFrame(w io.Writer, opcode byte, payload []byte) error {
+	frame := []byte{0x80 | opcode}
+	switch length := len(payload); {
+	case length < 126:
+		frame = append(frame, byte(length))
+	case length <= 0xffff:
+		frame = binary.BigEndian.AppendUint16(append(frame, 126), uint16(length))
+	default:
+		frame = binary.BigEndian.AppendUint64(append(frame, 127), uint64(length))
+	}
+	_, err := w.Write(append(frame, payload...))
+	return err
+}
+
+type wsFrameHeader struct {
+	fin     bool
+	opcode  byte
+	length  uint64
+	masked  bool
+	maskKey [4]byte
+}
+
+func readWebSocketFrameHeader(r io.Reader) (wsFrameHeader, error) {
+	var header wsFrameHeader
+	var buf [8]byte
+	if _, err := io.ReadFull(r, buf[:2]); err != nil {
+		return header, err
+	}
+	header.fin = buf[0]&0x80 != 0
+	header.opcode = buf[0] & 0x0f
+	header.masked = buf[1]&0x80 != 0
+
+	switch length := buf[1] & 0x7f; length {
+	case 126:
+		if _, err := io.ReadFull(r, buf[:2]); err != nil {
+			return header, err
+		}
+		header.length = uint64(binary.BigEndian.Uint16(buf[:2]))
+	case 127:
+		if _, err := io.ReadFull(r, buf[:8]); err != nil {
+			return header, err
+		}
+		header.length = binary.BigEndian.Uint64(buf[:8])
+	default:
+		header.length = uint64(length)
+	}
+
+	if header.masked {
+		if _, err := io.ReadFull(r, header.maskKey[:]); err != nil {
+			return header, err
+		}
+	}
+	return header, nil
+}
+
+// readWebSocketPayload reads the frame payload from r, unmasking it
+// if required, and writes it to w.
+func readWebSocketPayload(r io.Reader, header wsFrameHeader, w io.Writer) error {
+	buf := make([]byte, 32*1024)
+	var pos uint64
+	for pos < header.length {
+		chunk := buf
+		if remaining := header.length - pos; remaining < uint64(len(chunk)) {
+			chunk = chunk[:remaining]
+		}
+		n, err := io.ReadFull(r, chunk)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		if header.masked {
+			for i := 0; i < n; i++ {
+				chunk[i] ^= header.maskKey[(pos+uint64(i))%4]
+			}
+		}
+		_, _ = w.Write(chunk[:n])
+		pos += uint64(n)
+	}
+	return nil
+}
+
+// webSocketSession collects the messages relayed in both directions.
+type webSocketSession struct {
+	start          time.Time
+	maxMessageSize int64
+	mutex          sync.Mutex
+	messages       []WebSocketMessage
+}
+
+func (s *webSocketSession) add(message WebSocketMessage) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.messages = append(s.messages, message)
+}
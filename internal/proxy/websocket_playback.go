@@ -0,0 +1,143 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	impostermodel2 "github.com/imposter-project/imposter-cli/internal/impostermodel"
+)
+
+// wsNormalClosure is the payload of a close frame with status 1000.
+var wsNormalClosure = []byte{0x03, 0xe8}
+
+// errWebSocketClosed is returned when the client closes the connection
+// before the session has been replayed.
+var errWebSocketClosed = errors.New("client closed the WebSocket")
+
+// replayWebSocket completes the WebSocket handshake, then replays the
+// session file of the resource. An error is only returned if the session
+// cannot be replayed, in which case the request can still be forwarded.
+func (p *Playback) replayWebSocket(w http.ResponseWriter, req *http.Request, resource *impostermodel2.Resource) error {
+	session, err := loadWebSocketSession(filepath.Join(p.dir, resource.Response.File))
+	if err != nil {
+		return err
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return fmt.Errorf("cannot hijack connection for WebSocket %v", req.URL)
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return fmt.Errorf("failed to hijack connection for WebSocket %v: %v", req.URL, err)
+	}
+	defer conn.Close()
+
+	var handshake bytes.Buffer
+	handshake.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	handshake.WriteString("Sec-WebSocket-Accept: " + webSocketAccept(req.Header.Get("Sec-WebSocket-Key")) + "\r\n")
+	if session.Subprotocol != "" {
+		handshake.WriteString("Sec-WebSocket-Protocol: " + session.Subprotocol + "\r\n")
+	}
+	handshake.WriteString("\r\n")
+	if _, err := conn.Write(handshake.Bytes()); err != nil {
+		logger.Errorf("failed to complete WebSocket handshake with client %v: %v", req.RemoteAddr, err)
+		return nil
+	}
+
+	if err := replayWebSocketSession(conn, rw.Reader, session); err != nil {
+		logger.Debugf("WebSocket replay of %s ended: %v", resource.Response.File, err)
+	}
+	return nil
+}
+
+// replayWebSocketSession sends the messages received from the upstream in
+// the session, keeping the time between messages. Where the client sent a
+// message, the replay waits for the client to send one, whatever its
+// content. Once the session has been replayed, the connection is closed.
+func replayWebSocketSession(conn net.Conn, reader io.Reader, session *WebSocketSessionFile) error {
+	var lastTimeMs int64
+	for _, message := range session.Messages {
+		switch message.Direction {
+		case WebSocketSend:
+			if err := readWebSocketMessage(reader); err != nil {
+				if errors.Is(err, errWebSocketClosed) {
+					_ = writeWebSocketFrame(conn, wsOpClose, wsNormalClosure)
+				}
+				return err
+			}
+		case WebSocketReceive:
+			if delay := message.TimeMs - lastTimeMs; delay > 0 {
+				time.Sleep(time.Duration(delay) * time.Millisecond)
+			}
+			opcode, data := byte(wsOpText), []byte(message.Data)
+			if message.Type == "binary" {
+				var err error
+				if data, err = base64.StdEncoding.DecodeString(message.Data); err != nil {
+					return fmt.Errorf("failed to decode binary message: %v", err)
+				}
+				opcode = wsOpBinary
+			}
+			if err := writeWebSocketFrame(conn, opcode, data); err != nil {
+				return err
+			}
+		}
+		lastTimeMs = message.TimeMs
+	}
+
+	if err := writeWebSocketFrame(conn, wsOpClose, wsNormalClosure); err != nil {
+		return err
+	}
+	_ = conn.SetDeadline(time.Now().Add(wsCloseTimeout))
+	for {
+		if err := readWebSocketMessage(reader); err != nil {
+			if errors.Is(err, errWebSocketClosed) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// readWebSocketMessage reads frames from the client until a text or binary
+// message is complete, discarding its content. Control frames, other than
+// close, are ignored.
+func readWebSocketMessage(reader io.Reader) error {
+	for {
+		header, err := readWebSocketFrameHeader(reader)
+		if err != nil {
+			return err
+		}
+		if err := readWebSocketPayload(reader, header, io.Discard); err != nil {
+			return err
+		}
+		if header.opcode == wsOpClose {
+			return errWebSocketClosed
+		}
+		if header.fin && header.opcode < wsOpClose {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,189 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	impostermodel2 "github.com/imposter-project/imposter-cli/internal/impostermodel"
+	"github.com/imposter-project/imposter-cli/internal/stringutil"
+)
+
+// WebSocketSessionFile is the recording of a WebSocket connection. Messages
+// are in the order they were relayed, with the time of each relative to the
+// connection being established, so the pacing of the session is kept.
+// A resource referencing the session file is added to the configuration,
+// so that the session is replayed by Playback.
+type WebSocketSessionFile struct {
+	Path        string                    `json:"path"`
+	Query       string                    `json:"query,omitempty"`
+	Subprotocol string                    `json:"subprotocol,omitempty"`
+	Messages    []WebSocketSessionMessage `json:"messages"`
+}
+
+// WebSocketSessionMessage is a single message in a recorded session.
+// Direction is "send" for messages from the client and "receive" for
+// messages from the upstream. Binary message data is base64 encoded.
+type WebSocketSessionMessage struct {
+	Direction WebSocketDirection `json:"direction"`
+	TimeMs    int64              `json:"timeMs"`
+	Type      string             `json:"type"`
+	Data      string             `json:"data"`
+	Truncated bool               `json:"truncated,omitempty"`
+}
+
+// recordWebSocket writes the messages of a WebSocket exchange to a new
+// session file. Each connection is recorded separately, even if it has
+// the same path as an earlier one, but only the first session for a
+// request is added as a resource, as only one can be replayed.
+func (r *recorder) recordWebSocket(original HttpExchange, exchange HttpExchange) error {
+	req := exchange.Request
+	session := WebSocketSessionFile{
+		Path:        req.URL.Path,
+		Query:       req.URL.RawQuery,
+		Subprotocol: exchange.ResponseHeaders.Get("Sec-WebSocket-Protocol"),
+		Messages:    []WebSocketSessionMessage{},
+	}
+	for _, message := range *exchange.WebSocketMessages {
+		sessionMessage := WebSocketSessionMessage{
+			Direction: message.Direction,
+			TimeMs:    message.Offset.Milliseconds(),
+			Type:      "text",
+			Data:      string(message.Data),
+			Truncated: message.Truncated,
+		}
+		if message.Binary {
+			sessionMessage.Type = "binary"
+			sessionMessage.Data = base64.StdEncoding.EncodeToString(message.Data)
+		}
+		session.Messages = append(session.Messages, sessionMessage)
+	}
+
+	content, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal WebSocket session for %v: %v", req.URL, err)
+	}
+	sessionFile, err := r.generateWebSocketSessionFileName(exchange)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(sessionFile, content, 0644); err != nil {
+		return fmt.Errorf("failed to write WebSocket session file %s for %v: %v", sessionFile, req.URL, err)
+	}
+	logger.Infof("wrote WebSocket session file %s for %v [%d messages]", sessionFile, req.URL, len(session.Messages))
+
+	resource, err := r.buildWebSocketResource(exchange, sessionFile)
+	if err != nil {
+		return err
+	}
+	requestHash := getResourceHash(*resource)
+	if stringutil.Contains(r.requestHashes, requestHash) {
+		logger.Debugf("WebSocket %v is already replayed from an earlier session - not adding resource", req.URL)
+		return nil
+	}
+	r.requestHashes = append(r.requestHashes, requestHash)
+	omitRedactedMatchers(resource, original, exchange)
+	r.resources = append(r.resources, *resource)
+	return updateConfigFile(exchange, r.genOptions, r.resources, r.configFile)
+}
+
+// buildWebSocketResource returns a resource matching the WebSocket
+// handshake, which responds with the session file. The handshake is
+// matched by its Upgrade header, so plain requests to the same path
+// are not matched.
+func (r *recorder) buildWebSocketResource(exchange HttpExchange, sessionFile string) (*impostermodel2.Resource, error) {
+	req := exchange.Request
+	relSessionFile, err := filepath.Rel(r.dir, sessionFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get relative path for WebSocket session file: %s: %v", sessionFile, err)
+	}
+	resource := &impostermodel2.Resource{
+		Path:           req.URL.Path,
+		Method:         req.Method,
+		RequestHeaders: &map[string]string{"Upgrade": "websocket"},
+		Response: &impostermodel2.ResponseConfig{
+			StatusCode: http.StatusSwitchingProtocols,
+			File:       filepath.ToSlash(relSessionFile),
+		},
+	}
+	if query := req.URL.Query(); len(query) > 0 {
+		queryParams := make(map[string]string)
+		for name, values := range query {
+			queryParams[name] = values[0]
+		}
+		resource.QueryParams = &queryParams
+	}
+	return resource, nil
+}
+
+// loadWebSocketSession reads a session file written by the recorder.
+func loadWebSocketSession(sessionFile string) (*WebSocketSessionFile, error) {
+	content, err := os.ReadFile(sessionFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read WebSocket session file %s: %v", sessionFile, err)
+	}
+	var session WebSocketSessionFile
+	if err := json.Unmarshal(content, &session); err != nil {
+		return nil, fmt.Errorf("failed to parse WebSocket session file %s: %v", sessionFile, err)
+	}
+	return &session, nil
+}
+
+// generateWebSocketSessionFileName returns an unused file name for the
+// session, following the same structure as response files, numbered
+// so that repeated connections to the same path are kept.
+func (r *recorder) generateWebSocketSessionFileName(exchange HttpExchange) (string, error) {
+	req := exchange.Request
+	sanitisedParent := strings.TrimPrefix(path.Dir(req.URL.EscapedPath()), "/")
+	if sanitisedParent == "." {
+		sanitisedParent = ""
+	}
+	baseFileName := path.Base(req.URL.EscapedPath())
+	if baseFileName == "/" || baseFileName == "." {
+		baseFileName = "index"
+	}
+
+	var parentDir, stem string
+	if r.options.FlatResponseFileStructure {
+		flatParent := strings.ReplaceAll(sanitisedParent, "/", "_")
+		if len(flatParent) > 0 {
+			flatParent += "_"
+		}
+		parentDir = r.dir
+		stem = r.upstreamHost + "-WS-" + flatParent + baseFileName
+	} else {
+		parentDir = path.Join(r.dir, sanitisedParent)
+		if err := ensureDirExists(parentDir); err != nil {
+			return "", err
+		}
+		stem = "WS-" + baseFileName
+	}
+
+	for i := 1; ; i++ {
+		sessionFile := path.Join(parentDir, fmt.Sprintf("%s-%d.json", stem, i))
+		if _, err := os.Stat(sessionFile); os.IsNotExist(err) {
+			return sessionFile, nil
+		}
+	}
+}
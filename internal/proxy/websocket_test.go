@@ -0,0 +1,270 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/imposter-project/imposter-cli/internal/impostermodel"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func writeTestFrame(t *testing.T, w io.Writer, opcode byte, payload []byte, masked bool) {
+	var frame bytes.Buffer
+	frame.WriteByte(0x80 | opcode)
+	var maskBit byte
+	if masked {
+		maskBit = 0x80
+	}
+	switch {
+	case len(payload) < 126:
+		frame.WriteByte(maskBit | byte(len(payload)))
+	default:
+		frame.WriteByte(maskBit | 126)
+		_ = binary.Write(&frame, binary.BigEndian, uint16(len(payload)))
+	}
+	if masked {
+		key := [4]byte{1, 2, 3, 4}
+		frame.Write(key[:])
+		for i, b := range payload {
+			frame.WriteByte(b ^ key[i%4])
+		}
+	} else {
+		frame.Write(payload)
+	}
+	_, err := w.Write(frame.Bytes())
+	require.NoError(t, err)
+}
+
+func readTestFrame(t *testing.T, r io.Reader) (byte, []byte) {
+	header, err := readWebSocketFrameHeader(r)
+	require.NoError(t, err)
+	var payload bytes.Buffer
+	require.NoError(t, readWebSocketPayload(r, header, &payload))
+	return header.opcode, payload.Bytes()
+}
+
+func TestIsWebSocketRequest(t *testing.T) {
+	req := httptest.NewRequest("GET", "/ws", nil)
+	require.False(t, IsWebSocketRequest(req))
+
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "keep-alive, Upgrade")
+	require.True(t, IsWebSocketRequest(req))
+
+	req.Header.Set("Upgrade", "h2c")
+	require.False(t, IsWebSocketRequest(req))
+}
+
+func TestHandleWebSocket(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Sec-WebSocket-Extensions") != "" {
+			t.Errorf("extensions should not be offered to upstream")
+		}
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+			"Sec-WebSocket-Accept: " + webSocketAccept(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+		_ = rw.Flush()
+
+		opcode, payload := readTestFrame(t, rw.Reader)
+		require.Equal(t, byte(wsOpText), opcode)
+		writeTestFrame(t, conn, wsOpText, append([]byte("echo: "), payload...), false)
+		writeTestFrame(t, conn, wsOpBinary, []byte{0x00, 0xff}, false)
+
+		opcode, _ = readTestFrame(t, rw.Reader)
+		require.Equal(t, byte(wsOpClose), opcode)
+		writeTestFrame(t, conn, wsOpClose, nil, false)
+	}))
+	defer upstream.Close()
+
+	exchanges := make(chan HttpExchange, 1)
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Handle(upstream.URL, w, r, HandleOptions{}, func(exchange HttpExchange) {
+			exchanges <- exchange
+		})
+	}))
+	defer proxyServer.Close()
+
+	conn, err := net.Dial("tcp", proxyServer.Listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	key := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef"))
+	_, err = conn.Write([]byte("GET /chat?room=1 HTTP/1.1\r\nHost: localhost\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Extensions: permessage-deflate\r\nSec-WebSocket-Key: " + key + "\r\n\r\n"))
+	require.NoError(t, err)
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	require.Equal(t, webSocketAccept(key), resp.Header.Get("Sec-WebSocket-Accept"))
+
+	writeTestFrame(t, conn, wsOpText, []byte("hello"), true)
+	opcode, payload := readTestFrame(t, reader)
+	require.Equal(t, byte(wsOpText), opcode)
+	require.Equal(t, "echo: hello", string(payload))
+	opcode, payload = readTestFrame(t, reader)
+	require.Equal(t, byte(wsOpBinary), opcode)
+	require.Equal(t, []byte{0x00, 0xff}, payload)
+
+	writeTestFrame(t, conn, wsOpClose, nil, true)
+	opcode, _ = readTestFrame(t, reader)
+	require.Equal(t, byte(wsOpClose), opcode)
+
+	exchange := <-exchanges
+	require.Equal(t, http.StatusSwitchingProtocols, exchange.StatusCode)
+	require.NotNil(t, exchange.WebSocketMessages)
+	messages := *exchange.WebSocketMessages
+	require.Len(t, messages, 3)
+	require.Equal(t, WebSocketSend, messages[0].Direction)
+	require.Equal(t, "hello", string(messages[0].Data))
+	require.Equal(t, WebSocketReceive, messages[1].Direction)
+	require.Equal(t, "echo: hello", string(messages[1].Data))
+	require.True(t, messages[2].Binary)
+	require.Equal(t, []byte{0x00, 0xff}, messages[2].Data)
+}
+
+func TestRelayWebSocketFrames_fragmented(t *testing.T) {
+	var src bytes.Buffer
+	// a text message split across two frames, with a ping between them
+	src.Write([]byte{wsOpText, 3})
+	src.WriteString("hel")
+	src.Write([]byte{0x80 | 0x9, 0})
+	src.Write([]byte{0x80 | wsOpContinuation, 2})
+	src.WriteString("lo")
+	src.Write([]byte{0x80 | wsOpClose, 0})
+	original := append([]byte{}, src.Bytes()...)
+
+	var dst bytes.Buffer
+	session := &webSocketSession{start: time.Now(), maxMessageSize: 4}
+	require.NoError(t, relayWebSocketFrames(&dst, &src, WebSocketReceive, session))
+
+	require.Equal(t, original, dst.Bytes(), "frames should be relayed unchanged")
+	require.Len(t, session.messages, 1)
+	require.Equal(t, "hell", string(session.messages[0].Data))
+	require.True(t, session.messages[0].Truncated)
+}
+
+func TestRecorder_recordWebSocket(t *testing.T) {
+	dir := t.TempDir()
+	r, err := newRecorder("http://localhost:8080", dir, RecorderOptions{})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("GET", "/chat/room?user=1", nil)
+	respHeaders := http.Header{"Sec-Websocket-Protocol": {"chat"}}
+	messages := []WebSocketMessage{
+		{Direction: WebSocketSend, Offset: 5 * time.Millisecond, Data: []byte("hello")},
+		{Direction: WebSocketReceive, Offset: 20 * time.Millisecond, Binary: true, Data: []byte{0x00, 0xff}},
+	}
+	exchange := HttpExchange{
+		Request:           req,
+		RequestBody:       &[]byte{},
+		StatusCode:        http.StatusSwitchingProtocols,
+		ResponseBody:      &[]byte{},
+		ResponseHeaders:   &respHeaders,
+		WebSocketMessages: &messages,
+	}
+	r.record(exchange)
+	r.record(exchange)
+
+	first, err := os.ReadFile(filepath.Join(dir, "chat", "WS-room-1.json"))
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+  "path": "/chat/room",
+  "query": "user=1",
+  "subprotocol": "chat",
+  "messages": [
+    {"direction": "send", "timeMs": 5, "type": "text", "data": "hello"},
+    {"direction": "receive", "timeMs": 20, "type": "binary", "data": "AP8="}
+  ]
+}`, string(first))
+	require.FileExists(t, filepath.Join(dir, "chat", "WS-room-2.json"), "each connection should be recorded")
+
+	raw, err := os.ReadFile(filepath.Join(dir, "localhost-8080-config.yaml"))
+	require.NoError(t, err)
+	var config impostermodel.PluginConfig
+	require.NoError(t, yaml.Unmarshal(raw, &config))
+	require.Len(t, config.Resources, 1, "only the first session for a request should be replayed")
+	resource := config.Resources[0]
+	require.Equal(t, "/chat/room", resource.Path)
+	require.Equal(t, map[string]string{"Upgrade": "websocket"}, *resource.RequestHeaders)
+	require.Equal(t, map[string]string{"user": "1"}, *resource.QueryParams)
+	require.Equal(t, http.StatusSwitchingProtocols, resource.Response.StatusCode)
+	require.Equal(t, "chat/WS-room-1.json", resource.Response.File)
+}
+
+func TestPlayback_Serve_webSocket(t *testing.T) {
+	dir := t.TempDir()
+	req := httptest.NewRequest("GET", "/chat", nil)
+	req.Header.Set("Upgrade", "websocket")
+	respHeaders := http.Header{"Sec-Websocket-Protocol": {"chat"}}
+	messages := []WebSocketMessage{
+		{Direction: WebSocketReceive, Offset: 0, Data: []byte("welcome")},
+		{Direction: WebSocketSend, Offset: 5 * time.Millisecond, Data: []byte("hello")},
+		{Direction: WebSocketReceive, Offset: 10 * time.Millisecond, Binary: true, Data: []byte{0x00, 0xff}},
+	}
+	require.NoError(t, RecordExchanges("http://example.com", dir, RecorderOptions{}, []HttpExchange{{
+		Request:           req,
+		RequestBody:       &[]byte{},
+		StatusCode:        http.StatusSwitchingProtocols,
+		ResponseBody:      &[]byte{},
+		ResponseHeaders:   &respHeaders,
+		WebSocketMessages: &messages,
+	}}))
+
+	playback, err := NewPlayback("http://example.com", dir)
+	require.NoError(t, err)
+	require.False(t, playback.Serve(httptest.NewRecorder(), httptest.NewRequest("GET", "/chat", nil)),
+		"plain requests should not match a WebSocket session")
+
+	served := make(chan bool, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served <- playback.Serve(w, r)
+	}))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	key := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef"))
+	_, err = conn.Write([]byte("GET /chat HTTP/1.1\r\nHost: localhost\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Protocol: chat\r\nSec-WebSocket-Key: " + key + "\r\n\r\n"))
+	require.NoError(t, err)
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	require.Equal(t, webSocketAccept(key), resp.Header.Get("Sec-WebSocket-Accept"))
+	require.Equal(t, "chat", resp.Header.Get("Sec-WebSocket-Protocol"))
+
+	opcode, payload := readTestFrame(t, reader)
+	require.Equal(t, byte(wsOpText), opcode)
+	require.Equal(t, "welcome", string(payload))
+
+	writeTestFrame(t, conn, wsOpText, []byte("hi"), true)
+	opcode, payload = readTestFrame(t, reader)
+	require.Equal(t, byte(wsOpBinary), opcode)
+	require.Equal(t, []byte{0x00, 0xff}, payload, "upstream message should be sent once the client has sent its message")
+
+	opcode, _ = readTestFrame(t, reader)
+	require.Equal(t, byte(wsOpClose), opcode, "connection should be closed once the session has been replayed")
+	writeTestFrame(t, conn, wsOpClose, nil, true)
+	require.True(t, <-served)
+}
@@ -207,17 +207,15 @@ func (m MocksCloudRemote) request(method string, path string, response interface
 	return nil
 }
 
-func (m MocksCloudRemote) upload(path string, src string) error {
+// upload sends the file at src to the path, named by its path
+// relative to the configuration directory.
+func (m MocksCloudRemote) upload(path string, src string, name string) error {
 	file, err := os.Open(src)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	fi, err := file.Stat()
-	if err != nil {
-		return err
-	}
 	fileContents, err := io.ReadAll(file)
 	if err != nil {
 		return err
@@ -225,7 +223,7 @@ func (m MocksCloudRemote) upload(path string, src string) error {
 
 	body := new(bytes.Buffer)
 	writer := multipart.NewWriter(body)
-	part, err := writer.CreateFormFile("file", fi.Name())
+	part, err := writer.CreateFormFile("file", name)
 	if err != nil {
 		return err
 	}
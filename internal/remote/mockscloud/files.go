@@ -2,8 +2,8 @@ package mockscloud
 
 import (
 	"fmt"
-	"github.com/imposter-project/imposter-cli/internal/fileutil"
-	"strings"
+	"github.com/imposter-project/imposter-cli/internal/config"
+	"path/filepath"
 )
 
 func (m MocksCloudRemote) syncFiles(dir string) error {
@@ -14,17 +14,25 @@ func (m MocksCloudRemote) syncFiles(dir string) error {
 		return err
 	}
 
-	local, err := fileutil.ListFiles(dir, false)
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	local, err := config.ListConfigFiles(absDir)
+	if err != nil {
+		return err
+	}
+	names, err := relativeNames(absDir, local)
 	if err != nil {
 		return err
 	}
 
-	err = m.uploadFiles(local)
+	err = m.uploadFiles(local, names)
 	if err != nil {
 		return err
 	}
 
-	delta := m.calculateDelta(dir, r, local)
+	delta := m.calculateDelta(r, names)
 	err = m.deleteRemote(delta)
 	if err != nil {
 		return err
@@ -42,11 +50,25 @@ func (m MocksCloudRemote) listRemote() ([]string, error) {
 	return resp, nil
 }
 
-// calculateDelta determines the remote files that are not present in dir
-func (m MocksCloudRemote) calculateDelta(dir string, remote []string, local []string) []string {
+// relativeNames returns the path of each file relative to dir, using
+// forward slashes, such as paths/pets.yaml for a spec fragment.
+func relativeNames(dir string, files []string) ([]string, error) {
+	var names []string
+	for _, f := range files {
+		relPath, err := filepath.Rel(dir, f)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, filepath.ToSlash(relPath))
+	}
+	return names, nil
+}
+
+// calculateDelta determines the remote files that are not present locally
+func (m MocksCloudRemote) calculateDelta(remote []string, local []string) []string {
 	var delta []string
 	for _, r := range remote {
-		if !arrayContains(local, dir+"/", r) {
+		if !arrayContains(local, r) {
 			delta = append(delta, r)
 		}
 	}
@@ -54,22 +76,19 @@ func (m MocksCloudRemote) calculateDelta(dir string, remote []string, local []st
 	return delta
 }
 
-func arrayContains(search []string, trimPrefix string, term string) bool {
-	found := false
+func arrayContains(search []string, term string) bool {
 	for _, s := range search {
-		trimmed := strings.TrimPrefix(s, trimPrefix)
-		if trimmed == term {
-			found = true
-			break
+		if s == term {
+			return true
 		}
 	}
-	return found
+	return false
 }
 
-func (m MocksCloudRemote) uploadFiles(files []string) error {
-	for _, f := range files {
-		logger.Infof("uploading: %s", f)
-		err := m.upload(fmt.Sprintf("/api/mocks/%s/spec", m.Config[configKeyMockId]), f)
+func (m MocksCloudRemote) uploadFiles(files []string, names []string) error {
+	for i, f := range files {
+		logger.Infof("uploading: %s", names[i])
+		err := m.upload(fmt.Sprintf("/api/mocks/%s/spec", m.Config[configKeyMockId]), f, names[i])
 		if err != nil {
 			return fmt.Errorf("failed to upload file: %s: %s", f, err)
 		}
@@ -0,0 +1,23 @@
+package mockscloud
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_calculateDelta(t *testing.T) {
+	dir := t.TempDir()
+	local := []string{
+		filepath.Join(dir, "openapi.yaml"),
+		filepath.Join(dir, "paths", "pets.yaml"),
+	}
+	names, err := relativeNames(dir, local)
+	require.NoError(t, err)
+	require.Equal(t, []string{"openapi.yaml", "paths/pets.yaml"}, names)
+
+	remote := []string{"openapi.yaml", "paths/pets.yaml", "paths/orders.yaml", "pets.yaml"}
+	delta := MocksCloudRemote{}.calculateDelta(remote, names)
+	require.Equal(t, []string{"paths/orders.yaml", "pets.yaml"}, delta)
+}
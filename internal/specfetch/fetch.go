@@ -0,0 +1,270 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package specfetch
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/imposter-project/imposter-cli/internal/fileutil"
+	"github.com/imposter-project/imposter-cli/internal/logging"
+)
+
+var logger = logging.GetLogger()
+
+type documentKind int
+
+const (
+	kindOpenApi documentKind = iota
+	kindWsdl
+	kindProto
+)
+
+// document is a file fetched from a URL.
+type document struct {
+	url  *url.URL
+	kind documentKind
+	body []byte
+
+	// refs are the references to other documents, as they
+	// appear in the document, keyed by the URL they resolve to.
+	refs map[string][]string
+}
+
+// Fetch downloads the OpenAPI, WSDL or protobuf document at the HTTP(S) or
+// file URL into the directory, along with the documents it references,
+// such as $ref targets, XSD includes or proto imports.
+//
+// The headers are sent with requests to the host of the URL, such as to
+// authenticate with a registry. Referenced documents are written relative
+// to the root document, which is written to the directory itself. If a
+// reference is outside the directory of the root document, the references
+// to it are rewritten to its new location. References are only followed
+// if they use the same scheme as the URL, so a remote document cannot
+// cause local files to be read.
+//
+// Returns the path of the root document.
+func Fetch(rawUrl string, destDir string, headers http.Header, forceOverwrite bool) (string, error) {
+	rootUrl, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %s: %v", rawUrl, err)
+	}
+	if rootUrl.Scheme != "http" && rootUrl.Scheme != "https" && rootUrl.Scheme != "file" {
+		return "", fmt.Errorf("unsupported URL scheme: %s", rawUrl)
+	}
+	f := fetcher{
+		host:      rootUrl.Host,
+		headers:   headers,
+		documents: make(map[string]*document),
+	}
+	root, err := f.fetchAll(rootUrl)
+	if err != nil {
+		return "", err
+	}
+	return f.write(root, destDir, forceOverwrite)
+}
+
+type fetcher struct {
+	host    string
+	headers http.Header
+
+	// documents holds each fetched document, keyed by URL.
+	documents map[string]*document
+
+	// order lists the URLs of the documents in the order they were fetched.
+	order []string
+}
+
+// fetchAll fetches the document at the URL and the documents it references.
+func (f *fetcher) fetchAll(rootUrl *url.URL) (*document, error) {
+	pending := []*url.URL{rootUrl}
+	for len(pending) > 0 {
+		docUrl := pending[0]
+		pending = pending[1:]
+		if _, fetched := f.documents[docUrl.String()]; fetched {
+			continue
+		}
+		body, err := f.get(docUrl)
+		if err != nil {
+			return nil, err
+		}
+		doc := &document{url: docUrl, kind: detectKind(docUrl, body), body: body}
+		if doc.refs, err = findRefs(doc, rootUrl); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", docUrl, err)
+		}
+		f.documents[docUrl.String()] = doc
+		f.order = append(f.order, docUrl.String())
+		for refUrl := range doc.refs {
+			resolved, _ := url.Parse(refUrl)
+			if resolved.Scheme != rootUrl.Scheme {
+				return nil, fmt.Errorf("reference to %s in %s does not use the scheme of %s", resolved, docUrl, rootUrl)
+			}
+			pending = append(pending, resolved)
+		}
+	}
+	return f.documents[rootUrl.String()], nil
+}
+
+func (f *fetcher) get(docUrl *url.URL) ([]byte, error) {
+	logger.Debugf("fetching %v", docUrl)
+	if docUrl.Scheme == "file" {
+		body, err := os.ReadFile(filepath.FromSlash(docUrl.Path))
+		if err != nil {
+			return nil, fmt.Errorf("error reading: %v: %v", docUrl, err)
+		}
+		return body, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, docUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	// credentials for the registry should not be sent to other hosts
+	if docUrl.Host == f.host {
+		for name, values := range f.headers {
+			req.Header[name] = values
+		}
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching from: %v: %v", docUrl, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("error fetching from: %v - HTTP status: %d", docUrl, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// detectKind determines the kind of the document from the extension of
+// its URL, or its content if the extension is not recognised, such as
+// for a registry URL.
+func detectKind(docUrl *url.URL, body []byte) documentKind {
+	switch path.Ext(docUrl.Path) {
+	case ".wsdl", ".xsd", ".xml":
+		return kindWsdl
+	case ".proto":
+		return kindProto
+	case ".yaml", ".yml", ".json":
+		return kindOpenApi
+	}
+	trimmed := bytes.TrimSpace(body)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return kindWsdl
+	case protoSyntax.Match(trimmed):
+		return kindProto
+	default:
+		return kindOpenApi
+	}
+}
+
+// write writes the documents to the directory, returning the path of the root.
+func (f *fetcher) write(root *document, destDir string, forceOverwrite bool) (string, error) {
+	rootDir := path.Dir(root.url.Path)
+
+	// local paths are relative to the directory of the root document
+	localPaths := map[string]string{root.url.String(): rootFileName(root)}
+	written := map[string]string{rootFileName(root): root.url.String()}
+	for _, docUrl := range f.order {
+		doc := f.documents[docUrl]
+		if doc == root {
+			continue
+		}
+		relPath, err := localPath(rootDir, doc.url)
+		if err != nil {
+			return "", err
+		}
+		if other, taken := written[relPath]; taken {
+			return "", fmt.Errorf("unable to write %s and %s to the same local path: %s", other, docUrl, relPath)
+		}
+		localPaths[docUrl] = relPath
+		written[relPath] = docUrl
+	}
+
+	for _, docUrl := range f.order {
+		doc := f.documents[docUrl]
+		file := filepath.Join(destDir, filepath.FromSlash(localPaths[docUrl]))
+		if err := writeFile(file, rewriteRefs(doc, localPaths), forceOverwrite); err != nil {
+			return "", err
+		}
+	}
+	logger.Infof("fetched %s and %d referenced file(s) to %s", root.url, len(f.order)-1, destDir)
+	return filepath.Join(destDir, localPaths[root.url.String()]), nil
+}
+
+// rootFileName returns the name of the root document, adding an extension
+// for its kind if its URL does not have one, such as for a registry URL.
+func rootFileName(root *document) string {
+	name := path.Base(root.url.Path)
+	if name == "/" || name == "." {
+		name = "spec"
+	}
+	switch path.Ext(name) {
+	case ".yaml", ".yml", ".json", ".wsdl", ".proto":
+		return name
+	}
+	switch root.kind {
+	case kindWsdl:
+		return name + ".wsdl"
+	case kindProto:
+		return name + ".proto"
+	default:
+		if bytes.HasPrefix(bytes.TrimSpace(root.body), []byte("{")) {
+			return name + ".json"
+		}
+		return name + ".yaml"
+	}
+}
+
+// localPath returns the path at which a referenced document is written,
+// relative to the root document. A document outside the directory of the
+// root document is written to a subdirectory named after its own directory.
+func localPath(rootDir string, docUrl *url.URL) (string, error) {
+	relPath := strings.TrimPrefix(docUrl.Path, rootDir+"/")
+	if relPath == docUrl.Path || strings.HasPrefix(relPath, "../") {
+		relPath = path.Join(path.Base(path.Dir(docUrl.Path)), path.Base(docUrl.Path))
+	}
+	if relPath == "" || strings.HasPrefix(relPath, "/") || strings.Contains(relPath, "..") {
+		return "", fmt.Errorf("unable to determine local path for: %s", docUrl)
+	}
+	return relPath, nil
+}
+
+func writeFile(file string, body []byte, forceOverwrite bool) error {
+	fileutil.MustNotExist(file, forceOverwrite)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, body, 0644); err != nil {
+		return fmt.Errorf("failed to write: %s: %v", file, err)
+	}
+	logger.Debugf("wrote %s", file)
+	return nil
+}
+
+func stripFragment(ref string) string {
+	before, _, _ := strings.Cut(ref, "#")
+	return before
+}
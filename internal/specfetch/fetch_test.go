@@ -0,0 +1,136 @@
+package specfetch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const registrySpec = `openapi: "3.0.1"
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: pets
+          content:
+            application/json:
+              schema:
+                $ref: "schemas/pet.yaml#/Pets"
+        "500":
+          description: error, as defined in ../common/error.yaml
+          content:
+            application/json:
+              schema:
+                $ref: "../common/error.yaml"
+`
+
+func TestFetch_openapi(t *testing.T) {
+	files := map[string]string{
+		"/apis/pets/v1":               registrySpec,
+		"/apis/pets/schemas/pet.yaml": "Pets:\n  type: array\n  items:\n    $ref: \"#/Pet\"\nPet:\n  $ref: \"tag.yaml\"\n",
+		"/apis/pets/schemas/tag.yaml": "type: object\nproperties:\n  error:\n    $ref: '../../common/error.yaml'\n",
+		"/apis/common/error.yaml":     "type: object\n",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	headers := http.Header{"Authorization": []string{"Bearer secret"}}
+	rootFile, err := Fetch(server.URL+"/apis/pets/v1", dir, headers, false)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "v1.yaml"), rootFile, "an extension should be added for the kind of document")
+
+	root, err := os.ReadFile(rootFile)
+	require.NoError(t, err)
+	require.Contains(t, string(root), `$ref: "schemas/pet.yaml#/Pets"`)
+	require.Contains(t, string(root), `$ref: "common/error.yaml"`, "references outside the directory should be rewritten")
+	require.Contains(t, string(root), "as defined in ../common/error.yaml", "only reference values should be rewritten")
+
+	tag, err := os.ReadFile(filepath.Join(dir, "schemas", "tag.yaml"))
+	require.NoError(t, err)
+	require.Contains(t, string(tag), "$ref: '../common/error.yaml'", "references in referenced documents should be rewritten relative to them")
+
+	for _, file := range []string{"schemas/pet.yaml", "schemas/tag.yaml", "common/error.yaml"} {
+		require.FileExists(t, filepath.Join(dir, file))
+	}
+
+	_, err = Fetch(server.URL+"/apis/pets/v1", t.TempDir(), nil, false)
+	require.ErrorContains(t, err, "HTTP status: 401")
+}
+
+func TestFetch_remoteToLocalReference(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("openapi: \"3.0.1\"\npaths:\n  /secret:\n    $ref: \"file:///etc/passwd\"\n"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	_, err := Fetch(server.URL+"/spec.yaml", dir, nil, false)
+	require.ErrorContains(t, err, "does not use the scheme of")
+	entries, _ := os.ReadDir(dir)
+	require.Empty(t, entries, "nothing should be written")
+}
+
+func TestFetch_wsdl(t *testing.T) {
+	srcDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(srcDir, "xsd"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "service.wsdl"), []byte(`<?xml version="1.0"?>
+<definitions xmlns="http://schemas.xmlsoap.org/wsdl/" xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <types>
+    <xs:schema>
+      <xs:import namespace="urn:pets" schemaLocation="xsd/pets.xsd"/>
+    </xs:schema>
+  </types>
+</definitions>`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "xsd", "pets.xsd"), []byte(`<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:include schemaLocation="common.xsd"/>
+</xs:schema>`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "xsd", "common.xsd"), []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"/>`), 0644))
+
+	dir := t.TempDir()
+	rootFile, err := Fetch("file://"+filepath.ToSlash(filepath.Join(srcDir, "service.wsdl")), dir, nil, false)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "service.wsdl"), rootFile)
+	require.FileExists(t, filepath.Join(dir, "xsd", "pets.xsd"))
+	require.FileExists(t, filepath.Join(dir, "xsd", "common.xsd"))
+}
+
+func TestFetch_proto(t *testing.T) {
+	srcDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(srcDir, "common"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "pets.proto"), []byte(`syntax = "proto3";
+import "google/protobuf/empty.proto";
+import "common/types.proto";
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "common", "types.proto"), []byte(`syntax = "proto3";
+import public "common/ids.proto";
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "common", "ids.proto"), []byte(`syntax = "proto3";`), 0644))
+
+	dir := t.TempDir()
+	_, err := Fetch("file://"+filepath.ToSlash(filepath.Join(srcDir, "pets.proto")), dir, nil, false)
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(dir, "pets.proto"))
+	require.FileExists(t, filepath.Join(dir, "common", "types.proto"))
+	require.FileExists(t, filepath.Join(dir, "common", "ids.proto"), "imports should be resolved against the import path")
+}
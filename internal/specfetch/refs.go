@@ -0,0 +1,183 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package specfetch
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+var protoSyntax = regexp.MustCompile(`(?m)^\s*syntax\s*=\s*["']proto[23]["']`)
+
+var protoImport = regexp.MustCompile(`(?m)^\s*import\s+(?:public\s+|weak\s+)?["']([^"']+)["']\s*;`)
+
+// findRefs returns the references in the document to other documents,
+// keyed by the URL they resolve to. Imports in proto files are resolved
+// against the directory of the root document, which is the import path.
+func findRefs(doc *document, rootUrl *url.URL) (map[string][]string, error) {
+	var refs []string
+	base := doc.url
+	switch doc.kind {
+	case kindOpenApi:
+		var parsed any
+		if err := yaml.Unmarshal(doc.body, &parsed); err != nil {
+			return nil, err
+		}
+		refs = findJsonRefs(parsed, refs)
+	case kindWsdl:
+		var err error
+		if refs, err = findXmlRefs(doc.body); err != nil {
+			return nil, err
+		}
+	case kindProto:
+		base = rootUrl
+		for _, match := range protoImport.FindAllSubmatch(doc.body, -1) {
+			// well-known types are provided by the parser
+			if imported := string(match[1]); !strings.HasPrefix(imported, "google/protobuf/") {
+				refs = append(refs, imported)
+			}
+		}
+	}
+
+	resolved := make(map[string][]string)
+	for _, ref := range refs {
+		refUrl, err := url.Parse(stripFragment(ref))
+		if err != nil {
+			logger.Warnf("ignoring invalid reference %s in %s: %v", ref, doc.url, err)
+			continue
+		}
+		key := base.ResolveReference(refUrl).String()
+		resolved[key] = append(resolved[key], ref)
+	}
+	return resolved, nil
+}
+
+// findJsonRefs appends the $ref values that refer to other documents.
+func findJsonRefs(value any, refs []string) []string {
+	switch v := value.(type) {
+	case map[string]any:
+		if ref, ok := v["$ref"].(string); ok && stripFragment(ref) != "" {
+			refs = append(refs, ref)
+		}
+		for _, member := range v {
+			refs = findJsonRefs(member, refs)
+		}
+	case []any:
+		for _, item := range v {
+			refs = findJsonRefs(item, refs)
+		}
+	}
+	return refs
+}
+
+// findXmlRefs returns the locations of WSDL imports and
+// XSD imports, includes and redefines.
+func findXmlRefs(body []byte) ([]string, error) {
+	var refs []string
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return refs, nil
+		} else if err != nil {
+			return nil, err
+		}
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch element.Name.Local {
+		case "import", "include", "redefine":
+			for _, attr := range element.Attr {
+				if (attr.Name.Local == "location" || attr.Name.Local == "schemaLocation") && attr.Value != "" {
+					refs = append(refs, attr.Value)
+				}
+			}
+		}
+	}
+}
+
+// rewriteRefs returns the body of the document with each reference to
+// another document rewritten to the local path of that document, if the
+// reference, as written, would not resolve to it. Only the values of
+// $ref members, location and schemaLocation attributes and proto imports
+// are rewritten, so the same text elsewhere is left unchanged.
+func rewriteRefs(doc *document, localPaths map[string]string) []byte {
+	body := doc.body
+	docDir := path.Dir(localPaths[doc.url.String()])
+	refUrls := make([]string, 0, len(doc.refs))
+	for refUrl := range doc.refs {
+		refUrls = append(refUrls, refUrl)
+	}
+	sort.Strings(refUrls)
+
+	for _, refUrl := range refUrls {
+		target := localPaths[refUrl]
+
+		// proto imports are resolved against the import path, which is
+		// the directory of the root document, rather than the importer
+		newRef := target
+		if doc.kind != kindProto {
+			newRef = relativePath(docDir, target)
+		}
+		rewritten := make(map[string]bool)
+		for _, ref := range doc.refs[refUrl] {
+			refPath := stripFragment(ref)
+			if rewritten[refPath] || path.Clean(refPath) == newRef {
+				continue
+			}
+			rewritten[refPath] = true
+			logger.Debugf("rewriting reference %s to %s in %s", refPath, newRef, doc.url)
+			body = refPattern(doc.kind, refPath).ReplaceAll(body, []byte("${1}"+strings.ReplaceAll(newRef, "$", "$$")+"${2}"))
+		}
+	}
+	return body
+}
+
+// refPattern matches the reference where it is used as a reference
+// by a document of the kind, capturing the text either side of it.
+func refPattern(kind documentKind, ref string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(ref)
+	switch kind {
+	case kindWsdl:
+		return regexp.MustCompile(`(\b(?:location|schemaLocation)\s*=\s*["'])` + quoted + `(["'#])`)
+	case kindProto:
+		return regexp.MustCompile(`(?m)(^\s*import\s+(?:public\s+|weak\s+)?["'])` + quoted + `(["'])`)
+	default:
+		return regexp.MustCompile(`(?m)(["']?\$ref["']?\s*:\s*["']?)` + quoted + `(["'#,}\s]|$)`)
+	}
+}
+
+// relativePath returns the slash-separated path of target relative to dir,
+// where both are relative to the same directory.
+func relativePath(dir string, target string) string {
+	rel, err := filepath.Rel(filepath.FromSlash(dir), filepath.FromSlash(target))
+	if err != nil {
+		return target
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,430 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wiremock
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/imposter-project/imposter-cli/internal/fileutil"
+	"github.com/imposter-project/imposter-cli/internal/impostermodel"
+	"github.com/imposter-project/imposter-cli/internal/logging"
+	"github.com/imposter-project/imposter-cli/internal/stringutil"
+)
+
+var logger = logging.GetLogger()
+
+// filesDir is the directory of body files, in both the WireMock root
+// directory and the output directory.
+const filesDir = "__files"
+
+// ignoredMappingMembers are members of a mapping that do not affect
+// how requests are matched or responded to.
+var ignoredMappingMembers = []string{"id", "uuid", "name", "priority", "persistent", "metadata", "request", "response"}
+
+var scenarioMembers = []string{"scenarioName", "requiredScenarioState", "newScenarioState"}
+
+var translatedRequestMembers = []string{"method", "url", "urlPath", "urlPattern", "urlPathPattern", "urlPathTemplate", "headers", "queryParameters", "pathParameters", "bodyPatterns"}
+
+var translatedResponseMembers = []string{"status", "headers", "body", "jsonBody", "base64Body", "bodyFileName"}
+
+// bodyOperators maps WireMock body matchers to Imposter request body operators.
+var bodyOperators = map[string]string{
+	"equalTo":        "EqualTo",
+	"equalToJson":    "EqualTo",
+	"equalToXml":     "EqualTo",
+	"contains":       "Contains",
+	"doesNotContain": "NotContains",
+	"matches":        "Matches",
+	"doesNotMatch":   "NotMatches",
+}
+
+// regexSpecialChars are the characters that make a path segment of
+// a URL pattern a regular expression, rather than a literal.
+var regexSpecialChars = regexp.MustCompile(`[\\.*+?()\[\]{}|^$]`)
+
+type converter struct {
+	rootDir        string
+	outputDir      string
+	forceOverwrite bool
+	issues         impostermodel.ImportIssues
+	copiedFiles    map[string]bool
+	bodyCount      int
+}
+
+// Convert translates the mappings to resources for the Imposter REST plugin.
+// Body files are copied from the __files directory of the WireMock root
+// directory to the __files directory of the output directory, to which
+// the resources refer. Mappings that proxy or return faults are skipped.
+//
+// Returns the resources, along with the issues found translating them.
+func Convert(mappings []Mapping, rootDir string, outputDir string, forceOverwrite bool) ([]impostermodel.Resource, []impostermodel.ImportIssue, error) {
+	c := &converter{
+		rootDir:        rootDir,
+		outputDir:      outputDir,
+		forceOverwrite: forceOverwrite,
+		copiedFiles:    make(map[string]bool),
+	}
+	var resources []impostermodel.Resource
+	for _, mapping := range mappings {
+		resource, err := c.convert(mapping)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to convert WireMock mapping %s: %v", mapping.Source, err)
+		}
+		if resource != nil {
+			resources = append(resources, *resource)
+		}
+	}
+	return resources, c.issues, nil
+}
+
+func (c *converter) convert(mapping Mapping) (*impostermodel.Resource, error) {
+	if mapping.Response.ProxyBaseURL != "" {
+		c.issues.Report(mapping.Source, "proxying to %s is not supported - skipped mapping", mapping.Response.ProxyBaseURL)
+		return nil, nil
+	}
+	if mapping.Response.Fault != "" {
+		c.issues.Report(mapping.Source, "fault %s is not supported - skipped mapping", mapping.Response.Fault)
+		return nil, nil
+	}
+	c.reportUnsupported(mapping)
+
+	resource := &impostermodel.Resource{
+		Method:   c.method(mapping),
+		Response: &impostermodel.ResponseConfig{},
+	}
+	c.convertUrl(mapping, resource)
+	resource.RequestHeaders = c.convertMatchers(mapping, "header", mapping.Request.Headers)
+	if queryParams := c.convertMatchers(mapping, "query parameter", mapping.Request.QueryParameters); queryParams != nil {
+		if resource.QueryParams == nil {
+			resource.QueryParams = queryParams
+		} else {
+			for name, value := range *queryParams {
+				(*resource.QueryParams)[name] = value
+			}
+		}
+	}
+	resource.PathParams = c.convertMatchers(mapping, "path parameter", mapping.Request.PathParameters)
+	resource.RequestBody = c.convertBodyPatterns(mapping)
+
+	if err := c.convertResponse(mapping, resource.Response); err != nil {
+		return nil, err
+	}
+	return resource, nil
+}
+
+// reportUnsupported reports the members of the mapping that are not translated.
+func (c *converter) reportUnsupported(mapping Mapping) {
+	for _, name := range scenarioMembers {
+		if _, ok := mapping.raw.mapping[name]; ok {
+			c.issues.Report(mapping.Source, "scenarios are not supported - ignored scenario state")
+			break
+		}
+	}
+	for _, name := range unknownMembers(mapping.raw.mapping, ignoredMappingMembers, scenarioMembers) {
+		c.issues.Report(mapping.Source, "mapping property '%s' is not supported", name)
+	}
+	for _, name := range unknownMembers(mapping.raw.request, translatedRequestMembers) {
+		c.issues.Report(mapping.Source, "request property '%s' is not supported", name)
+	}
+	for _, name := range unknownMembers(mapping.raw.response, translatedResponseMembers) {
+		c.issues.Report(mapping.Source, "response property '%s' is not supported", name)
+	}
+}
+
+func unknownMembers(members map[string]json.RawMessage, known ...[]string) []string {
+	var unknown []string
+	for _, name := range slices.Sorted(maps.Keys(members)) {
+		isKnown := false
+		for _, names := range known {
+			isKnown = isKnown || stringutil.Contains(names, name)
+		}
+		if !isKnown {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
+
+// method returns the method of the mapping, or an empty string if it
+// matches any method, as a resource without a method matches any method.
+func (c *converter) method(mapping Mapping) string {
+	method := strings.ToUpper(mapping.Request.Method)
+	if method == "ANY" {
+		return ""
+	}
+	return method
+}
+
+// convertUrl sets the path of the resource, and query parameters for a
+// url matcher, which includes the query string.
+func (c *converter) convertUrl(mapping Mapping, resource *impostermodel.Resource) {
+	req := mapping.Request
+	switch {
+	case req.URL != "":
+		parsed, err := url.Parse(req.URL)
+		if err != nil {
+			c.issues.Report(mapping.Source, "invalid url %s - matching any path", req.URL)
+			return
+		}
+		resource.Path = parsed.Path
+		if query := parsed.Query(); len(query) > 0 {
+			queryParams := make(map[string]string)
+			for name, values := range query {
+				queryParams[name] = values[0]
+				if len(values) > 1 {
+					c.issues.Report(mapping.Source, "query parameter %s has several values - matching the first", name)
+				}
+			}
+			resource.QueryParams = &queryParams
+		}
+	case req.URLPath != "":
+		resource.Path = req.URLPath
+	case req.URLPathTemplate != "":
+		resource.Path = req.URLPathTemplate
+	case req.URLPattern != "" || req.URLPathPattern != "":
+		pattern := req.URLPattern + req.URLPathPattern
+		resource.Path = patternToPath(pattern)
+		c.issues.Report(mapping.Source, "URL pattern %s is not supported - matching path %s", pattern, resource.Path)
+	default:
+		c.issues.Report(mapping.Source, "no URL matcher - matching any path")
+	}
+}
+
+// patternToPath converts a URL pattern to a path template, in which each
+// path segment that is not a literal is matched by a path parameter.
+// Any query string in the pattern is dropped.
+func patternToPath(pattern string) string {
+	pattern = strings.TrimSuffix(strings.TrimPrefix(pattern, "^"), "$")
+	if queryStart := strings.Index(pattern, `\?`); queryStart >= 0 {
+		pattern = pattern[:queryStart]
+	}
+	segments := strings.Split(pattern, "/")
+	params := 0
+	for i, segment := range segments {
+		unescaped := strings.NewReplacer(`\.`, "", `\-`, "").Replace(segment)
+		if regexSpecialChars.MatchString(unescaped) {
+			params++
+			segments[i] = fmt.Sprintf("{param%d}", params)
+		} else {
+			segments[i] = strings.NewReplacer(`\.`, ".", `\-`, "-").Replace(segment)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// convertMatchers converts equalTo matchers to values to match exactly.
+func (c *converter) convertMatchers(mapping Mapping, kind string, matchers map[string]Matcher) *map[string]string {
+	if len(matchers) == 0 {
+		return nil
+	}
+	values := make(map[string]string)
+	for _, name := range slices.Sorted(maps.Keys(matchers)) {
+		matcher := matchers[name]
+		var value string
+		if operand, ok := matcher["equalTo"]; !ok || json.Unmarshal(operand, &value) != nil {
+			c.issues.Report(mapping.Source, "%s %s matcher %s is not supported - ignored", kind, name, describeMatcher(matcher))
+			continue
+		}
+		if string(matcher["caseInsensitive"]) == "true" {
+			c.issues.Report(mapping.Source, "%s %s is matched case sensitively", kind, name)
+		}
+		values[name] = value
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return &values
+}
+
+// convertBodyPatterns converts the first body pattern that has an
+// equivalent request body operator.
+func (c *converter) convertBodyPatterns(mapping Mapping) *impostermodel.RequestBody {
+	var requestBody *impostermodel.RequestBody
+	for _, pattern := range mapping.Request.BodyPatterns {
+		operator, value, ok := bodyMatcher(pattern)
+		if !ok {
+			c.issues.Report(mapping.Source, "body matcher %s is not supported - ignored", describeMatcher(pattern))
+			continue
+		}
+		if requestBody != nil {
+			c.issues.Report(mapping.Source, "only one body matcher is supported - ignored %s", describeMatcher(pattern))
+			continue
+		}
+		if _, isJson := pattern["equalToJson"]; isJson {
+			c.issues.Report(mapping.Source, "JSON body is matched as text")
+		} else if _, isXml := pattern["equalToXml"]; isXml {
+			c.issues.Report(mapping.Source, "XML body is matched as text")
+		}
+		requestBody = &impostermodel.RequestBody{Operator: operator, Value: value}
+	}
+	return requestBody
+}
+
+func bodyMatcher(matcher Matcher) (string, string, bool) {
+	for wiremockOperator, operator := range bodyOperators {
+		operand, ok := matcher[wiremockOperator]
+		if !ok {
+			continue
+		}
+		var value string
+		if err := json.Unmarshal(operand, &value); err == nil {
+			return operator, value, true
+		}
+		// JSON matchers may hold the JSON itself, rather than a string
+		var compact bytes.Buffer
+		if wiremockOperator == "equalToJson" && json.Compact(&compact, operand) == nil {
+			return operator, compact.String(), true
+		}
+		return "", "", false
+	}
+	return "", "", false
+}
+
+func (c *converter) convertResponse(mapping Mapping, response *impostermodel.ResponseConfig) error {
+	wmResponse := mapping.Response
+	response.StatusCode = wmResponse.Status
+	if response.StatusCode == 0 {
+		response.StatusCode = 200
+	}
+	if len(wmResponse.Headers) > 0 {
+		headers := make(map[string]string)
+		for _, name := range slices.Sorted(maps.Keys(wmResponse.Headers)) {
+			var value string
+			var values []string
+			if err := json.Unmarshal(wmResponse.Headers[name], &value); err == nil {
+				headers[name] = value
+			} else if err := json.Unmarshal(wmResponse.Headers[name], &values); err == nil {
+				headers[name] = strings.Join(values, ", ")
+			} else {
+				c.issues.Report(mapping.Source, "response header %s has an invalid value - ignored", name)
+			}
+		}
+		response.Headers = &headers
+	}
+	for _, transformer := range wmResponse.Transformers {
+		if transformer == "response-template" {
+			c.issues.Report(mapping.Source, "response templates are not translated - the body is returned as is")
+		}
+	}
+
+	switch {
+	case wmResponse.Body != nil:
+		response.Content = *wmResponse.Body
+	case len(wmResponse.JSONBody) > 0:
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, wmResponse.JSONBody); err != nil {
+			return fmt.Errorf("invalid JSON body: %v", err)
+		}
+		response.Content = compact.String()
+	case wmResponse.Base64Body != "":
+		body, err := base64.StdEncoding.DecodeString(wmResponse.Base64Body)
+		if err != nil {
+			return fmt.Errorf("invalid base64 body: %v", err)
+		}
+		file, err := c.writeBodyFile(body, headerValue(response.Headers, "Content-Type"))
+		if err != nil {
+			return err
+		}
+		response.File = file
+	case wmResponse.BodyFileName != "":
+		file, err := c.copyBodyFile(mapping, wmResponse.BodyFileName)
+		if err != nil {
+			return err
+		}
+		response.File = file
+	}
+	return nil
+}
+
+// copyBodyFile copies the body file into the output directory, unless
+// it has already been copied, returning the path to which it was copied,
+// relative to the output directory. Returns an empty path if the file
+// does not exist.
+func (c *converter) copyBodyFile(mapping Mapping, bodyFileName string) (string, error) {
+	relFile := path.Join(filesDir, path.Clean("/" + filepath.ToSlash(bodyFileName))[1:])
+	if c.copiedFiles[relFile] {
+		return relFile, nil
+	}
+	src := filepath.Join(c.rootDir, filepath.FromSlash(relFile))
+	if _, err := os.Stat(src); err != nil {
+		c.issues.Report(mapping.Source, "body file %s not found - ignored", bodyFileName)
+		return "", nil
+	}
+	dest := filepath.Join(c.outputDir, filepath.FromSlash(relFile))
+	srcAbs, _ := filepath.Abs(src)
+	destAbs, _ := filepath.Abs(dest)
+	if srcAbs != destAbs {
+		fileutil.MustNotExist(dest, c.forceOverwrite)
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return "", err
+		}
+		if err := fileutil.CopyFile(src, dest); err != nil {
+			return "", fmt.Errorf("failed to copy body file %s: %v", bodyFileName, err)
+		}
+		logger.Debugf("copied body file %s to %s", src, dest)
+	}
+	c.copiedFiles[relFile] = true
+	return relFile, nil
+}
+
+// writeBodyFile writes an inline binary body to a file in the output directory.
+func (c *converter) writeBodyFile(body []byte, contentType string) (string, error) {
+	c.bodyCount++
+	extension := fileutil.ExtensionForMediaType(contentType)
+	if extension == "" {
+		extension = ".bin"
+	}
+	relFile := fmt.Sprintf("%s/body-%d%s", filesDir, c.bodyCount, extension)
+	dest := filepath.Join(c.outputDir, filepath.FromSlash(relFile))
+	fileutil.MustNotExist(dest, c.forceOverwrite)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(dest, body, 0644); err != nil {
+		return "", fmt.Errorf("failed to write body file %s: %v", dest, err)
+	}
+	return relFile, nil
+}
+
+func headerValue(headers *map[string]string, name string) string {
+	if headers == nil {
+		return ""
+	}
+	for key, value := range *headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
+// describeMatcher returns the JSON form of the matcher, for reporting.
+func describeMatcher(matcher Matcher) string {
+	described, _ := json.Marshal(matcher)
+	return string(described)
+}
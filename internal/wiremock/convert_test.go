@@ -0,0 +1,61 @@
+package wiremock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_patternToPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{pattern: "/users", want: "/users"},
+		{pattern: `^/files/report\.pdf$`, want: "/files/report.pdf"},
+		{pattern: "/users/[0-9]+/orders/.*", want: "/users/{param1}/orders/{param2}"},
+		{pattern: `/search\?q=.*`, want: "/search"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			require.Equal(t, tt.want, patternToPath(tt.pattern))
+		})
+	}
+}
+
+func TestConvert_matchers(t *testing.T) {
+	mapping, err := parseMapping([]byte(`{
+  "request": {
+    "urlPathTemplate": "/users/{id}",
+    "pathParameters": {"id": {"equalTo": "1"}},
+    "headers": {"X-Tenant": {"equalTo": "acme", "caseInsensitive": true}},
+    "bodyPatterns": [{"matchesJsonPath": "$.name"}, {"contains": "alice"}, {"contains": "bob"}],
+    "cookies": {"session": {"equalTo": "abc"}}
+  },
+  "response": {"status": 204}
+}`), "mappings/user.json")
+	require.NoError(t, err)
+
+	resources, issues, err := Convert([]Mapping{*mapping}, t.TempDir(), t.TempDir(), false)
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	resource := resources[0]
+	require.Empty(t, resource.Method, "a mapping without a method should match any method")
+	require.Equal(t, "/users/{id}", resource.Path)
+	require.Equal(t, map[string]string{"id": "1"}, *resource.PathParams)
+	require.Equal(t, map[string]string{"X-Tenant": "acme"}, *resource.RequestHeaders)
+	require.Equal(t, "Contains", resource.RequestBody.Operator)
+	require.Equal(t, "alice", resource.RequestBody.Value)
+	require.Equal(t, 204, resource.Response.StatusCode)
+
+	var messages []string
+	for _, issue := range issues {
+		messages = append(messages, issue.String())
+	}
+	require.Equal(t, []string{
+		"mappings/user.json: request property 'cookies' is not supported",
+		"mappings/user.json: header X-Tenant is matched case sensitively",
+		`mappings/user.json: body matcher {"matchesJsonPath":"$.name"} is not supported - ignored`,
+		`mappings/user.json: only one body matcher is supported - ignored {"contains":"bob"}`,
+	}, messages)
+}
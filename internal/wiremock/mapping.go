@@ -0,0 +1,182 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package wiremock reads WireMock stub mappings and converts them to
+// resources for the Imposter REST plugin.
+// See https://wiremock.org/docs/stubbing/
+package wiremock
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Mapping is a WireMock stub mapping.
+type Mapping struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Priority *int     `json:"priority"`
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+
+	ScenarioName          string `json:"scenarioName"`
+	RequiredScenarioState string `json:"requiredScenarioState"`
+	NewScenarioState      string `json:"newScenarioState"`
+
+	// Source identifies where the mapping was read from, such as
+	// mappings/users.json, for reporting.
+	Source string `json:"-"`
+
+	// raw holds the members of the mapping, its request and its response,
+	// so that those which are not translated can be reported.
+	raw rawMapping
+}
+
+type Request struct {
+	Method          string             `json:"method"`
+	URL             string             `json:"url"`
+	URLPath         string             `json:"urlPath"`
+	URLPattern      string             `json:"urlPattern"`
+	URLPathPattern  string             `json:"urlPathPattern"`
+	URLPathTemplate string             `json:"urlPathTemplate"`
+	Headers         map[string]Matcher `json:"headers"`
+	QueryParameters map[string]Matcher `json:"queryParameters"`
+	PathParameters  map[string]Matcher `json:"pathParameters"`
+	BodyPatterns    []Matcher          `json:"bodyPatterns"`
+}
+
+type Response struct {
+	Status       int                        `json:"status"`
+	Headers      map[string]json.RawMessage `json:"headers"`
+	Body         *string                    `json:"body"`
+	JSONBody     json.RawMessage            `json:"jsonBody"`
+	Base64Body   string                     `json:"base64Body"`
+	BodyFileName string                     `json:"bodyFileName"`
+	ProxyBaseURL string                     `json:"proxyBaseUrl"`
+	Fault        string                     `json:"fault"`
+	Transformers []string                   `json:"transformers"`
+}
+
+// Matcher is a WireMock value matcher, such as {"equalTo": "abc"}, keyed by
+// operator, along with any flags, such as {"caseInsensitive": true}.
+type Matcher map[string]json.RawMessage
+
+type rawMapping struct {
+	mapping  map[string]json.RawMessage
+	request  map[string]json.RawMessage
+	response map[string]json.RawMessage
+}
+
+// Load reads the stub mappings in the mappings directory of a WireMock root
+// directory, including its subdirectories. A mapping file can hold a single
+// mapping, or several in a 'mappings' array. Mappings are returned in file
+// order, then sorted by priority, highest first, as WireMock does.
+func Load(rootDir string) ([]Mapping, error) {
+	mappingsDir := filepath.Join(rootDir, "mappings")
+	var files []string
+	err := filepath.WalkDir(mappingsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.EqualFold(filepath.Ext(path), ".json") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list WireMock mappings: %v", err)
+	}
+
+	var mappings []Mapping
+	for _, file := range files {
+		source, _ := filepath.Rel(rootDir, file)
+		fileMappings, err := parseMappingFile(file, filepath.ToSlash(source))
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, fileMappings...)
+	}
+	sort.SliceStable(mappings, func(i, j int) bool {
+		return priority(mappings[i]) < priority(mappings[j])
+	})
+	return mappings, nil
+}
+
+// defaultPriority is the priority of mappings that do not specify one.
+const defaultPriority = 5
+
+func priority(mapping Mapping) int {
+	if mapping.Priority == nil {
+		return defaultPriority
+	}
+	return *mapping.Priority
+}
+
+func parseMappingFile(file string, source string) ([]Mapping, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read WireMock mapping: %s: %v", file, err)
+	}
+	var multiple struct {
+		Mappings []json.RawMessage `json:"mappings"`
+	}
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return nil, fmt.Errorf("failed to parse WireMock mapping: %s: %v", file, err)
+	}
+	if multiple.Mappings == nil {
+		mapping, err := parseMapping(data, source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse WireMock mapping: %s: %v", file, err)
+		}
+		return []Mapping{*mapping}, nil
+	}
+
+	var mappings []Mapping
+	for i, data := range multiple.Mappings {
+		mapping, err := parseMapping(data, fmt.Sprintf("%s[%d]", source, i))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse WireMock mapping %d: %s: %v", i, file, err)
+		}
+		mappings = append(mappings, *mapping)
+	}
+	return mappings, nil
+}
+
+func parseMapping(data []byte, source string) (*Mapping, error) {
+	mapping := &Mapping{Source: source}
+	if err := json.Unmarshal(data, mapping); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &mapping.raw.mapping); err != nil {
+		return nil, err
+	}
+	if request := mapping.raw.mapping["request"]; request != nil {
+		if err := json.Unmarshal(request, &mapping.raw.request); err != nil {
+			return nil, err
+		}
+	}
+	if response := mapping.raw.mapping["response"]; response != nil {
+		if err := json.Unmarshal(response, &mapping.raw.response); err != nil {
+			return nil, err
+		}
+	}
+	return mapping, nil
+}
@@ -0,0 +1,208 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wsdl
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/imposter-project/imposter-cli/internal/logging"
+	"github.com/imposter-project/imposter-cli/internal/openapi"
+	wsdlparser "github.com/outofcoffee/go-wsdl-parser"
+	"github.com/outofcoffee/go-wsdl-parser/wsdlmsg"
+)
+
+var logger = logging.GetLogger()
+
+type SoapVersion string
+
+const (
+	Soap11 SoapVersion = "1.1"
+	Soap12 SoapVersion = "1.2"
+)
+
+const (
+	soap11EnvelopeNamespace = "http://schemas.xmlsoap.org/soap/envelope/"
+	soap12EnvelopeNamespace = "http://www.w3.org/2003/05/soap-envelope"
+	soap12BindingNamespace  = "http://schemas.xmlsoap.org/wsdl/soap12/"
+)
+
+// ContentType returns the media type of messages for the SOAP version.
+func (v SoapVersion) ContentType() string {
+	if v == Soap12 {
+		return "application/soap+xml"
+	}
+	return "text/xml"
+}
+
+func (v SoapVersion) envelopeNamespace() string {
+	if v == Soap12 {
+		return soap12EnvelopeNamespace
+	}
+	return soap11EnvelopeNamespace
+}
+
+// SampleGenerator builds sample SOAP envelopes for the operations of a
+// WSDL, with a body shaped by the XSD types of the operation's messages.
+// Values are generated from the types, using the seed, so the same seed
+// produces the same envelopes.
+type SampleGenerator struct {
+	parser  wsdlparser.WSDLParser
+	builder *sampleBuilder
+}
+
+func NewSampleGenerator(parser wsdlparser.WSDLParser, seed int64) (*SampleGenerator, error) {
+	schemas, err := newSchemaIndex(*parser.GetSchemaSystem())
+	if err != nil {
+		return nil, err
+	}
+	return &SampleGenerator{
+		parser: parser,
+		builder: &sampleBuilder{
+			schemas: schemas,
+			fake:    openapi.NewFakeDataGenerator(seed),
+		},
+	}, nil
+}
+
+// SoapVersion returns the SOAP version of the binding of the operation.
+func (g *SampleGenerator) SoapVersion(op *wsdlparser.Operation) SoapVersion {
+	binding := xmlquery.FindOne(g.parser.GetWSDLDoc(), fmt.Sprintf("//*[local-name()='binding' and @name='%s']", op.Binding))
+	if g.parser.GetVersion() == wsdlparser.WSDL2 {
+		// WSDL 2.0 SOAP bindings default to SOAP 1.2
+		if binding != nil && binding.SelectAttr("version") == "1.1" {
+			return Soap11
+		}
+		return Soap12
+	}
+	if binding != nil {
+		for _, child := range childElements(binding) {
+			if child.Data == "binding" && child.NamespaceURI == soap12BindingNamespace {
+				return Soap12
+			}
+		}
+	}
+	return Soap11
+}
+
+// ResponseEnvelope returns a sample envelope for the output of the operation.
+func (g *SampleGenerator) ResponseEnvelope(op *wsdlparser.Operation) ([]byte, error) {
+	body, err := g.messageSample(op.Output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build output of operation %s: %v", op.Name, err)
+	}
+	return renderEnvelope(g.SoapVersion(op), body), nil
+}
+
+// FaultEnvelope returns a sample envelope containing a fault, with
+// the fault message of the operation as its detail. Returns false if
+// the operation has no fault message.
+func (g *SampleGenerator) FaultEnvelope(op *wsdlparser.Operation) ([]byte, bool, error) {
+	if op.Fault == nil {
+		return nil, false, nil
+	}
+	detail, err := g.messageSample(op.Fault)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to build fault of operation %s: %v", op.Name, err)
+	}
+
+	version := g.SoapVersion(op)
+	var fault *sampleNode
+	if version == Soap12 {
+		fault = &sampleNode{name: "soap:Fault", children: []*sampleNode{
+			{name: "soap:Code", children: []*sampleNode{{name: "soap:Value", text: "soap:Receiver"}}},
+			{name: "soap:Reason", children: []*sampleNode{{name: "soap:Text", attrs: [][2]string{{"xml:lang", "en"}}, text: "Server error"}}},
+		}}
+		if detail != nil {
+			fault.children = append(fault.children, &sampleNode{name: "soap:Detail", children: []*sampleNode{detail}})
+		}
+	} else {
+		fault = &sampleNode{name: "soap:Fault", children: []*sampleNode{
+			{name: "faultcode", text: "soap:Server"},
+			{name: "faultstring", text: "Server error"},
+		}}
+		if detail != nil {
+			fault.children = append(fault.children, &sampleNode{name: "detail", children: []*sampleNode{detail}})
+		}
+	}
+	return renderEnvelope(version, fault), true, nil
+}
+
+// messageSample returns a sample of the element of the message, or nil
+// if there is no message. Messages are resolved to elements by the parser.
+func (g *SampleGenerator) messageSample(message *wsdlmsg.Message) (*sampleNode, error) {
+	if message == nil {
+		return nil, nil
+	}
+	elementMessage, ok := (*message).(*wsdlmsg.ElementMessage)
+	if !ok || elementMessage.Element == nil {
+		return nil, fmt.Errorf("unsupported message type: %T", *message)
+	}
+	return g.builder.element(qname{elementMessage.Element.Space, elementMessage.Element.Local})
+}
+
+func renderEnvelope(version SoapVersion, body *sampleNode) []byte {
+	envelope := &sampleNode{
+		name:  "soap:Envelope",
+		attrs: [][2]string{{"xmlns:soap", version.envelopeNamespace()}},
+		children: []*sampleNode{
+			{name: "soap:Header"},
+			{name: "soap:Body"},
+		},
+	}
+	if body != nil {
+		envelope.children[1].children = []*sampleNode{body}
+	}
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	render(&buf, envelope, 0, "")
+	return buf.Bytes()
+}
+
+// render writes the node as XML, declaring its namespace as the
+// default namespace if it differs from that of its parent.
+func render(buf *bytes.Buffer, node *sampleNode, indent int, defaultNs string) {
+	padding := strings.Repeat("  ", indent)
+	buf.WriteString(padding + "<" + node.name)
+	if node.ns != defaultNs {
+		buf.WriteString(` xmlns="`)
+		_ = xml.EscapeText(buf, []byte(node.ns))
+		buf.WriteString(`"`)
+	}
+	for _, attr := range node.attrs {
+		buf.WriteString(" " + attr[0] + `="`)
+		_ = xml.EscapeText(buf, []byte(attr[1]))
+		buf.WriteString(`"`)
+	}
+	switch {
+	case len(node.children) > 0:
+		buf.WriteString(">\n")
+		for _, child := range node.children {
+			render(buf, child, indent+1, node.ns)
+		}
+		buf.WriteString(padding + "</" + node.name + ">\n")
+	case node.text != "":
+		buf.WriteString(">")
+		_ = xml.EscapeText(buf, []byte(node.text))
+		buf.WriteString("</" + node.name + ">\n")
+	default:
+		buf.WriteString("/>\n")
+	}
+}
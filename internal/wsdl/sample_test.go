@@ -0,0 +1,136 @@
+package wsdl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	wsdlparser "github.com/outofcoffee/go-wsdl-parser"
+	"github.com/stretchr/testify/require"
+)
+
+const petServiceWsdl = `<?xml version="1.0" encoding="UTF-8"?>
+<definitions xmlns="http://schemas.xmlsoap.org/wsdl/"
+             xmlns:soap="http://schemas.xmlsoap.org/wsdl/%s/"
+             xmlns:tns="urn:com:example:petstore"
+             xmlns:xsd="http://www.w3.org/2001/XMLSchema"
+             targetNamespace="urn:com:example:petstore">
+    <types>
+        <xsd:schema targetNamespace="urn:com:example:petstore" elementFormDefault="qualified">
+            <xsd:element name="getPetByIdRequest">
+                <xsd:complexType>
+                    <xsd:sequence>
+                        <xsd:element name="id" type="xsd:int"/>
+                    </xsd:sequence>
+                </xsd:complexType>
+            </xsd:element>
+            <xsd:element name="getPetByIdResponse" type="tns:petType"/>
+            <xsd:complexType name="petType">
+                <xsd:sequence>
+                    <xsd:element name="id" type="xsd:int"/>
+                    <xsd:element name="name" type="xsd:string"/>
+                    <xsd:element name="status" type="tns:statusType"/>
+                    <xsd:element name="born" type="xsd:date" minOccurs="0"/>
+                </xsd:sequence>
+                <xsd:attribute name="version" type="xsd:string" fixed="2"/>
+            </xsd:complexType>
+            <xsd:simpleType name="statusType">
+                <xsd:restriction base="xsd:string">
+                    <xsd:enumeration value="available"/>
+                </xsd:restriction>
+            </xsd:simpleType>
+            <xsd:element name="fault">
+                <xsd:complexType>
+                    <xsd:sequence>
+                        <xsd:element name="code" type="xsd:string"/>
+                    </xsd:sequence>
+                </xsd:complexType>
+            </xsd:element>
+        </xsd:schema>
+    </types>
+    <message name="getPetByIdRequest">
+        <part name="parameters" element="tns:getPetByIdRequest"/>
+    </message>
+    <message name="getPetByIdResponse">
+        <part name="parameters" element="tns:getPetByIdResponse"/>
+    </message>
+    <message name="faultMessage">
+        <part name="parameters" element="tns:fault"/>
+    </message>
+    <portType name="PetPortType">
+        <operation name="getPetById">
+            <input message="tns:getPetByIdRequest"/>
+            <output message="tns:getPetByIdResponse"/>
+            <fault name="fault" message="tns:faultMessage"/>
+        </operation>
+    </portType>
+    <binding name="PetBinding" type="tns:PetPortType">
+        <soap:binding style="document" transport="http://schemas.xmlsoap.org/soap/http"/>
+        <operation name="getPetById">
+            <soap:operation soapAction="getPetById"/>
+            <input><soap:body use="literal"/></input>
+            <output><soap:body use="literal"/></output>
+            <fault name="fault"><soap:fault name="fault" use="literal"/></fault>
+        </operation>
+    </binding>
+    <service name="PetService">
+        <port name="PetPort" binding="tns:PetBinding">
+            <soap:address location="http://www.example.com/pets/"/>
+        </port>
+    </service>
+</definitions>`
+
+func newTestGenerator(t *testing.T, bindingNs string) (*SampleGenerator, *wsdlparser.Operation) {
+	wsdlFile := filepath.Join(t.TempDir(), "service.wsdl")
+	require.NoError(t, os.WriteFile(wsdlFile, []byte(strings.Replace(petServiceWsdl, "%s", bindingNs, 1)), 0644))
+	parser, err := wsdlparser.NewWSDLParser(wsdlFile)
+	require.NoError(t, err)
+	generator, err := NewSampleGenerator(parser, 1)
+	require.NoError(t, err)
+	op := parser.GetOperation("getPetById")
+	require.NotNil(t, op)
+	return generator, op
+}
+
+func TestSampleGenerator_ResponseEnvelope(t *testing.T) {
+	generator, op := newTestGenerator(t, "soap")
+	require.Equal(t, Soap11, generator.SoapVersion(op))
+
+	envelope, err := generator.ResponseEnvelope(op)
+	require.NoError(t, err)
+	body := string(envelope)
+	require.Contains(t, body, `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">`)
+	require.Contains(t, body, `<getPetByIdResponse xmlns="urn:com:example:petstore" version="2">`)
+	require.Regexp(t, `<id>-?\d+</id>`, body)
+	require.Contains(t, body, `<status>available</status>`)
+	require.Regexp(t, `<born>\d{4}-\d{2}-\d{2}</born>`, body)
+
+	other, op := newTestGenerator(t, "soap")
+	again, err := other.ResponseEnvelope(op)
+	require.NoError(t, err)
+	require.Equal(t, body, string(again), "the same seed should produce the same envelope")
+}
+
+func TestSampleGenerator_FaultEnvelope(t *testing.T) {
+	t.Run("soap 1.1", func(t *testing.T) {
+		generator, op := newTestGenerator(t, "soap")
+		envelope, ok, err := generator.FaultEnvelope(op)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Contains(t, string(envelope), `<faultcode>soap:Server</faultcode>`)
+		require.Contains(t, string(envelope), `<fault xmlns="urn:com:example:petstore">`)
+	})
+	t.Run("soap 1.2", func(t *testing.T) {
+		generator, op := newTestGenerator(t, "soap12")
+		require.Equal(t, Soap12, generator.SoapVersion(op))
+		require.Equal(t, "application/soap+xml", generator.SoapVersion(op).ContentType())
+
+		envelope, ok, err := generator.FaultEnvelope(op)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Contains(t, string(envelope), `xmlns:soap="http://www.w3.org/2003/05/soap-envelope"`)
+		require.Contains(t, string(envelope), `<soap:Value>soap:Receiver</soap:Value>`)
+		require.Contains(t, string(envelope), `<soap:Detail>`)
+	})
+}
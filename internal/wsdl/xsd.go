@@ -0,0 +1,434 @@
+/*
+Copyright © 2026 Pete Cornish <outofcoffee@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wsdl
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/imposter-project/imposter-cli/internal/openapi"
+	"github.com/outofcoffee/go-wsdl-parser/xsd"
+)
+
+const xsdNamespace = "http://www.w3.org/2001/XMLSchema"
+
+// maxSampleDepth limits the nesting of sample elements,
+// such as for recursive types.
+const maxSampleDepth = 10
+
+type qname struct {
+	space string
+	local string
+}
+
+// schemaIndex holds the top-level components of the schemas of a WSDL,
+// keyed by qualified name. Components are also keyed by local name, as
+// a fallback for references whose namespace cannot be determined.
+type schemaIndex struct {
+	components map[string]map[qname]*xmlquery.Node
+	byLocal    map[string]map[string]*xmlquery.Node
+}
+
+func newSchemaIndex(schemaSystem xsd.SchemaSystem) (*schemaIndex, error) {
+	index := &schemaIndex{
+		components: make(map[string]map[qname]*xmlquery.Node),
+		byLocal:    make(map[string]map[string]*xmlquery.Node),
+	}
+	// schemas are indexed in order, so fallback lookups are consistent
+	var files []string
+	for _, schema := range schemaSystem.GetSchemas() {
+		if schema.TargetNamespace != xsdNamespace {
+			files = append(files, schema.FilePath)
+		}
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		if err := index.add(file); err != nil {
+			return nil, err
+		}
+	}
+	return index, nil
+}
+
+func (s *schemaIndex) add(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	doc, err := xmlquery.Parse(f)
+	if err != nil {
+		return fmt.Errorf("failed to parse schema: %s: %v", file, err)
+	}
+	root := doc.SelectElement("*")
+	if root == nil {
+		return nil
+	}
+	targetNamespace := root.SelectAttr("targetNamespace")
+	for _, child := range childElements(root) {
+		name := child.SelectAttr("name")
+		if name == "" {
+			continue
+		}
+		kind := child.Data
+		if s.components[kind] == nil {
+			s.components[kind] = make(map[qname]*xmlquery.Node)
+			s.byLocal[kind] = make(map[string]*xmlquery.Node)
+		}
+		s.components[kind][qname{targetNamespace, name}] = child
+		if _, exists := s.byLocal[kind][name]; !exists {
+			s.byLocal[kind][name] = child
+		}
+	}
+	return nil
+}
+
+// lookup returns the top-level component of the kind, such as 'element'
+// or 'complexType', with the name, or nil if there is no such component.
+func (s *schemaIndex) lookup(kind string, name qname) *xmlquery.Node {
+	if node := s.components[kind][name]; node != nil {
+		return node
+	}
+	return s.byLocal[kind][name.local]
+}
+
+// sampleNode is an element of a sample XML document.
+type sampleNode struct {
+	name     string
+	ns       string
+	attrs    [][2]string
+	text     string
+	children []*sampleNode
+}
+
+// sampleBuilder builds sample XML documents for the elements of a schema.
+type sampleBuilder struct {
+	schemas *schemaIndex
+	fake    *openapi.FakeDataGenerator
+}
+
+// element builds a sample of the top-level element with the name.
+func (b *sampleBuilder) element(name qname) (*sampleNode, error) {
+	el := b.schemas.lookup("element", name)
+	if el == nil {
+		return nil, fmt.Errorf("element %s not found in schemas", name.local)
+	}
+	return b.buildElement(el, true, 0), nil
+}
+
+func (b *sampleBuilder) buildElement(el *xmlquery.Node, topLevel bool, depth int) *sampleNode {
+	if ref := el.SelectAttr("ref"); ref != "" {
+		target := b.schemas.lookup("element", resolveQName(el, ref))
+		if target == nil {
+			return nil
+		}
+		return b.buildElement(target, true, depth)
+	}
+	schema := schemaOf(el)
+	node := &sampleNode{name: el.SelectAttr("name")}
+	form := el.SelectAttr("form")
+	if topLevel || form == "qualified" || (form == "" && schema != nil && schema.SelectAttr("elementFormDefault") == "qualified") {
+		if schema != nil {
+			node.ns = schema.SelectAttr("targetNamespace")
+		}
+	}
+	if depth >= maxSampleDepth {
+		return node
+	}
+
+	if typeName := el.SelectAttr("type"); typeName != "" {
+		b.applyType(node, resolveQName(el, typeName), depth)
+	} else if ct := childElement(el, "complexType"); ct != nil {
+		b.buildComplexType(node, ct, depth)
+	} else if st := childElement(el, "simpleType"); st != nil {
+		node.text = b.simpleValue(b.simpleTypeSchema(st, depth))
+	}
+	if fixed := el.SelectAttr("fixed"); fixed != "" {
+		node.text = fixed
+	} else if def := el.SelectAttr("default"); def != "" {
+		node.text = def
+	}
+	return node
+}
+
+// applyType sets the content of the node from the named type.
+func (b *sampleBuilder) applyType(node *sampleNode, typeName qname, depth int) {
+	if typeName.space == xsdNamespace {
+		if typeName.local != "anyType" {
+			node.text = b.simpleValue(builtinSchema(typeName.local))
+		}
+		return
+	}
+	if ct := b.schemas.lookup("complexType", typeName); ct != nil {
+		b.buildComplexType(node, ct, depth)
+	} else if st := b.schemas.lookup("simpleType", typeName); st != nil {
+		node.text = b.simpleValue(b.simpleTypeSchema(st, depth))
+	} else {
+		logger.Debugf("type %s not found in schemas - using string", typeName.local)
+		node.text = b.simpleValue(builtinSchema("string"))
+	}
+}
+
+// buildComplexType adds the attributes and content of a complex type, or
+// of an extension or restriction within one, to the node.
+func (b *sampleBuilder) buildComplexType(node *sampleNode, ct *xmlquery.Node, depth int) {
+	for _, child := range childElements(ct) {
+		switch child.Data {
+		case "sequence", "all", "choice":
+			b.buildParticles(node, child, depth)
+		case "group":
+			if group := b.schemas.lookup("group", resolveQName(child, child.SelectAttr("ref"))); group != nil {
+				b.buildComplexType(node, group, depth)
+			}
+		case "attribute":
+			b.buildAttribute(node, child, depth)
+		case "attributeGroup":
+			if group := b.schemas.lookup("attributeGroup", resolveQName(child, child.SelectAttr("ref"))); group != nil {
+				b.buildComplexType(node, group, depth)
+			}
+		case "complexContent", "simpleContent":
+			for _, derivation := range childElements(child) {
+				if derivation.Data != "extension" && derivation.Data != "restriction" {
+					continue
+				}
+				// a restriction of complex content replaces the content of its base
+				if base := derivation.SelectAttr("base"); base != "" && (derivation.Data == "extension" || child.Data == "simpleContent") {
+					b.applyType(node, resolveQName(derivation, base), depth)
+				}
+				b.buildComplexType(node, derivation, depth)
+			}
+		}
+	}
+}
+
+// buildParticles adds the elements of a model group to the node. Only
+// the first particle of a choice is used.
+func (b *sampleBuilder) buildParticles(node *sampleNode, group *xmlquery.Node, depth int) {
+	for _, child := range childElements(group) {
+		switch child.Data {
+		case "element":
+			if el := b.buildElement(child, false, depth+1); el != nil {
+				node.children = append(node.children, el)
+			}
+		case "sequence", "all", "choice":
+			b.buildParticles(node, child, depth)
+		case "group":
+			if ref := b.schemas.lookup("group", resolveQName(child, child.SelectAttr("ref"))); ref != nil {
+				b.buildComplexType(node, ref, depth)
+			}
+		default:
+			continue
+		}
+		if group.Data == "choice" {
+			return
+		}
+	}
+}
+
+func (b *sampleBuilder) buildAttribute(node *sampleNode, attr *xmlquery.Node, depth int) {
+	if attr.SelectAttr("use") == "prohibited" {
+		return
+	}
+	if ref := attr.SelectAttr("ref"); ref != "" {
+		if attr = b.schemas.lookup("attribute", resolveQName(attr, ref)); attr == nil {
+			return
+		}
+	}
+	var value string
+	switch {
+	case attr.SelectAttr("fixed") != "":
+		value = attr.SelectAttr("fixed")
+	case attr.SelectAttr("default") != "":
+		value = attr.SelectAttr("default")
+	case attr.SelectAttr("type") != "":
+		value = b.simpleValue(b.namedSimpleSchema(resolveQName(attr, attr.SelectAttr("type")), depth))
+	case childElement(attr, "simpleType") != nil:
+		value = b.simpleValue(b.simpleTypeSchema(childElement(attr, "simpleType"), depth))
+	default:
+		value = b.simpleValue(builtinSchema("string"))
+	}
+	node.attrs = append(node.attrs, [2]string{attr.SelectAttr("name"), value})
+}
+
+// namedSimpleSchema returns the schema for a built-in or simple type.
+func (b *sampleBuilder) namedSimpleSchema(typeName qname, depth int) *openapi.Schema {
+	if typeName.space != xsdNamespace && depth < maxSampleDepth {
+		if st := b.schemas.lookup("simpleType", typeName); st != nil {
+			return b.simpleTypeSchema(st, depth+1)
+		}
+	}
+	return builtinSchema(typeName.local)
+}
+
+// simpleTypeSchema returns a schema describing the values of a simple
+// type, from its base type and facets, such as enumerations and bounds.
+func (b *sampleBuilder) simpleTypeSchema(st *xmlquery.Node, depth int) *openapi.Schema {
+	if restriction := childElement(st, "restriction"); restriction != nil {
+		var schema openapi.Schema
+		if base := restriction.SelectAttr("base"); base != "" {
+			schema = *b.namedSimpleSchema(resolveQName(restriction, base), depth)
+		} else if inline := childElement(restriction, "simpleType"); inline != nil {
+			schema = *b.simpleTypeSchema(inline, depth)
+		}
+		applyFacets(&schema, restriction)
+		return &schema
+	}
+	if list := childElement(st, "list"); list != nil {
+		if itemType := list.SelectAttr("itemType"); itemType != "" {
+			return b.namedSimpleSchema(resolveQName(list, itemType), depth)
+		}
+	}
+	if union := childElement(st, "union"); union != nil {
+		if memberTypes := strings.Fields(union.SelectAttr("memberTypes")); len(memberTypes) > 0 {
+			return b.namedSimpleSchema(resolveQName(union, memberTypes[0]), depth)
+		}
+		if inline := childElement(union, "simpleType"); inline != nil {
+			return b.simpleTypeSchema(inline, depth)
+		}
+	}
+	return builtinSchema("string")
+}
+
+func applyFacets(schema *openapi.Schema, restriction *xmlquery.Node) {
+	for _, facet := range childElements(restriction) {
+		value := facet.SelectAttr("value")
+		switch facet.Data {
+		case "enumeration":
+			schema.Enum = append(schema.Enum, value)
+		case "minInclusive", "maxInclusive":
+			if number, err := strconv.ParseFloat(value, 64); err == nil {
+				if facet.Data == "minInclusive" {
+					schema.Minimum = &number
+				} else {
+					schema.Maximum = &number
+				}
+			}
+		case "minExclusive":
+			schema.ExclusiveMinimum = json.RawMessage(value)
+		case "maxExclusive":
+			schema.ExclusiveMaximum = json.RawMessage(value)
+		case "length", "minLength", "maxLength":
+			if length, err := strconv.Atoi(value); err == nil {
+				if facet.Data != "maxLength" {
+					schema.MinLength = &length
+				}
+				if facet.Data != "minLength" {
+					schema.MaxLength = &length
+				}
+			}
+		}
+	}
+}
+
+// builtinSchema returns a schema describing the values
+// of an XSD built-in type, such as 'int' or 'dateTime'.
+func builtinSchema(typeName string) *openapi.Schema {
+	bounded := func(schemaType string, minimum float64, maximum float64) *openapi.Schema {
+		return &openapi.Schema{Type: openapi.SchemaType{schemaType}, Minimum: &minimum, Maximum: &maximum}
+	}
+	switch typeName {
+	case "boolean":
+		return &openapi.Schema{Type: openapi.SchemaType{"boolean"}}
+	case "byte":
+		return bounded("integer", 0, 127)
+	case "unsignedByte":
+		return bounded("integer", 0, 255)
+	case "positiveInteger":
+		return bounded("integer", 1, 1000)
+	case "negativeInteger", "nonPositiveInteger":
+		return bounded("integer", -1000, -1)
+	case "int", "integer", "long", "short", "nonNegativeInteger", "unsignedInt", "unsignedLong", "unsignedShort":
+		return bounded("integer", 0, 1000)
+	case "decimal", "float", "double":
+		return &openapi.Schema{Type: openapi.SchemaType{"number"}}
+	case "date":
+		return &openapi.Schema{Type: openapi.SchemaType{"string"}, Format: "date"}
+	case "dateTime":
+		return &openapi.Schema{Type: openapi.SchemaType{"string"}, Format: "date-time"}
+	case "time":
+		return &openapi.Schema{Type: openapi.SchemaType{"string"}, Format: "time"}
+	case "anyURI":
+		return &openapi.Schema{Type: openapi.SchemaType{"string"}, Format: "uri"}
+	case "base64Binary":
+		return &openapi.Schema{Type: openapi.SchemaType{"string"}, Format: "byte"}
+	case "duration":
+		return &openapi.Schema{Const: "P1D"}
+	case "language":
+		return &openapi.Schema{Const: "en"}
+	default:
+		return &openapi.Schema{Type: openapi.SchemaType{"string"}}
+	}
+}
+
+func (b *sampleBuilder) simpleValue(schema *openapi.Schema) string {
+	return fmt.Sprint(b.fake.Generate(schema))
+}
+
+// resolveQName resolves a qualified name, such as 'tns:Pet', using
+// the namespace declarations in scope for the node.
+func resolveQName(node *xmlquery.Node, value string) qname {
+	prefix, local, found := strings.Cut(value, ":")
+	if !found {
+		prefix, local = "", value
+	}
+	for n := node; n != nil; n = n.Parent {
+		for _, attr := range n.Attr {
+			if (prefix == "" && attr.Name.Space == "" && attr.Name.Local == "xmlns") ||
+				(prefix != "" && attr.Name.Space == "xmlns" && attr.Name.Local == prefix) {
+				return qname{attr.Value, local}
+			}
+		}
+	}
+	if prefix == "xs" || prefix == "xsd" {
+		return qname{xsdNamespace, local}
+	}
+	return qname{"", local}
+}
+
+// schemaOf returns the schema element containing the node.
+func schemaOf(node *xmlquery.Node) *xmlquery.Node {
+	for n := node; n != nil; n = n.Parent {
+		if n.Type == xmlquery.ElementNode && n.Data == "schema" {
+			return n
+		}
+	}
+	return nil
+}
+
+func childElements(node *xmlquery.Node) []*xmlquery.Node {
+	var children []*xmlquery.Node
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == xmlquery.ElementNode {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+func childElement(node *xmlquery.Node, name string) *xmlquery.Node {
+	for _, child := range childElements(node) {
+		if child.Data == name {
+			return child
+		}
+	}
+	return nil
+}